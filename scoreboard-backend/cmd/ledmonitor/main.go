@@ -0,0 +1,66 @@
+// Command ledmonitor shows what an LED board would: it reads frames from a
+// serial port, or from the /dev/pts path a simulated port ("led.port":
+// "pty") logs at startup, and prints each frame that differs from the last.
+// Checksum errors and line noise are reported and skipped.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"scoreboard-backend/internal/led"
+	"strings"
+)
+
+func main() {
+	port := flag.String("port", "", "serial device or /dev/pts path to read")
+	protocol := flag.String("protocol", led.DefaultProtocol, "frame protocol ("+strings.Join(led.ProtocolNames(), ", ")+")")
+	defaults := led.DefaultSerialConfig()
+	baud := flag.Int("baud", defaults.Baud, "baud rate")
+	parity := flag.String("parity", defaults.Parity, "none, even or odd")
+	stopBits := flag.Int("stop-bits", defaults.StopBits, "1 or 2")
+	all := flag.Bool("all", false, "print every frame, not just changes")
+	flag.Parse()
+	if *port == "" {
+		log.Fatal("-port is required")
+	}
+	p, err := led.LookupProtocol(*protocol)
+	if err != nil {
+		log.Fatal(err)
+	}
+	f, err := led.OpenSerial(*port, led.SerialConfig{Baud: *baud, Parity: *parity, StopBits: *stopBits})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var last led.Frame
+	first := true
+	for {
+		frame, address, err := p.Decode(r)
+		if errors.Is(err, io.EOF) {
+			return
+		}
+		if err != nil {
+			fmt.Println("bad frame:", err)
+			continue
+		}
+		if !*all && !first && frame == last {
+			continue
+		}
+		first, last = false, frame
+		running := "stopped"
+		if frame.ShotClockRunning {
+			running = "running"
+		}
+		if *protocol == "segments" {
+			running = "" // Digit boards have no running light
+		}
+		fmt.Printf("[%02d] %6s  shot %4s %-7s  %3d - %-3d  fouls %d - %d\n",
+			address, frame.GameClock, frame.ShotClock, running, frame.ScoreA, frame.ScoreB, frame.FoulA, frame.FoulB)
+	}
+}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+const (
+	timeoutLength  = 30 * time.Second // 3x3 timeouts last 30s
+	freeThrowTime  = 8 * time.Second  // game time spent on each free throw
+	freeThrowMakes = 0.7
+)
+
+var teams = [2]string{"A", "B"}
+
+// court plays games on one backend
+type court struct {
+	id     int
+	url    string
+	opts   options
+	client *http.Client
+	rand   *rand.Rand
+	stats  courtStats
+}
+
+type courtStats struct {
+	games, possessions, baskets, freeThrows int
+	fouls, timeouts, violations, turnovers  int
+	requests, errors                        int
+}
+
+// gameState is the part of GET /api/state the simulator follows
+type gameState struct {
+	TimerTenths     int  `json:"timerTenths"`
+	ShotClockTenths int  `json:"shotClockTenths"`
+	ScoreA          int  `json:"scoreA"`
+	ScoreB          int  `json:"scoreB"`
+	Running         bool `json:"isShotClockRunning"`
+}
+
+func newCourt(id int, url string, opts options) *court {
+	return &court{
+		id:     id,
+		url:    url,
+		opts:   opts,
+		client: &http.Client{Timeout: 10 * time.Second},
+		rand:   rand.New(rand.NewSource(opts.seed + int64(id))),
+	}
+}
+
+func (c *court) summary() string {
+	s := c.stats
+	return fmt.Sprintf("court %d (%s): %d games, %d possessions, %d baskets, %d free throws made, %d fouls, %d timeouts, %d shot clock violations, %d turnovers, %d requests, %d errors",
+		c.id, c.url, s.games, s.possessions, s.baskets, s.freeThrows, s.fouls, s.timeouts, s.violations, s.turnovers, s.requests, s.errors)
+}
+
+// play runs games back to back until opts.games are done or ctx ends
+func (c *court) play(ctx context.Context) {
+	for n := 1; c.opts.games == 0 || n <= c.opts.games; n++ {
+		if err := c.playGame(ctx, n); err != nil {
+			if ctx.Err() == nil {
+				slog.Error("Game abandoned", "court", c.id, "game", n, "error", err)
+			}
+			c.call(http.MethodPost, "/api/shotclock/stop", nil, nil)
+			return
+		}
+		if c.opts.games != 0 && n == c.opts.games {
+			return
+		}
+		if !c.wait(ctx, c.opts.breakFor) {
+			return
+		}
+	}
+}
+
+// playGame resets the court and plays possessions until the clock runs out
+// or a team reaches the score cap
+func (c *court) playGame(ctx context.Context, n int) error {
+	if err := c.resetGame(fmt.Sprintf("simulated game %d", n)); err != nil {
+		return err
+	}
+	slog.Info("Game started", "court", c.id, "game", n)
+	if err := c.call(http.MethodPost, "/api/shotclock/start", nil, nil); err != nil {
+		return err
+	}
+	timeouts := [2]int{1, 1}
+	offense := c.rand.Intn(2)
+	for {
+		state, err := c.state()
+		if err != nil {
+			return err
+		}
+		if state.TimerTenths == 0 || c.capReached(state) {
+			break
+		}
+		if offense, err = c.possession(ctx, offense, &timeouts); err != nil {
+			return err
+		}
+	}
+	if err := c.call(http.MethodPost, "/api/shotclock/stop", nil, nil); err != nil {
+		return err
+	}
+	state, err := c.state()
+	if err != nil {
+		return err
+	}
+	c.stats.games++
+	slog.Info("Game over", "court", c.id, "game", n, "score", fmt.Sprintf("%d-%d", state.ScoreA, state.ScoreB))
+	return nil
+}
+
+func (c *court) capReached(state gameState) bool {
+	limit := c.opts.scoreCap
+	return limit > 0 && (state.ScoreA >= limit || state.ScoreB >= limit)
+}
+
+// possession plays one trip down the floor and returns the team with the
+// ball next
+func (c *court) possession(ctx context.Context, offense int, timeouts *[2]int) (int, error) {
+	c.stats.possessions++
+	defense := 1 - offense
+	if timeouts[offense] > 0 && c.rand.Float64() < 0.03 {
+		timeouts[offense]--
+		c.stats.timeouts++
+		slog.Debug("Timeout", "court", c.id, "team", teams[offense])
+		if err := c.stoppage(ctx, timeoutLength); err != nil {
+			return offense, err
+		}
+	}
+
+	if c.rand.Float64() < 0.04 {
+		// Shot clock violation: run the clock out, then reset it, which
+		// restarts play
+		c.stats.violations++
+		state, err := c.state()
+		if err != nil {
+			return offense, err
+		}
+		if err := c.elapse(ctx, tenths(state.ShotClockTenths)); err != nil {
+			return offense, err
+		}
+		if !pause(ctx, 300*time.Millisecond) { // for the expiry tick
+			return offense, ctx.Err()
+		}
+		return defense, c.call(http.MethodPost, "/api/shotclock/reset", nil, nil)
+	}
+
+	length := time.Duration(2000+c.rand.Intn(9500)) * time.Millisecond
+	if err := c.elapse(ctx, length); err != nil {
+		return offense, err
+	}
+
+	switch r := c.rand.Float64(); {
+	case r < 0.30:
+		return defense, c.basket(offense, 1)
+	case r < 0.42:
+		return defense, c.basket(offense, 2)
+	case r < 0.72:
+		// Missed shot; the offense keeps the ball on an offensive rebound
+		if err := c.call(http.MethodPost, "/api/shotclock/reset", nil, nil); err != nil {
+			return offense, err
+		}
+		if c.rand.Float64() < 0.35 {
+			return offense, nil
+		}
+		return defense, nil
+	case r < 0.87:
+		return c.foul(ctx, offense)
+	default:
+		c.stats.turnovers++
+		return defense, c.call(http.MethodPost, "/api/shotclock/reset", nil, nil)
+	}
+}
+
+// basket adds points for team and resets the shot clock
+func (c *court) basket(team, points int) error {
+	c.stats.baskets++
+	for i := 0; i < points; i++ {
+		if err := c.call(http.MethodPost, "/api/score"+teams[team]+"/increment", nil, nil); err != nil {
+			return err
+		}
+	}
+	slog.Debug("Basket", "court", c.id, "team", teams[team], "points", points)
+	return c.call(http.MethodPost, "/api/shotclock/reset", nil, nil)
+}
+
+// foul charges the defense with a foul. A shooting foul gives one or two
+// free throws, after which the other team has the ball.
+func (c *court) foul(ctx context.Context, offense int) (int, error) {
+	defense := 1 - offense
+	c.stats.fouls++
+	if err := c.call(http.MethodPost, "/api/foul"+teams[defense]+"/increment", nil, nil); err != nil {
+		return offense, err
+	}
+	if err := c.call(http.MethodPost, "/api/shotclock/stop", nil, nil); err != nil {
+		return offense, err
+	}
+	next := offense
+	if c.rand.Float64() < 0.5 {
+		shots := 1
+		if c.rand.Float64() < 0.3 {
+			shots = 2
+		}
+		for i := 0; i < shots; i++ {
+			if !c.wait(ctx, freeThrowTime) {
+				return offense, ctx.Err()
+			}
+			if c.rand.Float64() < freeThrowMakes {
+				c.stats.freeThrows++
+				if err := c.call(http.MethodPost, "/api/score"+teams[offense]+"/increment", nil, nil); err != nil {
+					return offense, err
+				}
+			}
+		}
+		next = defense
+	}
+	if err := c.call(http.MethodPost, "/api/shotclock/reset", nil, nil); err != nil {
+		return offense, err
+	}
+	return next, c.call(http.MethodPost, "/api/shotclock/start", nil, nil)
+}
+
+// stoppage stops the clocks for d of game time and starts them again
+func (c *court) stoppage(ctx context.Context, d time.Duration) error {
+	if err := c.call(http.MethodPost, "/api/shotclock/stop", nil, nil); err != nil {
+		return err
+	}
+	if !c.wait(ctx, d) {
+		return ctx.Err()
+	}
+	return c.call(http.MethodPost, "/api/shotclock/start", nil, nil)
+}
+
+// elapse lets d of game time pass with the clocks running. Above speed 1 the
+// backend's clocks only run for the real time waited, so the rest is taken
+// off both clocks with a clock adjustment.
+func (c *court) elapse(ctx context.Context, d time.Duration) error {
+	real := time.Duration(float64(d) / c.opts.speed)
+	if !c.wait(ctx, d) {
+		return ctx.Err()
+	}
+	skipped := int((d - real) / (100 * time.Millisecond))
+	if skipped <= 0 {
+		return nil
+	}
+	return c.call(http.MethodPost, "/api/clock/adjust", map[string]interface{}{
+		"gameClockDeltaTenths": -skipped,
+		"shotClockDeltaTenths": -skipped,
+		"reason":               "simulator speed-up",
+	}, nil)
+}
+
+// wait sleeps for d of game time, reporting false when ctx ended first
+func (c *court) wait(ctx context.Context, d time.Duration) bool {
+	return pause(ctx, time.Duration(float64(d)/c.opts.speed))
+}
+
+// pause sleeps for d of real time, reporting false when ctx ended first
+func pause(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// resetGame resets the court with the two-step confirmation
+func (c *court) resetGame(reason string) error {
+	var confirmation struct {
+		Token string `json:"token"`
+	}
+	if err := c.call(http.MethodPost, "/api/game/reset", map[string]string{"reason": reason}, &confirmation); err != nil {
+		return err
+	}
+	return c.call(http.MethodPost, "/api/game/reset", map[string]string{"token": confirmation.Token, "reason": reason}, nil)
+}
+
+func (c *court) state() (gameState, error) {
+	var state gameState
+	err := c.call(http.MethodGet, "/api/state", nil, &state)
+	return state, err
+}
+
+// call sends a request as the simulator's operator and decodes the response
+// into out when it is not nil. A 200 carrying an error, as a refused shot
+// clock start does, is counted but not returned.
+func (c *court) call(method, path string, body, out interface{}) error {
+	c.stats.requests++
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.url+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Operator-ID", fmt.Sprintf("simulator-court-%d", c.id))
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.stats.errors++
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.stats.errors++
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		c.stats.errors++
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, bytes.TrimSpace(data))
+	}
+	var refused struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(data, &refused) == nil && refused.Error != "" {
+		c.stats.errors++
+		slog.Debug("Request refused", "court", c.id, "path", path, "error", refused.Error)
+	}
+	if out != nil {
+		return json.Unmarshal(data, out)
+	}
+	return nil
+}
+
+func tenths(n int) time.Duration {
+	return time.Duration(n) * 100 * time.Millisecond
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// probeTimeout bounds the wait for every viewer to receive one state sync
+const probeTimeout = 5 * time.Second
+
+// probe is one timed state sync on one court
+type probe struct {
+	id      int64
+	sentAt  time.Time
+	mutex   sync.Mutex
+	latency []time.Duration
+	done    chan struct{} // closed once every viewer has it
+	want    int
+}
+
+func (p *probe) receive(at time.Time) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.latency = append(p.latency, at.Sub(p.sentAt))
+	if len(p.latency) == p.want {
+		close(p.done)
+	}
+}
+
+// loadCourt is one backend with its viewers
+type loadCourt struct {
+	url     string
+	viewers int32                 // connected
+	current atomic.Pointer[probe] // in flight, or nil
+}
+
+// runLoad connects opts.viewers WebSocket viewers spread over the courts,
+// then times opts.probes state syncs per court from the POST until each
+// viewer receives it, and prints latency percentiles
+func runLoad(ctx context.Context, opts options) error {
+	courts := make([]*loadCourt, len(opts.courts))
+	for i, url := range opts.courts {
+		courts[i] = &loadCourt{url: url}
+	}
+
+	slog.Info("Connecting viewers", "viewers", opts.viewers, "courts", len(courts))
+	var dialFailures int32
+	var firstDialError atomic.Value
+	var wg sync.WaitGroup
+	conns := make(chan *websocket.Conn, opts.viewers)
+	jobs := make(chan int)
+	for w := 0; w < opts.dialWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				c := courts[i%len(courts)]
+				conn, err := dialViewer(ctx, c.url)
+				if err != nil {
+					atomic.AddInt32(&dialFailures, 1)
+					firstDialError.CompareAndSwap(nil, err.Error())
+					continue
+				}
+				atomic.AddInt32(&c.viewers, 1)
+				conns <- conn
+				go watch(conn, c)
+			}
+		}()
+	}
+	started := time.Now()
+	for i := 0; i < opts.viewers && ctx.Err() == nil; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	close(conns)
+	defer func() {
+		for conn := range conns {
+			conn.Close()
+		}
+	}()
+	connected := opts.viewers - int(dialFailures)
+	slog.Info("Viewers connected", "connected", connected, "failed", dialFailures, "took", time.Since(started).Round(time.Millisecond))
+	if dialFailures > 0 {
+		slog.Warn("Some viewers could not connect; raise the open file limit (ulimit -n) for large runs", "firstError", firstDialError.Load())
+	}
+	if connected == 0 {
+		return fmt.Errorf("no viewer connected")
+	}
+	// Let the initial state syncs arrive before timing anything
+	pause(ctx, time.Second)
+
+	var all []time.Duration
+	var sent, missed int
+	for i := 1; i <= opts.probes && ctx.Err() == nil; i++ {
+		for _, c := range courts {
+			want := int(atomic.LoadInt32(&c.viewers))
+			if want == 0 {
+				continue
+			}
+			p, err := c.probe(ctx, int64(i), want)
+			if err != nil {
+				return err
+			}
+			sent++
+			missed += want - len(p.latency)
+			all = append(all, p.latency...)
+		}
+		pause(ctx, opts.interval)
+	}
+	fmt.Println(latencyReport(all, sent, missed))
+	return nil
+}
+
+// probe triggers a state sync and waits until every viewer has it
+func (c *loadCourt) probe(ctx context.Context, id int64, want int) (*probe, error) {
+	p := &probe{id: id, want: want, done: make(chan struct{})}
+	c.current.Store(p)
+	defer c.current.Store(nil)
+	p.mutex.Lock()
+	p.sentAt = time.Now()
+	p.mutex.Unlock()
+	resp, err := http.Post(c.url+"/api/state/sync", "application/json", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("POST %s/api/state/sync: %s", c.url, resp.Status)
+	}
+	select {
+	case <-p.done:
+	case <-time.After(probeTimeout):
+	case <-ctx.Done():
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p, nil
+}
+
+func dialViewer(ctx context.Context, url string) (*websocket.Conn, error) {
+	wsURL := "ws" + strings.TrimPrefix(url, "http") + "/ws/state"
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, wsURL, nil)
+	return conn, err
+}
+
+// watch reads a viewer's messages, reporting each state sync to the probe
+// in flight on its court. A viewer counts each probe once.
+func watch(conn *websocket.Conn, c *loadCourt) {
+	defer atomic.AddInt32(&c.viewers, -1)
+	var last int64
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		at := time.Now()
+		var envelope struct {
+			Type string `json:"type"`
+		}
+		if json.Unmarshal(data, &envelope) != nil || envelope.Type != "state_sync" {
+			continue
+		}
+		if p := c.current.Load(); p != nil && p.id != last {
+			last = p.id
+			p.receive(at)
+		}
+	}
+}
+
+// latencyReport summarises every delivery of every probe
+func latencyReport(latency []time.Duration, probes, missed int) string {
+	if len(latency) == 0 {
+		return fmt.Sprintf("%d probes, no deliveries, %d missed", probes, missed)
+	}
+	sort.Slice(latency, func(i, j int) bool { return latency[i] < latency[j] })
+	at := func(q float64) time.Duration {
+		return latency[int(q*float64(len(latency)-1))].Round(10 * time.Microsecond)
+	}
+	return fmt.Sprintf("%d probes, %d deliveries, %d missed: p50 %s, p90 %s, p99 %s, max %s",
+		probes, len(latency), missed, at(0.5), at(0.9), at(0.99), latency[len(latency)-1].Round(10*time.Microsecond))
+}
@@ -0,0 +1,102 @@
+// Command simulate rehearses tournament days against running backends. In
+// game mode it plays 3x3 games through the REST API on every court: scoring,
+// fouls and free throws, shot clock resets and violations, and timeouts. In
+// load mode it connects thousands of WebSocket viewers and reports how long
+// a state_sync takes to reach them.
+//
+//	simulate -courts http://court1:8080,http://court2:8080 -speed 10
+//	simulate -mode load -courts http://localhost:8080 -viewers 5000
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+type options struct {
+	mode     string
+	courts   []string
+	speed    float64
+	games    int
+	scoreCap int
+	seed     int64
+	breakFor time.Duration
+
+	viewers     int
+	probes      int
+	interval    time.Duration
+	dialWorkers int
+}
+
+func main() {
+	var opts options
+	var courts string
+	flag.StringVar(&opts.mode, "mode", "game", "game: play games on every court; load: measure broadcast latency to many viewers")
+	flag.StringVar(&courts, "courts", "http://localhost:8080", "comma-separated base URLs, one backend per court")
+	flag.Float64Var(&opts.speed, "speed", 1, "game time per real second, e.g. 10 plays a 10 minute game in one minute")
+	flag.IntVar(&opts.games, "games", 1, "games to play per court, 0 to play until interrupted")
+	flag.IntVar(&opts.scoreCap, "score-cap", 21, "score that ends a game early, 0 for none")
+	flag.Int64Var(&opts.seed, "seed", 0, "random seed, 0 for a different game every run")
+	flag.DurationVar(&opts.breakFor, "break", 2*time.Minute, "game time between games on a court")
+	flag.IntVar(&opts.viewers, "viewers", 1000, "load mode: WebSocket viewers, spread over the courts")
+	flag.IntVar(&opts.probes, "probes", 50, "load mode: state syncs to time per court")
+	flag.DurationVar(&opts.interval, "interval", 200*time.Millisecond, "load mode: pause between probes")
+	flag.IntVar(&opts.dialWorkers, "dial-workers", 50, "load mode: viewers connecting at once")
+	flag.Parse()
+
+	for _, url := range strings.Split(courts, ",") {
+		if url = strings.TrimRight(strings.TrimSpace(url), "/"); url != "" {
+			opts.courts = append(opts.courts, url)
+		}
+	}
+	if len(opts.courts) == 0 || opts.speed <= 0 {
+		fmt.Fprintln(os.Stderr, "need at least one court and a positive speed")
+		os.Exit(2)
+	}
+	if opts.seed == 0 {
+		opts.seed = time.Now().UnixNano()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch opts.mode {
+	case "game":
+		playGames(ctx, opts)
+	case "load":
+		if err := runLoad(ctx, opts); err != nil {
+			slog.Error("Load run failed", "error", err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown mode %q\n", opts.mode)
+		os.Exit(2)
+	}
+}
+
+// playGames runs one game loop per court and prints a summary per court
+func playGames(ctx context.Context, opts options) {
+	slog.Info("Playing games", "courts", len(opts.courts), "speed", opts.speed, "games", opts.games, "seed", opts.seed)
+	var wg sync.WaitGroup
+	courts := make([]*court, len(opts.courts))
+	for i, url := range opts.courts {
+		courts[i] = newCourt(i+1, url, opts)
+		wg.Add(1)
+		go func(c *court) {
+			defer wg.Done()
+			c.play(ctx)
+		}(courts[i])
+	}
+	wg.Wait()
+	for _, c := range courts {
+		fmt.Println(c.summary())
+	}
+}
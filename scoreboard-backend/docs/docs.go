@@ -15,6 +15,95 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/api/audit": {
+            "get": {
+                "description": "Returns audit entries oldest first, each with actor, time, reason and the full state before and after. Filter with action, after (sequence number) and limit (newest N).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "log"
+                ],
+                "summary": "Get audit log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only this action, e.g. game_reset",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only entries with a higher sequence number",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "At most this many of the newest entries",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/clock/adjust": {
+            "post": {
+                "description": "Adds signed deltas in tenths to either clock, or sets them to mm:ss.t / ss.t values. Results are clamped to the rules profile and recorded as one correction. Running clocks keep running.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "timer"
+                ],
+                "summary": "Adjust the clocks",
+                "parameters": [
+                    {
+                        "description": "Clock adjustment",
+                        "name": "adjustment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.AdjustClocksRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.ClockAdjustResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
         "/api/foulA/decrement": {
             "post": {
                 "produces": [
@@ -51,6 +140,13 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
                 }
             }
@@ -63,7 +159,563 @@ const docTemplate = `{
                 "tags": [
                     "foul"
                 ],
-                "summary": "Decrement Team B foul",
+                "summary": "Decrement Team B foul",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/foulB/increment": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "foul"
+                ],
+                "summary": "Increment Team B foul",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/fouls": {
+            "put": {
+                "description": "Sets one or both foul counts, validated against the rules profile. Broadcasts a single state_sync and logs a single correction.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fouls"
+                ],
+                "summary": "Correct fouls",
+                "parameters": [
+                    {
+                        "description": "Corrected foul counts",
+                        "name": "fouls",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SetFoulsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ScoreboardState"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/game/archives": {
+            "get": {
+                "description": "Games saved just before a reset, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "game"
+                ],
+                "summary": "List archived games",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/game/archives/{id}/restore": {
+            "post": {
+                "description": "Replaces the current state and game log with an archived game. Clocks come back stopped. Accepts ?reason= for the audit log.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "game"
+                ],
+                "summary": "Restore an archived game",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Archive ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/game/reset": {
+            "post": {
+                "description": "Without a token, returns 202 with a confirmation token and a summary of what will be lost. With the token, resets timer, shot clock, scores, fouls and the game log, archiving the game first when enabled.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "game"
+                ],
+                "summary": "Reset the game",
+                "parameters": [
+                    {
+                        "description": "Confirmation token and reason",
+                        "name": "reset",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ResetGameRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/services.ResetConfirmation"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/games": {
+            "get": {
+                "description": "IDs of every game with an event log, newest first, and the ID of the current game",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "games"
+                ],
+                "summary": "List games",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/games/{id}/state": {
+            "get": {
+                "description": "Rebuilds a game's state from its events. Without at, returns the final state; with at (game clock in tenths), the state when the game clock showed that time. Use \"current\" for the game in progress.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "games"
+                ],
+                "summary": "Replay a game",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Game ID or current",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Game clock in tenths",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/log": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "log"
+                ],
+                "summary": "Get change log",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/overlay/data": {
+            "get": {
+                "description": "One flat JSON object of pre-formatted strings for vMix and CasparCG data sources. teamA and teamB set the team names. During a playback the played back game is shown and playback is \"PLAYBACK\".",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "overlay"
+                ],
+                "summary": "Overlay data feed",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Team A name",
+                        "name": "teamA",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Team B name",
+                        "name": "teamB",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/overlay.Data"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/playback": {
+            "get": {
+                "description": "Returns {\"status\": \"playing\", \"playback\": {...}} during a playback, otherwise {\"status\": \"idle\"}",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "playback"
+                ],
+                "summary": "Get the playback in progress",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/playback/start": {
+            "post": {
+                "description": "Broadcasts a game's events to every display as if it were live. With gameId (or \"current\") the game's event log is played; without it the request body is the events, as a JSON array or NDJSON like a file from the games directory. speed is 1, 2 or 10; step=true instead plays one event per /api/playback/step. The clocks must be stopped. When the playback ends a state_sync puts the live game back.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "playback"
+                ],
+                "summary": "Start a playback",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Game ID or current",
+                        "name": "gameId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "1, 2 or 10",
+                        "name": "speed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Step through the events one at a time",
+                        "name": "step",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.Playback"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/playback/step": {
+            "post": {
+                "description": "Broadcasts the next event. After the last one the playback ends and the live game is put back.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "playback"
+                ],
+                "summary": "Step a playback",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.Playback"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/playback/stop": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "playback"
+                ],
+                "summary": "Stop a playback",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.Playback"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/review": {
+            "get": {
+                "description": "Returns {\"status\": \"review\", \"review\": {...}} during a review, otherwise {\"status\": \"live\"}",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review"
+                ],
+                "summary": "Get the review in progress",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/review/end": {
+            "post": {
+                "description": "Records the outcome and broadcasts review_update with status \"live\". With resumeClocks the clocks restart; otherwise they stay stopped.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review"
+                ],
+                "summary": "End a review",
+                "parameters": [
+                    {
+                        "description": "Review outcome",
+                        "name": "review",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.EndReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/review/events": {
+            "get": {
+                "description": "Replays the current game's event log and returns each event, except clock ticks, with the clocks, scores and fouls it left the game in. The event number is what /api/review/rewind takes.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review"
+                ],
+                "summary": "List rewind points",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/review/rewind": {
+            "post": {
+                "description": "During a review, sets the clocks, scores and fouls to the state just after the given event from /api/review/events, or to the moment the game clock showed gameClock. Both are replayed from the game's event log, exact to the tenth. Recorded as a correction.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review"
+                ],
+                "summary": "Rewind to a game event or game clock time",
+                "parameters": [
+                    {
+                        "description": "Event seq or game clock time",
+                        "name": "rewind",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.RewindReviewRequest"
+                        }
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -71,22 +723,64 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
                 }
             }
         },
-        "/api/foulB/increment": {
+        "/api/review/start": {
             "post": {
+                "description": "Stops both clocks and broadcasts review_update with status \"review\". The clocks cannot be started until the review ends.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "foul"
+                    "review"
+                ],
+                "summary": "Start a review",
+                "parameters": [
+                    {
+                        "description": "Review reason",
+                        "name": "review",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.StartReviewRequest"
+                        }
+                    }
                 ],
-                "summary": "Increment Team B foul",
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.Review"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -95,16 +789,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/game/reset": {
-            "post": {
-                "description": "Resets timer, shot clock, and both team scores to default values",
+        "/api/schema": {
+            "get": {
+                "description": "Returns a JSON Schema (draft 2020-12) describing the versioned envelope and every typed payload",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "game"
+                    "state"
                 ],
-                "summary": "Reset the game",
+                "summary": "Get WebSocket message schema",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -116,18 +810,46 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/log": {
-            "get": {
+        "/api/score": {
+            "put": {
+                "description": "Sets one or both scores, validated against the rules profile (e.g. the 3x3 score cap). Broadcasts a single state_sync and logs a single correction.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "log"
+                    "score"
+                ],
+                "summary": "Correct scores",
+                "parameters": [
+                    {
+                        "description": "Corrected scores",
+                        "name": "scores",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SetScoresRequest"
+                        }
+                    }
                 ],
-                "summary": "Get change log",
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ScoreboardState"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -172,6 +894,13 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
                 }
             }
@@ -212,12 +941,20 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
                 }
             }
         },
         "/api/shotclock/reset": {
             "post": {
+                "description": "Resets the shot clock to the rules profile's value. A reset from zero restarts the clocks.",
                 "produces": [
                     "application/json"
                 ],
@@ -238,7 +975,7 @@ const docTemplate = `{
         },
         "/api/shotclock/set": {
             "post": {
-                "description": "Sets the shot clock to a specific value in ss.x format",
+                "description": "Sets the shot clock to a specific value in ss.x format, capped at the rules profile's shot clock. The response has the value set.",
                 "consumes": [
                     "application/json"
                 ],
@@ -354,7 +1091,7 @@ const docTemplate = `{
         },
         "/api/timer/reset": {
             "post": {
-                "description": "Resets the main timer to 10:00",
+                "description": "Stops the main timer and resets it to the rules profile's game length",
                 "produces": [
                     "application/json"
                 ],
@@ -375,7 +1112,7 @@ const docTemplate = `{
         },
         "/api/timer/set": {
             "post": {
-                "description": "Sets the main timer to a specific value in mm:ss format",
+                "description": "Sets the main timer to a specific value in mm:ss or mm:ss.t format, capped at the rules profile's game clock. The response has the value set.",
                 "consumes": [
                     "application/json"
                 ],
@@ -407,26 +1144,495 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "/healthz/live": {
+            "get": {
+                "description": "Checks that the WebSocket hub loop is processing. Returns 503 with details when it is wedged.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.HealthResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz/ready": {
+            "get": {
+                "description": "Checks the WebSocket hub loop, that the game and audit logs are writable, and that running clocks are ticking",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.HealthResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/overlay": {
+            "get": {
+                "description": "Transparent HTML overlay driven by /ws/state. layout is bug, full or lower-third; teamA, teamB, colorA and colorB (hex without '#') label the teams.",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "overlay"
+                ],
+                "summary": "Broadcast overlay",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "bug",
+                        "description": "bug, full or lower-third",
+                        "name": "layout",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Team A name",
+                        "name": "teamA",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Team B name",
+                        "name": "teamB",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Team A colour, hex without '#'",
+                        "name": "colorA",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Team B colour, hex without '#'",
+                        "name": "colorB",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "HTML",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
         }
     },
     "definitions": {
+        "handlers.AdjustClocksRequest": {
+            "description": "Signed deltas in tenths or absolute values for either clock, with an optional reason",
+            "type": "object",
+            "properties": {
+                "gameClock": {
+                    "description": "mm:ss.t, mm:ss or ss.t",
+                    "type": "string"
+                },
+                "gameClockDeltaTenths": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "shotClock": {
+                    "description": "ss.t",
+                    "type": "string"
+                },
+                "shotClockDeltaTenths": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.CheckResult": {
+            "type": "object",
+            "properties": {
+                "durationMs": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "\"ok\" or \"fail\"",
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.EndReviewRequest": {
+            "description": "Review outcome, and whether to restart the clocks straight away",
+            "type": "object",
+            "properties": {
+                "outcome": {
+                    "type": "string"
+                },
+                "resumeClocks": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "handlers.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/handlers.CheckResult"
+                    }
+                },
+                "status": {
+                    "description": "\"ok\" when every check passed",
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ResetGameRequest": {
+            "description": "Confirmation token from the first call and an optional reason for the audit log",
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.RewindReviewRequest": {
+            "description": "Game event (seq) or game clock time (mm:ss.t) to rewind to, with an optional reason",
+            "type": "object",
+            "properties": {
+                "event": {
+                    "type": "integer"
+                },
+                "gameClock": {
+                    "description": "mm:ss.t, mm:ss or ss.t",
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.SetFoulsRequest": {
+            "description": "Corrected foul counts and an optional reason",
+            "type": "object",
+            "properties": {
+                "foulA": {
+                    "type": "integer"
+                },
+                "foulB": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.SetScoresRequest": {
+            "description": "Corrected scores and an optional reason",
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                },
+                "scoreA": {
+                    "type": "integer"
+                },
+                "scoreB": {
+                    "type": "integer"
+                }
+            }
+        },
         "handlers.SetShotClockRequest": {
             "description": "Timer in ss.x format",
             "type": "object",
             "properties": {
+                "reason": {
+                    "type": "string"
+                },
                 "time": {
                     "type": "string"
                 }
             }
         },
         "handlers.SetTimerRequest": {
-            "description": "Timer in mm:ss format",
+            "description": "Timer in mm:ss or mm:ss.t format, with an optional reason for the audit log",
             "type": "object",
             "properties": {
+                "reason": {
+                    "type": "string"
+                },
                 "time": {
                     "type": "string"
                 }
             }
+        },
+        "handlers.StartReviewRequest": {
+            "description": "Why the play is being reviewed",
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Actor": {
+            "type": "object",
+            "properties": {
+                "clientIp": {
+                    "description": "Address the change came from",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Device or operator ID, empty for anonymous callers",
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "Operator's explanation, recorded for audited actions",
+                    "type": "string"
+                },
+                "requestId": {
+                    "description": "HTTP request or WebSocket connection ID",
+                    "type": "string"
+                },
+                "route": {
+                    "description": "REST route, WebSocket message type, remote transport or input device",
+                    "type": "string"
+                },
+                "source": {
+                    "description": "SourceREST, SourceWebSocket, SourceRemote, SourceInput or SourceSystem",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ScoreboardState": {
+            "type": "object",
+            "properties": {
+                "foulA": {
+                    "description": "Current fouls for Team A",
+                    "type": "integer"
+                },
+                "foulB": {
+                    "description": "Current fouls for Team B",
+                    "type": "integer"
+                },
+                "isShotClockRunning": {
+                    "description": "Shot clock running status",
+                    "type": "boolean"
+                },
+                "scoreA": {
+                    "description": "Current score for Team A",
+                    "type": "integer"
+                },
+                "scoreB": {
+                    "description": "Current score for Team B",
+                    "type": "integer"
+                },
+                "shotClockTenths": {
+                    "description": "Shot clock in tenths of a second",
+                    "type": "integer"
+                },
+                "timerTenths": {
+                    "description": "Timer in tenths of a second",
+                    "type": "integer"
+                }
+            }
+        },
+        "overlay.Data": {
+            "type": "object",
+            "properties": {
+                "foulA": {
+                    "type": "string"
+                },
+                "foulB": {
+                    "type": "string"
+                },
+                "gameClock": {
+                    "description": "By the display rules, e.g. mm:ss or ss.t",
+                    "type": "string"
+                },
+                "gameId": {
+                    "type": "string"
+                },
+                "playback": {
+                    "description": "\"PLAYBACK\" while a past game is played back, otherwise empty",
+                    "type": "string"
+                },
+                "review": {
+                    "description": "\"REVIEW\" during a review, otherwise empty",
+                    "type": "string"
+                },
+                "scoreA": {
+                    "type": "string"
+                },
+                "scoreB": {
+                    "type": "string"
+                },
+                "shotClock": {
+                    "description": "By the display rules, e.g. ss or s.t",
+                    "type": "string"
+                },
+                "shotClockRunning": {
+                    "description": "\"true\" or \"false\"",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "\"live\" or \"review\"",
+                    "type": "string"
+                },
+                "teamA": {
+                    "type": "string"
+                },
+                "teamB": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.ClockAdjustResult": {
+            "type": "object",
+            "properties": {
+                "clamped": {
+                    "type": "boolean"
+                },
+                "state": {
+                    "$ref": "#/definitions/models.ScoreboardState"
+                }
+            }
+        },
+        "services.Playback": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "integer"
+                },
+                "position": {
+                    "description": "Events played so far",
+                    "type": "integer"
+                },
+                "source": {
+                    "description": "Game ID, or \"file\" for uploaded events",
+                    "type": "string"
+                },
+                "speed": {
+                    "description": "0 when stepping",
+                    "type": "integer"
+                },
+                "startedAt": {
+                    "type": "string"
+                },
+                "state": {
+                    "description": "What the displays show",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ScoreboardState"
+                        }
+                    ]
+                },
+                "step": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "services.ResetConfirmation": {
+            "type": "object",
+            "properties": {
+                "expiresAt": {
+                    "type": "string"
+                },
+                "summary": {
+                    "$ref": "#/definitions/services.ResetSummary"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.ResetSummary": {
+            "type": "object",
+            "properties": {
+                "archived": {
+                    "description": "The game will be archived and can be restored",
+                    "type": "boolean"
+                },
+                "description": {
+                    "description": "One line for a confirmation dialog",
+                    "type": "string"
+                },
+                "gameLogEntries": {
+                    "type": "integer"
+                },
+                "state": {
+                    "$ref": "#/definitions/models.ScoreboardState"
+                }
+            }
+        },
+        "services.Review": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "$ref": "#/definitions/models.Actor"
+                },
+                "before": {
+                    "description": "State when the review started",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ScoreboardState"
+                        }
+                    ]
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "rewoundTo": {
+                    "description": "Seq of the game event rewound to, if any",
+                    "type": "integer"
+                },
+                "startedAt": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
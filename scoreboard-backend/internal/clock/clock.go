@@ -0,0 +1,179 @@
+// Package clock lets the services tell the time and tick without calling the
+// time package directly. Real is the system clock; Fake only moves when told
+// to, so the game and shot clocks can be driven one tick at a time.
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock tells the time and makes tickers and timers
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	NewTicker(d time.Duration) Ticker
+	// After sends the time once d has passed, like time.After
+	After(d time.Duration) <-chan time.Time
+}
+
+// Ticker delivers ticks on C until stopped, like time.Ticker
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Real is the system clock
+type Real struct{}
+
+func (Real) Now() time.Time                  { return time.Now() }
+func (Real) Since(t time.Time) time.Duration { return time.Since(t) }
+func (Real) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+func (Real) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+type realTicker struct {
+	ticker *time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time { return t.ticker.C }
+func (t realTicker) Stop()               { t.ticker.Stop() }
+
+// Fake is a clock that stands still until Advance. Unlike a real ticker, a
+// fake one never drops a tick: Advance hands each due tick over and waits
+// for it to be received, or for the ticker to be stopped. A timer from After
+// fires once and is never waited for.
+type Fake struct {
+	mutex   sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+// NewFake returns a fake clock reading start
+func NewFake(start time.Time) *Fake {
+	return &Fake{now: start}
+}
+
+func (f *Fake) Now() time.Time {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.now
+}
+
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	t := &fakeTicker{
+		clock:   f,
+		period:  d,
+		next:    f.now.Add(d),
+		c:       make(chan time.Time),
+		stopped: make(chan struct{}),
+	}
+	f.tickers = append(f.tickers, t)
+	return t
+}
+
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	t := &fakeTicker{
+		clock:   f,
+		next:    f.now.Add(d),
+		c:       make(chan time.Time, 1),
+		stopped: make(chan struct{}),
+	}
+	f.tickers = append(f.tickers, t)
+	return t.c
+}
+
+// Advance moves the clock forward by d, delivering every tick and timer that
+// falls due on the way in time order. The clock reads each one's time while
+// it is delivered. It returns the number of ticks received.
+func (f *Fake) Advance(d time.Duration) int {
+	f.mutex.Lock()
+	end := f.now.Add(d)
+	f.mutex.Unlock()
+	received := 0
+	for {
+		t, at := f.nextTick(end)
+		if t == nil {
+			break
+		}
+		if t.period == 0 {
+			t.c <- at // a timer's buffer is empty until its only send
+			t.Stop()
+			continue
+		}
+		select {
+		case t.c <- at:
+			received++
+		case <-t.stopped:
+		}
+	}
+	f.mutex.Lock()
+	f.now = end
+	f.mutex.Unlock()
+	return received
+}
+
+// Tickers returns the number of tickers not yet stopped and timers not yet fired
+func (f *Fake) Tickers() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return len(f.tickers)
+}
+
+// nextTick finds the earliest tick due by end, moves the clock to it and
+// schedules that ticker's next tick
+func (f *Fake) nextTick(end time.Time) (*fakeTicker, time.Time) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	sort.SliceStable(f.tickers, func(i, j int) bool { return f.tickers[i].next.Before(f.tickers[j].next) })
+	if len(f.tickers) == 0 || f.tickers[0].next.After(end) {
+		return nil, time.Time{}
+	}
+	t := f.tickers[0]
+	at := t.next
+	t.next = at.Add(t.period)
+	f.now = at
+	return t, at
+}
+
+func (f *Fake) remove(t *fakeTicker) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for i, other := range f.tickers {
+		if other == t {
+			f.tickers = append(f.tickers[:i], f.tickers[i+1:]...)
+			return
+		}
+	}
+}
+
+type fakeTicker struct {
+	clock   *Fake
+	period  time.Duration // 0 for a timer from After
+	next    time.Time     // guarded by clock.mutex
+	c       chan time.Time
+	stopped chan struct{}
+	once    sync.Once
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.c }
+
+func (t *fakeTicker) Stop() {
+	t.once.Do(func() {
+		close(t.stopped)
+		t.clock.remove(t)
+	})
+}
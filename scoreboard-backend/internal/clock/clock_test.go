@@ -0,0 +1,138 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+var start = time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+
+func TestFakeStandsStill(t *testing.T) {
+	f := NewFake(start)
+	if !f.Now().Equal(start) {
+		t.Errorf("Now %v, want %v", f.Now(), start)
+	}
+	if n := f.Advance(1500 * time.Millisecond); n != 0 {
+		t.Errorf("%d ticks without a ticker", n)
+	}
+	if got := f.Since(start); got != 1500*time.Millisecond {
+		t.Errorf("Since %v after advancing 1.5s", got)
+	}
+}
+
+func TestFakeTickerDeliversEveryTick(t *testing.T) {
+	f := NewFake(start)
+	ticker := f.NewTicker(100 * time.Millisecond)
+	ticks := make(chan time.Time, 3)
+	go func() {
+		for i := 0; i < 3; i++ {
+			ticks <- <-ticker.C()
+		}
+		ticker.Stop()
+	}()
+
+	if n := f.Advance(250 * time.Millisecond); n != 2 {
+		t.Errorf("Advance(250ms) delivered %d ticks, want 2", n)
+	}
+	if n := f.Advance(50 * time.Millisecond); n != 1 {
+		t.Errorf("Advance(50ms) delivered %d ticks, want the one due at 300ms", n)
+	}
+	for i := 1; i <= 3; i++ {
+		if at, want := <-ticks, start.Add(time.Duration(i)*100*time.Millisecond); !at.Equal(want) {
+			t.Errorf("tick %d sent %v, want %v", i, at, want)
+		}
+	}
+	if !f.Now().Equal(start.Add(300 * time.Millisecond)) {
+		t.Errorf("Now %v after advancing 300ms", f.Now())
+	}
+}
+
+func TestFakeTickersInTimeOrder(t *testing.T) {
+	f := NewFake(start)
+	fast, slow := f.NewTicker(100*time.Millisecond), f.NewTicker(250*time.Millisecond)
+	// One receiver sees the ticks in the order Advance sends them
+	order := make(chan string, 4)
+	go func() {
+		for i := 0; i < 4; i++ {
+			select {
+			case at := <-fast.C():
+				order <- "fast " + at.Sub(start).String()
+			case at := <-slow.C():
+				order <- "slow " + at.Sub(start).String()
+			}
+		}
+		fast.Stop()
+		slow.Stop()
+	}()
+
+	if n := f.Advance(300 * time.Millisecond); n != 4 {
+		t.Fatalf("delivered %d ticks, want 4", n)
+	}
+	want := []string{"fast 100ms", "fast 200ms", "slow 250ms", "fast 300ms"}
+	for _, w := range want {
+		if got := <-order; got != w {
+			t.Errorf("got %s, want %s", got, w)
+		}
+	}
+}
+
+func TestFakeStoppedTickerDoesNotBlock(t *testing.T) {
+	f := NewFake(start)
+	ticker := f.NewTicker(100 * time.Millisecond)
+	if f.Tickers() != 1 {
+		t.Fatalf("Tickers %d, want 1", f.Tickers())
+	}
+	ticker.Stop()
+	ticker.Stop()
+	if f.Tickers() != 0 {
+		t.Errorf("Tickers %d after Stop", f.Tickers())
+	}
+	if n := f.Advance(time.Second); n != 0 {
+		t.Errorf("a stopped ticker received %d ticks", n)
+	}
+}
+
+func TestFakeTickerStoppedWhileDue(t *testing.T) {
+	// Advance gives up on a tick nobody receives once the ticker is stopped
+	f := NewFake(start)
+	ticker := f.NewTicker(100 * time.Millisecond)
+	go func() {
+		for f.Now().Before(start.Add(100 * time.Millisecond)) {
+			time.Sleep(time.Millisecond)
+		}
+		ticker.Stop()
+	}()
+	if n := f.Advance(time.Second); n != 0 {
+		t.Errorf("%d ticks received, want none", n)
+	}
+}
+
+func TestFakeAfterFiresOnce(t *testing.T) {
+	f := NewFake(start)
+	c := f.After(time.Second)
+	f.Advance(999 * time.Millisecond)
+	select {
+	case <-c:
+		t.Fatal("After fired early")
+	default:
+	}
+	// Nobody is receiving yet, which Advance does not wait for
+	if n := f.Advance(time.Second); n != 0 {
+		t.Errorf("a timer counted as %d ticks", n)
+	}
+	if at := <-c; !at.Equal(start.Add(time.Second)) {
+		t.Errorf("fired at %v", at)
+	}
+	if f.Tickers() != 0 {
+		t.Errorf("Tickers %d after the timer fired", f.Tickers())
+	}
+}
+
+func TestFakeNewTickerPanicsOnZero(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic for a zero interval")
+		}
+	}()
+	NewFake(start).NewTicker(0)
+}
@@ -0,0 +1,360 @@
+// Package config loads backend settings from a JSON file, environment
+// variables and command-line flags, in that order of precedence (flags win).
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"scoreboard-backend/internal/format"
+	"scoreboard-backend/internal/rules"
+	"strconv"
+	"strings"
+)
+
+// Config is the effective backend configuration
+type Config struct {
+	ListenAddr     string       `json:"listenAddr"`     // Address the HTTP server binds
+	TLSCert        string       `json:"tlsCert"`        // PEM certificate; TLS is enabled when both cert and key are set
+	TLSKey         string       `json:"tlsKey"`         // PEM private key
+	AllowedOrigins []string     `json:"allowedOrigins"` // CORS and WebSocket origins; "*" allows all
+	DataDir        string       `json:"dataDir"`        // Directory for game.log and other persisted files
+	RulesProfile   string       `json:"rulesProfile"`   // Default rules profile, see package rules
+	LogLevel       string       `json:"logLevel"`       // debug, info, warn or error
+	LogFormat      string       `json:"logFormat"`      // text or json
+	CourtID        string       `json:"courtId"`        // Court label for metrics
+	ArchiveOnReset bool         `json:"archiveOnReset"` // Save each game before a reset so it can be restored
+	Display        format.Rules `json:"display"`        // How broadcasts format the clocks
+	Remote         Remote       `json:"remote"`
+	Input          Input        `json:"input"`
+	LED            LED          `json:"led"`
+}
+
+// Remote configures the TCP/UDP hardware remote listener
+type Remote struct {
+	TCPAddr string `json:"tcpAddr"`
+	UDPAddr string `json:"udpAddr"`
+	Key     string `json:"key"`
+}
+
+// Input configures the evdev keypad daemon
+type Input struct {
+	Devices []string `json:"devices"`
+	KeyMap  string   `json:"keyMap"`
+	Grab    bool     `json:"grab"`
+}
+
+// LED configures the LED board output on a serial port. The protocol and
+// line settings are checked by package led when the port is opened.
+type LED struct {
+	Port      string `json:"port"`     // Serial device, or "pty" for a simulated port; empty disables the output
+	Protocol  string `json:"protocol"` // ascii or segments
+	Address   int    `json:"address"`  // Controller address, 0-99
+	Baud      int    `json:"baud"`
+	Parity    string `json:"parity"` // none, even or odd
+	StopBits  int    `json:"stopBits"`
+	RS485     bool   `json:"rs485"`     // Kernel RS-485 mode, RTS as transmit enable
+	RefreshMs int    `json:"refreshMs"` // Resend an unchanged frame this often
+}
+
+// Default returns the settings used when nothing is configured
+func Default() Config {
+	return Config{
+		ListenAddr:     ":8080",
+		AllowedOrigins: []string{"*"},
+		DataDir:        ".",
+		RulesProfile:   rules.DefaultProfile,
+		LogLevel:       "info",
+		LogFormat:      "text",
+		CourtID:        "1",
+		ArchiveOnReset: true,
+		Display:        format.DefaultRules(),
+		LED: LED{
+			Protocol:  "ascii",
+			Baud:      9600,
+			Parity:    "none",
+			StopBits:  1,
+			RefreshMs: 1000,
+		},
+	}
+}
+
+// GameLogPath is where the game log lives inside the data directory
+func (c Config) GameLogPath() string {
+	return filepath.Join(c.DataDir, "game.log")
+}
+
+// AuditLogPath is where the append-only audit log lives inside the data directory
+func (c Config) AuditLogPath() string {
+	return filepath.Join(c.DataDir, "audit.ndjson")
+}
+
+// ArchiveDir is where games are archived before a reset
+func (c Config) ArchiveDir() string {
+	return filepath.Join(c.DataDir, "archive")
+}
+
+// GamesDir is where each game's event log is kept
+func (c Config) GamesDir() string {
+	return filepath.Join(c.DataDir, "games")
+}
+
+// StatePath is where the scoreboard state is saved at shutdown and restored from at startup
+func (c Config) StatePath() string {
+	return filepath.Join(c.DataDir, "state.json")
+}
+
+// TLSEnabled reports whether both a certificate and a key are configured
+func (c Config) TLSEnabled() bool {
+	return c.TLSCert != "" && c.TLSKey != ""
+}
+
+// AllowsAllOrigins reports whether the origin list contains "*" or is empty
+func (c Config) AllowsAllOrigins() bool {
+	for _, o := range c.AllowedOrigins {
+		if o == "*" {
+			return true
+		}
+	}
+	return len(c.AllowedOrigins) == 0
+}
+
+// Redacted returns a copy safe to print, with secrets masked
+func (c Config) Redacted() Config {
+	if c.Remote.Key != "" {
+		c.Remote.Key = "***"
+	}
+	return c
+}
+
+// Validate checks values that would otherwise fail later at startup
+func (c Config) Validate() error {
+	if _, err := rules.Lookup(c.RulesProfile); err != nil {
+		return err
+	}
+	switch c.LogLevel {
+	case "debug", "info", "warn", "error":
+	default:
+		return fmt.Errorf("invalid log level %q", c.LogLevel)
+	}
+	switch c.LogFormat {
+	case "text", "json":
+	default:
+		return fmt.Errorf("invalid log format %q", c.LogFormat)
+	}
+	if err := c.Display.Validate(); err != nil {
+		return err
+	}
+	if c.LED.Port != "" && c.LED.RefreshMs <= 0 {
+		return fmt.Errorf("led refresh must be positive")
+	}
+	if (c.TLSCert == "") != (c.TLSKey == "") {
+		return fmt.Errorf("tls needs both a certificate and a key")
+	}
+	return nil
+}
+
+// Load builds the configuration from defaults, the file named by -config
+// or CONFIG_FILE, environment variables and finally flags.
+func Load(args []string) (Config, error) {
+	fs := flag.NewFlagSet("scoreboard-backend", flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "JSON config file")
+	var flagValues Config
+	var origins, devices string
+	fs.StringVar(&flagValues.ListenAddr, "listen", "", "listen address, e.g. :8080")
+	fs.StringVar(&flagValues.TLSCert, "tls-cert", "", "TLS certificate file")
+	fs.StringVar(&flagValues.TLSKey, "tls-key", "", "TLS private key file")
+	fs.StringVar(&origins, "allowed-origins", "", "comma-separated allowed origins, * for all")
+	fs.StringVar(&flagValues.DataDir, "data-dir", "", "directory for game.log and persisted state")
+	fs.StringVar(&flagValues.RulesProfile, "rules", "", "default rules profile ("+strings.Join(rules.Names(), ", ")+")")
+	fs.StringVar(&flagValues.LogLevel, "log-level", "", "debug, info, warn or error")
+	fs.StringVar(&flagValues.LogFormat, "log-format", "", "text or json")
+	fs.StringVar(&flagValues.CourtID, "court", "", "court label for metrics")
+	fs.BoolVar(&flagValues.ArchiveOnReset, "archive-on-reset", true, "archive each game before a reset")
+	fs.IntVar(&flagValues.Display.GameClockTenthsBelow, "clock-tenths-below", 0, "show the game clock in tenths below this many tenths, e.g. 600 for the last minute")
+	fs.BoolVar(&flagValues.Display.ShotClockWholeSeconds, "shot-clock-whole-seconds", false, "show the shot clock in whole seconds, rounded up, down to -shot-clock-tenths-below")
+	fs.IntVar(&flagValues.Display.ShotClockTenthsBelow, "shot-clock-tenths-below", 0, "with whole seconds, show the shot clock in tenths below this many tenths, 0 for whole seconds only")
+	fs.BoolVar(&flagValues.Display.LeadingZeros, "clock-leading-zeros", true, "pad clock minutes and seconds to two digits")
+	fs.StringVar(&flagValues.Remote.TCPAddr, "remote-tcp", "", "remote control TCP address")
+	fs.StringVar(&flagValues.Remote.UDPAddr, "remote-udp", "", "remote control UDP address")
+	fs.StringVar(&flagValues.Remote.Key, "remote-key", "", "remote control pre-shared key")
+	fs.StringVar(&devices, "input-devices", "", "comma-separated evdev device paths or globs")
+	fs.StringVar(&flagValues.Input.KeyMap, "input-keymap", "", "JSON key map file")
+	fs.BoolVar(&flagValues.Input.Grab, "input-grab", false, "grab input devices exclusively")
+	fs.StringVar(&flagValues.LED.Port, "led-port", "", "LED board serial device, or pty for a simulated port")
+	fs.StringVar(&flagValues.LED.Protocol, "led-protocol", "", "LED board protocol, ascii or segments")
+	fs.IntVar(&flagValues.LED.Address, "led-address", 0, "LED board controller address")
+	fs.IntVar(&flagValues.LED.Baud, "led-baud", 0, "LED board baud rate")
+	fs.StringVar(&flagValues.LED.Parity, "led-parity", "", "LED board parity: none, even or odd")
+	fs.IntVar(&flagValues.LED.StopBits, "led-stop-bits", 0, "LED board stop bits, 1 or 2")
+	fs.BoolVar(&flagValues.LED.RS485, "led-rs485", false, "drive RTS as the RS-485 transmit enable")
+	fs.IntVar(&flagValues.LED.RefreshMs, "led-refresh-ms", 0, "resend an unchanged frame every this many ms")
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	cfg := Default()
+	if *configFile != "" {
+		data, err := os.ReadFile(*configFile)
+		if err != nil {
+			return Config{}, fmt.Errorf("read config: %w", err)
+		}
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return Config{}, fmt.Errorf("parse config %s: %w", *configFile, err)
+		}
+	}
+
+	if err := applyEnv(&cfg); err != nil {
+		return Config{}, err
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "listen":
+			cfg.ListenAddr = flagValues.ListenAddr
+		case "tls-cert":
+			cfg.TLSCert = flagValues.TLSCert
+		case "tls-key":
+			cfg.TLSKey = flagValues.TLSKey
+		case "allowed-origins":
+			cfg.AllowedOrigins = splitList(origins)
+		case "data-dir":
+			cfg.DataDir = flagValues.DataDir
+		case "rules":
+			cfg.RulesProfile = flagValues.RulesProfile
+		case "log-level":
+			cfg.LogLevel = flagValues.LogLevel
+		case "log-format":
+			cfg.LogFormat = flagValues.LogFormat
+		case "court":
+			cfg.CourtID = flagValues.CourtID
+		case "archive-on-reset":
+			cfg.ArchiveOnReset = flagValues.ArchiveOnReset
+		case "clock-tenths-below":
+			cfg.Display.GameClockTenthsBelow = flagValues.Display.GameClockTenthsBelow
+		case "shot-clock-whole-seconds":
+			cfg.Display.ShotClockWholeSeconds = flagValues.Display.ShotClockWholeSeconds
+		case "shot-clock-tenths-below":
+			cfg.Display.ShotClockTenthsBelow = flagValues.Display.ShotClockTenthsBelow
+		case "clock-leading-zeros":
+			cfg.Display.LeadingZeros = flagValues.Display.LeadingZeros
+		case "remote-tcp":
+			cfg.Remote.TCPAddr = flagValues.Remote.TCPAddr
+		case "remote-udp":
+			cfg.Remote.UDPAddr = flagValues.Remote.UDPAddr
+		case "remote-key":
+			cfg.Remote.Key = flagValues.Remote.Key
+		case "input-devices":
+			cfg.Input.Devices = splitList(devices)
+		case "input-keymap":
+			cfg.Input.KeyMap = flagValues.Input.KeyMap
+		case "input-grab":
+			cfg.Input.Grab = flagValues.Input.Grab
+		case "led-port":
+			cfg.LED.Port = flagValues.LED.Port
+		case "led-protocol":
+			cfg.LED.Protocol = flagValues.LED.Protocol
+		case "led-address":
+			cfg.LED.Address = flagValues.LED.Address
+		case "led-baud":
+			cfg.LED.Baud = flagValues.LED.Baud
+		case "led-parity":
+			cfg.LED.Parity = flagValues.LED.Parity
+		case "led-stop-bits":
+			cfg.LED.StopBits = flagValues.LED.StopBits
+		case "led-rs485":
+			cfg.LED.RS485 = flagValues.LED.RS485
+		case "led-refresh-ms":
+			cfg.LED.RefreshMs = flagValues.LED.RefreshMs
+		}
+	})
+
+	return cfg, cfg.Validate()
+}
+
+// applyEnv overrides cfg with any environment variables that are set. A
+// number or boolean that doesn't parse is an error, not a silent default.
+func applyEnv(cfg *Config) error {
+	if v := os.Getenv("PORT"); v != "" {
+		cfg.ListenAddr = ":" + v
+	}
+	setString(&cfg.ListenAddr, "LISTEN_ADDR")
+	setString(&cfg.TLSCert, "TLS_CERT")
+	setString(&cfg.TLSKey, "TLS_KEY")
+	if v, ok := os.LookupEnv("ALLOWED_ORIGINS"); ok {
+		cfg.AllowedOrigins = splitList(v)
+	}
+	setString(&cfg.DataDir, "DATA_DIR")
+	setString(&cfg.RulesProfile, "RULES_PROFILE")
+	setString(&cfg.LogLevel, "LOG_LEVEL")
+	setString(&cfg.LogFormat, "LOG_FORMAT")
+	setString(&cfg.CourtID, "COURT_ID")
+	setString(&cfg.Remote.TCPAddr, "REMOTE_TCP_ADDR")
+	setString(&cfg.Remote.UDPAddr, "REMOTE_UDP_ADDR")
+	setString(&cfg.Remote.Key, "REMOTE_KEY")
+	if v, ok := os.LookupEnv("INPUT_DEVICES"); ok {
+		cfg.Input.Devices = splitList(v)
+	}
+	setString(&cfg.Input.KeyMap, "INPUT_KEYMAP")
+	setString(&cfg.LED.Port, "LED_PORT")
+	setString(&cfg.LED.Protocol, "LED_PROTOCOL")
+	setString(&cfg.LED.Parity, "LED_PARITY")
+	return errors.Join(
+		setBool(&cfg.ArchiveOnReset, "ARCHIVE_ON_RESET"),
+		setInt(&cfg.Display.GameClockTenthsBelow, "CLOCK_TENTHS_BELOW"),
+		setBool(&cfg.Display.ShotClockWholeSeconds, "SHOT_CLOCK_WHOLE_SECONDS"),
+		setInt(&cfg.Display.ShotClockTenthsBelow, "SHOT_CLOCK_TENTHS_BELOW"),
+		setBool(&cfg.Display.LeadingZeros, "CLOCK_LEADING_ZEROS"),
+		setBool(&cfg.Input.Grab, "INPUT_GRAB"),
+		setInt(&cfg.LED.Address, "LED_ADDRESS"),
+		setInt(&cfg.LED.Baud, "LED_BAUD"),
+		setInt(&cfg.LED.StopBits, "LED_STOP_BITS"),
+		setBool(&cfg.LED.RS485, "LED_RS485"),
+		setInt(&cfg.LED.RefreshMs, "LED_REFRESH_MS"),
+	)
+}
+
+func setString(dst *string, name string) {
+	if v, ok := os.LookupEnv(name); ok && v != "" {
+		*dst = v
+	}
+}
+
+func setInt(dst *int, name string) error {
+	v, ok := os.LookupEnv(name)
+	if !ok || v == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("%s must be a whole number, not %q", name, v)
+	}
+	*dst = n
+	return nil
+}
+
+func setBool(dst *bool, name string) error {
+	v, ok := os.LookupEnv(name)
+	if !ok || v == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fmt.Errorf("%s must be true or false, not %q", name, v)
+	}
+	*dst = b
+	return nil
+}
+
+func splitList(v string) []string {
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
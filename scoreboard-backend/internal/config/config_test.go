@@ -0,0 +1,65 @@
+package config
+
+import (
+	"scoreboard-backend/internal/led"
+	"strings"
+	"testing"
+)
+
+func TestInvalidEnvValues(t *testing.T) {
+	tests := []struct {
+		name, value, want string
+	}{
+		{"ARCHIVE_ON_RESET", "yes", `ARCHIVE_ON_RESET must be true or false, not "yes"`},
+		{"CLOCK_LEADING_ZEROS", "on", `CLOCK_LEADING_ZEROS must be true or false`},
+		{"SHOT_CLOCK_WHOLE_SECONDS", "whole", `SHOT_CLOCK_WHOLE_SECONDS must be true or false`},
+		{"INPUT_GRAB", "grab", `INPUT_GRAB must be true or false`},
+		{"LED_RS485", "1x", `LED_RS485 must be true or false`},
+		{"LED_BAUD", "9600baud", `LED_BAUD must be a whole number, not "9600baud"`},
+		{"CLOCK_TENTHS_BELOW", "60.5", `CLOCK_TENTHS_BELOW must be a whole number`},
+		{"LED_REFRESH_MS", "1s", `LED_REFRESH_MS must be a whole number`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(tt.name, tt.value)
+			_, err := Load(nil)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load with %s=%s: got %v, want %q", tt.name, tt.value, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvValues(t *testing.T) {
+	t.Setenv("ARCHIVE_ON_RESET", "false")
+	t.Setenv("CLOCK_LEADING_ZEROS", "0")
+	t.Setenv("SHOT_CLOCK_WHOLE_SECONDS", "1")
+	t.Setenv("INPUT_GRAB", "TRUE")
+	t.Setenv("LED_BAUD", "19200")
+	t.Setenv("LED_ADDRESS", "") // Empty counts as unset
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ArchiveOnReset || cfg.Display.LeadingZeros || !cfg.Display.ShotClockWholeSeconds || !cfg.Input.Grab || cfg.LED.Baud != 19200 || cfg.LED.Address != 0 {
+		t.Errorf("env values not applied: %+v", cfg)
+	}
+}
+
+func TestEnvErrorsAreAllReported(t *testing.T) {
+	t.Setenv("INPUT_GRAB", "maybe")
+	t.Setenv("LED_STOP_BITS", "one")
+	_, err := Load(nil)
+	if err == nil || !strings.Contains(err.Error(), "INPUT_GRAB") || !strings.Contains(err.Error(), "LED_STOP_BITS") {
+		t.Errorf("got %v, want both variables named", err)
+	}
+}
+
+func TestLEDDefaultsMatchPackageLED(t *testing.T) {
+	got := Default().LED
+	serial := led.DefaultSerialConfig()
+	if got.Protocol != led.DefaultProtocol || got.Baud != serial.Baud || got.Parity != serial.Parity ||
+		got.StopBits != serial.StopBits || got.RS485 != serial.RS485 || got.RefreshMs != int(led.DefaultRefresh.Milliseconds()) {
+		t.Errorf("config LED defaults %+v differ from package led's %+v", got, serial)
+	}
+}
@@ -0,0 +1,100 @@
+// Package format renders clock tenths as text. Rules are the configurable
+// display formats sent with every broadcast, so displays show the clocks
+// without formatting them. The game log and corrections use the fixed
+// formats below instead, so their lines can be parsed back whatever the
+// display rules are.
+package format
+
+import (
+	"errors"
+	"fmt"
+	"scoreboard-backend/internal/models"
+)
+
+// Rules say how the game and shot clocks are displayed. The game clock counts
+// down in mm:ss above its tenths threshold, with seconds cut so 4:59.9 shows
+// as 04:59, and in ss.t below it. The shot clock shows ss.t unless whole
+// seconds are turned on; those round up, as shot clocks do, so 11.9 shows as
+// 12 and the clock reads 12 for the whole first second.
+type Rules struct {
+	// GameClockTenthsBelow switches the game clock from mm:ss to ss.t below
+	// this many tenths, e.g. 600 for the last minute
+	GameClockTenthsBelow int `json:"gameClockTenthsBelow"`
+	// ShotClockWholeSeconds shows the shot clock in whole seconds at and
+	// above ShotClockTenthsBelow
+	ShotClockWholeSeconds bool `json:"shotClockWholeSeconds"`
+	// ShotClockTenthsBelow switches the shot clock from whole seconds to
+	// ss.t below this many tenths, e.g. 50 for whole seconds above 5s.
+	// 0 always shows whole seconds.
+	ShotClockTenthsBelow int `json:"shotClockTenthsBelow"`
+	// LeadingZeros pads minutes and seconds to two digits: 04:12, 09.5 and
+	// 08 rather than 4:12, 9.5 and 8
+	LeadingZeros bool `json:"leadingZeros"`
+}
+
+// DefaultRules show tenths in the game clock's last minute and on the shot
+// clock throughout, with leading zeros, as GET /api/state always has. Turning
+// on ShotClockWholeSeconds gives whole seconds above 5s.
+func DefaultRules() Rules {
+	return Rules{GameClockTenthsBelow: 600, ShotClockTenthsBelow: 50, LeadingZeros: true}
+}
+
+// Validate rejects negative thresholds
+func (r Rules) Validate() error {
+	if r.GameClockTenthsBelow < 0 || r.ShotClockTenthsBelow < 0 {
+		return errors.New("clock tenths thresholds cannot be negative")
+	}
+	return nil
+}
+
+// GameClock renders the game clock as mm:ss, or ss.t below the threshold
+func (r Rules) GameClock(tenths int) string {
+	if tenths < r.GameClockTenthsBelow {
+		return r.pad(tenths/10) + fmt.Sprintf(".%d", tenths%10)
+	}
+	return r.pad(tenths/600) + fmt.Sprintf(":%02d", (tenths/10)%60)
+}
+
+// ShotClock renders the shot clock as ss.t, or with whole seconds as seconds
+// rounded up down to the threshold
+func (r Rules) ShotClock(tenths int) string {
+	if !r.ShotClockWholeSeconds || tenths < r.ShotClockTenthsBelow {
+		return r.pad(tenths/10) + fmt.Sprintf(".%d", tenths%10)
+	}
+	return r.pad((tenths + 9) / 10)
+}
+
+// Clocks formats both clocks for a broadcast
+func (r Rules) Clocks(timerTenths, shotClockTenths int) models.FormattedClocks {
+	return models.FormattedClocks{
+		FormattedTimer:     r.GameClock(timerTenths),
+		FormattedShotclock: r.ShotClock(shotClockTenths),
+	}
+}
+
+func (r Rules) pad(n int) string {
+	if r.LeadingZeros {
+		return fmt.Sprintf("%02d", n)
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+// logRules are the game log's fixed format, which review parses back
+var logRules = Rules{GameClockTenthsBelow: 600, LeadingZeros: true}
+
+// LogClock renders the game clock as mm:ss, or ss.t in the last minute, the
+// prefix of every game log line
+func LogClock(tenths int) string {
+	return logRules.GameClock(tenths)
+}
+
+// Precise renders the game clock as mm:ss.t, keeping the tenth a correction
+// is made to even above the last minute
+func Precise(tenths int) string {
+	return fmt.Sprintf("%02d:%02d.%d", tenths/600, (tenths/10)%60, tenths%10)
+}
+
+// PreciseShotClock renders the shot clock as s.t
+func PreciseShotClock(tenths int) string {
+	return fmt.Sprintf("%d.%d", tenths/10, tenths%10)
+}
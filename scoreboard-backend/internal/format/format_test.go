@@ -0,0 +1,60 @@
+package format
+
+import "testing"
+
+func TestShotClock(t *testing.T) {
+	whole := DefaultRules()
+	whole.ShotClockWholeSeconds = true
+	noZeros := whole
+	noZeros.LeadingZeros = false
+	tests := []struct {
+		name   string
+		rules  Rules
+		tenths int
+		want   string
+	}{
+		{"default at the start", DefaultRules(), 120, "12.0"},
+		{"default in the first second", DefaultRules(), 119, "11.9"},
+		{"default below 5s", DefaultRules(), 49, "04.9"},
+		{"whole at the start", whole, 120, "12"},
+		{"whole rounds up", whole, 119, "12"},
+		{"whole on the second", whole, 110, "11"},
+		{"whole just above the threshold", whole, 51, "06"},
+		{"whole at the threshold", whole, 50, "05"},
+		{"whole below the threshold", whole, 49, "04.9"},
+		{"whole at zero", whole, 0, "00.0"},
+		{"whole without zeros", noZeros, 81, "9"},
+		{"whole without a threshold", Rules{ShotClockWholeSeconds: true}, 1, "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rules.ShotClock(tt.tenths); got != tt.want {
+				t.Errorf("ShotClock(%d) = %q, want %q", tt.tenths, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGameClock(t *testing.T) {
+	noZeros := DefaultRules()
+	noZeros.LeadingZeros = false
+	tests := []struct {
+		rules  Rules
+		tenths int
+		want   string
+	}{
+		{DefaultRules(), 6000, "10:00"},
+		{DefaultRules(), 2999, "04:59"}, // Cut, not rounded
+		{DefaultRules(), 600, "01:00"},
+		{DefaultRules(), 599, "59.9"},
+		{DefaultRules(), 95, "09.5"},
+		{noZeros, 2520, "4:12"},
+		{noZeros, 95, "9.5"},
+		{Rules{LeadingZeros: true}, 5, "00:00"},
+	}
+	for _, tt := range tests {
+		if got := tt.rules.GameClock(tt.tenths); got != tt.want {
+			t.Errorf("%+v GameClock(%d) = %q, want %q", tt.rules, tt.tenths, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"net/http"
+	"scoreboard-backend/internal/format"
+	"scoreboard-backend/internal/models"
+	"scoreboard-backend/internal/rules"
+	"scoreboard-backend/internal/services"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// The handlers depend on these rather than on the concrete services, so any
+// of them can be swapped for a fake. Changes still go through Control.
+
+// Scoreboard is the game state the handlers read
+type Scoreboard interface {
+	Snapshot() services.Snapshot
+	GameID() string
+	Games() ([]string, error)
+	GameEvents(gameID string) ([]models.Event, error)
+	Rules() rules.Profile
+}
+
+// Hub accepts WebSocket clients and broadcasts to them
+type Hub interface {
+	UpgradeConnection(w http.ResponseWriter, r *http.Request) (*websocket.Conn, error)
+	NewClient(remoteAddr string, encoding string) *models.Client
+	ServeClient(conn *websocket.Conn, client *models.Client, onMessage func(models.InboundMessage))
+	Ping(timeout time.Duration) error
+}
+
+// GameClock runs the game clock down
+type GameClock interface {
+	StartTimer() error
+	StopTimer() error
+	IsRunning() bool
+	CheckAlive(maxAge time.Duration) error
+}
+
+// ShotClock is the shot clock as the health checks see it
+type ShotClock interface {
+	CheckAlive(maxAge time.Duration) error
+}
+
+// Control applies operator actions, broadcasts them and logs them
+type Control interface {
+	AdjustScore(team string, delta int, actor models.Actor) (uint, error)
+	SetScore(team string, score uint, actor models.Actor) error
+	CorrectScores(scoreA, scoreB *uint, actor models.Actor) (models.ScoreboardState, error)
+	AdjustFoul(team string, delta int, actor models.Actor) (uint, error)
+	CorrectFouls(foulA, foulB *uint, actor models.Actor) (models.ScoreboardState, error)
+
+	StartClocks(actor models.Actor) error
+	StopClocks(actor models.Actor)
+	StartTimer(actor models.Actor) error
+	StopTimer(actor models.Actor)
+	SetTimer(tenths int, actor models.Actor) int
+	ResetTimer(actor models.Actor) error
+	SetShotClock(tenths int, actor models.Actor) int
+	ResetShotClock(actor models.Actor)
+	AdjustClocks(adj services.ClockAdjustment, actor models.Actor) (services.ClockAdjustResult, error)
+
+	PrepareReset(actor models.Actor) (services.ResetConfirmation, error)
+	ResetGame(token string, actor models.Actor) (string, error)
+	Archives() ([]services.ArchiveSummary, error)
+	RestoreArchive(id string, actor models.Actor) (models.ScoreboardState, error)
+
+	Review() *services.Review
+	StartReview(reason string, actor models.Actor) (services.Review, error)
+	ReviewEvents() []services.ReviewEvent
+	RewindReview(to services.Rewind, actor models.Actor) (models.ScoreboardState, error)
+	EndReview(outcome string, resumeClocks bool, actor models.Actor) (services.Review, error)
+
+	SyncState() models.ScoreboardState
+	StateSync() models.StateSyncData
+	FormatClocks(state models.ScoreboardState) models.FormattedClocks
+	DisplayRules() format.Rules
+}
+
+// GameLog is the human-readable log of the current game
+type GameLog interface {
+	Lines() ([]string, error)
+	CheckWritable() error
+}
+
+// AuditLog is the record of administrative actions
+type AuditLog interface {
+	Entries(q services.AuditQuery) ([]models.AuditEntry, error)
+	CheckWritable() error
+}
+
+// Playback replays a recorded game to the displays
+type Playback interface {
+	Status() *services.Playback
+	Start(source string, events []models.Event, speed int, step bool) (services.Playback, error)
+	Step() (services.Playback, error)
+	Stop() (services.Playback, error)
+}
+
+var (
+	_ Scoreboard = (*services.ScoreboardService)(nil)
+	_ Hub        = (*services.WebSocketService)(nil)
+	_ GameClock  = (*services.TimerService)(nil)
+	_ ShotClock  = (*services.ShotClockService)(nil)
+	_ Control    = (*services.ControlService)(nil)
+	_ GameLog    = (*services.GameLog)(nil)
+	_ AuditLog   = (*services.AuditLog)(nil)
+	_ Playback   = (*services.PlaybackService)(nil)
+)
@@ -1,33 +1,83 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
-	"os"
+	"scoreboard-backend/internal/format"
+	"scoreboard-backend/internal/logging"
 	"scoreboard-backend/internal/models"
+	"scoreboard-backend/internal/rules"
+	"scoreboard-backend/internal/schema"
 	"scoreboard-backend/internal/services"
-	"time"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/gorilla/websocket"
 )
 
 type ScoreboardHandler struct {
-	scoreboardService *services.ScoreboardService
-	websocketService  *services.WebSocketService
-	timerService      *services.TimerService
+	scoreboardService Scoreboard
+	websocketService  Hub
+	timerService      GameClock
+	controlService    Control
+	gameLog           GameLog
+	auditLog          AuditLog
 }
 
 func NewScoreboardHandler(
-	scoreboardService *services.ScoreboardService,
-	websocketService *services.WebSocketService,
-	timerService *services.TimerService,
+	scoreboardService Scoreboard,
+	websocketService Hub,
+	timerService GameClock,
+	controlService Control,
+	gameLog GameLog,
+	auditLog AuditLog,
 ) *ScoreboardHandler {
 	return &ScoreboardHandler{
 		scoreboardService: scoreboardService,
 		websocketService:  websocketService,
 		timerService:      timerService,
+		controlService:    controlService,
+		gameLog:           gameLog,
+		auditLog:          auditLog,
+	}
+}
+
+// OperatorHeader names the operator or device making a REST change. There is
+// no authentication yet, so it is taken as given and only used for logging.
+const OperatorHeader = "X-Operator-ID"
+
+// restActor attributes a change to the REST request that made it. The
+// optional ?reason= query parameter is recorded for audited actions.
+func restActor(c *gin.Context) models.Actor {
+	return models.Actor{
+		Source:    models.SourceREST,
+		ID:        c.GetHeader(OperatorHeader),
+		RequestID: logging.RequestID(c),
+		ClientIP:  c.ClientIP(),
+		Route:     c.FullPath(),
+		Reason:    c.Query("reason"),
+	}
+}
+
+// withReason prefers a reason given in the request body over the query parameter
+func withReason(actor models.Actor, reason string) models.Actor {
+	if reason != "" {
+		actor.Reason = reason
+	}
+	return actor
+}
+
+// wsActor attributes changes to a WebSocket connection. The operator is
+// taken from the ?operator= query parameter of the upgrade request.
+func wsActor(c *gin.Context) models.Actor {
+	return models.Actor{
+		Source:    models.SourceWebSocket,
+		ID:        c.Query("operator"),
+		RequestID: logging.RequestID(c),
+		ClientIP:  c.ClientIP(),
+		Route:     c.FullPath(),
 	}
 }
 
@@ -39,30 +89,9 @@ func NewScoreboardHandler(
 // @Success 200 {object} map[string]interface{}
 // @Router /api/state [get]
 func (h *ScoreboardHandler) GetState(c *gin.Context) {
-	state := h.scoreboardService.GetState()
-
-	// Format timer as mm:ss or ss.x
-	formattedTimer := ""
-	if state.TimerTenths >= 60*10 {
-		minutes := state.TimerTenths / (60 * 10)
-		seconds := (state.TimerTenths / 10) % 60
-		tenths := state.TimerTenths % 10
-		if minutes > 0 {
-			formattedTimer = fmt.Sprintf("%02d:%02d", minutes, seconds)
-		} else {
-			formattedTimer = fmt.Sprintf("%02d.%d", seconds, tenths)
-		}
-	} else {
-		seconds := state.TimerTenths / 10
-		tenths := state.TimerTenths % 10
-		formattedTimer = fmt.Sprintf("%02d.%d", seconds, tenths)
-	}
-
-	// Format shot clock as ss.x
-	formattedShotclock := ""
-	shotSeconds := state.ShotClockTenths / 10
-	shotTenths := state.ShotClockTenths % 10
-	formattedShotclock = fmt.Sprintf("%02d.%d", shotSeconds, shotTenths)
+	snapshot := h.scoreboardService.Snapshot()
+	state := snapshot.State
+	formatted := h.controlService.FormatClocks(state)
 
 	c.JSON(http.StatusOK, gin.H{
 		"timerTenths":        state.TimerTenths,
@@ -70,10 +99,12 @@ func (h *ScoreboardHandler) GetState(c *gin.Context) {
 		"scoreB":             state.ScoreB,
 		"shotClockTenths":    state.ShotClockTenths,
 		"isShotClockRunning": state.IsShotClockRunning,
-		"formattedTimer":     formattedTimer,
-		"formattedShotclock": formattedShotclock,
+		"formattedTimer":     formatted.FormattedTimer,
+		"formattedShotclock": formatted.FormattedShotclock,
 		"foulA":              state.FoulA,
 		"foulB":              state.FoulB,
+		"version":            snapshot.Version,
+		"gameId":             snapshot.GameID,
 	})
 }
 
@@ -109,32 +140,33 @@ func (h *ScoreboardHandler) GetState(c *gin.Context) {
 // 	c.JSON(http.StatusOK, gin.H{"message": "Timer stopped"})
 // }
 
-// ResetTimer sets timer to 10:00
+// ResetTimer sets the timer back to the rules profile's game length
 // @Summary Reset the timer
-// @Description Resets the main timer to 10:00
+// @Description Stops the main timer and resets it to the rules profile's game length
 // @Tags timer
 // @Produce json
 // @Success 200 {object} map[string]interface{}
 // @Router /api/timer/reset [post]
 func (h *ScoreboardHandler) ResetTimer(c *gin.Context) {
-	err := h.timerService.ResetTimer()
+	err := h.controlService.ResetTimer(restActor(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Timer reset to 10:00"})
+	c.JSON(http.StatusOK, gin.H{"message": "Timer reset to " + format.LogClock(h.scoreboardService.Rules().GameClockTenths)})
 }
 
 // SetTimerRequest is the request body for SetTimer
-// @Description Timer in mm:ss format
+// @Description Timer in mm:ss or mm:ss.t format, with an optional reason for the audit log
 // @example {"time": "10:00"}
 type SetTimerRequest struct {
-	Time string `json:"time"`
+	Time   string `json:"time"`
+	Reason string `json:"reason,omitempty"`
 }
 
 // SetTimer sets the timer to a specific value in mm:ss format
 // @Summary Set the timer
-// @Description Sets the main timer to a specific value in mm:ss format
+// @Description Sets the main timer to a specific value in mm:ss or mm:ss.t format, capped at the rules profile's game clock. The response has the value set.
 // @Tags timer
 // @Accept json
 // @Produce json
@@ -148,39 +180,80 @@ func (h *ScoreboardHandler) SetTimer(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	var min, sec int
-	n, err := fmt.Sscanf(req.Time, "%d:%d", &min, &sec)
-	if err != nil || n != 2 || min < 0 || sec < 0 || sec >= 60 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time format, expected mm:ss"})
+	totalTenths, err := services.ParseClockTenths(req.Time)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time format, expected mm:ss or mm:ss.t"})
 		return
 	}
-	totalTenths := (min*60 + sec) * 10
-	h.scoreboardService.SetTimerTenths(totalTenths)
-	h.websocketService.BroadcastMessage(models.WebSocketMessage{
-		Type: "timer_update",
-		Data: map[string]interface{}{
-			"timerTenths": totalTenths,
-		},
-	})
+	totalTenths = h.controlService.SetTimer(totalTenths, withReason(restActor(c), req.Reason))
 	c.JSON(http.StatusOK, gin.H{"message": "Timer set", "timerTenths": totalTenths})
 }
 
+// AdjustClocksRequest is the request body for AdjustClocks. Each clock takes
+// either a signed delta in tenths or an absolute value, not both.
+// @Description Signed deltas in tenths or absolute values for either clock, with an optional reason
+// @example {"gameClockDeltaTenths": 7, "reason": "video review"}
+type AdjustClocksRequest struct {
+	GameClockDeltaTenths *int   `json:"gameClockDeltaTenths"`
+	GameClock            string `json:"gameClock"` // mm:ss.t, mm:ss or ss.t
+	ShotClockDeltaTenths *int   `json:"shotClockDeltaTenths"`
+	ShotClock            string `json:"shotClock"` // ss.t
+	Reason               string `json:"reason,omitempty"`
+}
+
+// AdjustClocks corrects the game clock and/or shot clock
+// @Summary Adjust the clocks
+// @Description Adds signed deltas in tenths to either clock, or sets them to mm:ss.t / ss.t values. Results are clamped to the rules profile and recorded as one correction. Running clocks keep running.
+// @Tags timer
+// @Accept json
+// @Produce json
+// @Param adjustment body AdjustClocksRequest true "Clock adjustment"
+// @Success 200 {object} services.ClockAdjustResult
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/clock/adjust [post]
+func (h *ScoreboardHandler) AdjustClocks(c *gin.Context) {
+	var req AdjustClocksRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	gameClock, err := parseOptionalClock(req.GameClock)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	shotClock, err := parseOptionalClock(req.ShotClock)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	adj := services.ClockAdjustment{
+		GameClockDelta: req.GameClockDeltaTenths,
+		GameClock:      gameClock,
+		ShotClockDelta: req.ShotClockDeltaTenths,
+		ShotClock:      shotClock,
+	}
+	result, err := h.controlService.AdjustClocks(adj, withReason(restActor(c), req.Reason))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
 // IncrementScoreA increments the score for Team A by 1
 // @Summary Increment Team A score
 // @Tags score
 // @Produce json
 // @Success 200 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
 // @Router /api/scoreA/increment [post]
 func (h *ScoreboardHandler) IncrementScoreA(c *gin.Context) {
-	newScore := h.scoreboardService.IncrementScoreA()
-	h.logChange("ScoreA", newScore)
-	message := models.WebSocketMessage{
-		Type: "score_update",
-		Data: map[string]interface{}{
-			"scoreA": newScore,
-		},
-	}
-	h.websocketService.BroadcastMessage(message)
+	newScore, err := h.controlService.AdjustScore(models.TeamA, 1, restActor(c))
+	if err != nil {
+		correctionError(c, err)
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"scoreA": newScore})
 }
 
@@ -191,15 +264,11 @@ func (h *ScoreboardHandler) IncrementScoreA(c *gin.Context) {
 // @Success 200 {object} map[string]interface{}
 // @Router /api/scoreA/decrement [post]
 func (h *ScoreboardHandler) DecrementScoreA(c *gin.Context) {
-	newScore := h.scoreboardService.DecrementScoreA()
-	h.logChange("ScoreA", newScore)
-	message := models.WebSocketMessage{
-		Type: "score_update",
-		Data: map[string]interface{}{
-			"scoreA": newScore,
-		},
-	}
-	h.websocketService.BroadcastMessage(message)
+	newScore, err := h.controlService.AdjustScore(models.TeamA, -1, restActor(c))
+	if err != nil {
+		correctionError(c, err)
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"scoreA": newScore})
 }
 
@@ -208,17 +277,14 @@ func (h *ScoreboardHandler) DecrementScoreA(c *gin.Context) {
 // @Tags score
 // @Produce json
 // @Success 200 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
 // @Router /api/scoreB/increment [post]
 func (h *ScoreboardHandler) IncrementScoreB(c *gin.Context) {
-	newScore := h.scoreboardService.IncrementScoreB()
-	h.logChange("ScoreB", newScore)
-	message := models.WebSocketMessage{
-		Type: "score_update",
-		Data: map[string]interface{}{
-			"scoreB": newScore,
-		},
-	}
-	h.websocketService.BroadcastMessage(message)
+	newScore, err := h.controlService.AdjustScore(models.TeamB, 1, restActor(c))
+	if err != nil {
+		correctionError(c, err)
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"scoreB": newScore})
 }
 
@@ -229,15 +295,11 @@ func (h *ScoreboardHandler) IncrementScoreB(c *gin.Context) {
 // @Success 200 {object} map[string]interface{}
 // @Router /api/scoreB/decrement [post]
 func (h *ScoreboardHandler) DecrementScoreB(c *gin.Context) {
-	newScore := h.scoreboardService.DecrementScoreB()
-	h.logChange("ScoreB", newScore)
-	message := models.WebSocketMessage{
-		Type: "score_update",
-		Data: map[string]interface{}{
-			"scoreB": newScore,
-		},
-	}
-	h.websocketService.BroadcastMessage(message)
+	newScore, err := h.controlService.AdjustScore(models.TeamB, -1, restActor(c))
+	if err != nil {
+		correctionError(c, err)
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"scoreB": newScore})
 }
 
@@ -246,14 +308,14 @@ func (h *ScoreboardHandler) DecrementScoreB(c *gin.Context) {
 // @Tags foul
 // @Produce json
 // @Success 200 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
 // @Router /api/foulA/increment [post]
 func (h *ScoreboardHandler) IncrementFoulA(c *gin.Context) {
-	newFoul := h.scoreboardService.IncrementFoulA()
-	h.logChange("FoulA", newFoul)
-	h.websocketService.BroadcastMessage(models.WebSocketMessage{
-		Type: "foul_update",
-		Data: map[string]interface{}{"foulA": newFoul},
-	})
+	newFoul, err := h.controlService.AdjustFoul(models.TeamA, 1, restActor(c))
+	if err != nil {
+		correctionError(c, err)
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"foulA": newFoul})
 }
 
@@ -264,12 +326,11 @@ func (h *ScoreboardHandler) IncrementFoulA(c *gin.Context) {
 // @Success 200 {object} map[string]interface{}
 // @Router /api/foulA/decrement [post]
 func (h *ScoreboardHandler) DecrementFoulA(c *gin.Context) {
-	newFoul := h.scoreboardService.DecrementFoulA()
-	h.logChange("FoulA", newFoul)
-	h.websocketService.BroadcastMessage(models.WebSocketMessage{
-		Type: "foul_update",
-		Data: map[string]interface{}{"foulA": newFoul},
-	})
+	newFoul, err := h.controlService.AdjustFoul(models.TeamA, -1, restActor(c))
+	if err != nil {
+		correctionError(c, err)
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"foulA": newFoul})
 }
 
@@ -278,14 +339,14 @@ func (h *ScoreboardHandler) DecrementFoulA(c *gin.Context) {
 // @Tags foul
 // @Produce json
 // @Success 200 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
 // @Router /api/foulB/increment [post]
 func (h *ScoreboardHandler) IncrementFoulB(c *gin.Context) {
-	newFoul := h.scoreboardService.IncrementFoulB()
-	h.logChange("FoulB", newFoul)
-	h.websocketService.BroadcastMessage(models.WebSocketMessage{
-		Type: "foul_update",
-		Data: map[string]interface{}{"foulB": newFoul},
-	})
+	newFoul, err := h.controlService.AdjustFoul(models.TeamB, 1, restActor(c))
+	if err != nil {
+		correctionError(c, err)
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"foulB": newFoul})
 }
 
@@ -296,55 +357,97 @@ func (h *ScoreboardHandler) IncrementFoulB(c *gin.Context) {
 // @Success 200 {object} map[string]interface{}
 // @Router /api/foulB/decrement [post]
 func (h *ScoreboardHandler) DecrementFoulB(c *gin.Context) {
-	newFoul := h.scoreboardService.DecrementFoulB()
-	h.logChange("FoulB", newFoul)
-	h.websocketService.BroadcastMessage(models.WebSocketMessage{
-		Type: "foul_update",
-		Data: map[string]interface{}{"foulB": newFoul},
-	})
+	newFoul, err := h.controlService.AdjustFoul(models.TeamB, -1, restActor(c))
+	if err != nil {
+		correctionError(c, err)
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"foulB": newFoul})
 }
 
-var (
-	changeLog   []string
-	logFilePath = "game.log"
-)
+// SetScoresRequest is the request body for SetScores. Omitted teams keep their score.
+// @Description Corrected scores and an optional reason
+// @example {"scoreA": 12, "scoreB": 9, "reason": "missed basket at 04:10"}
+type SetScoresRequest struct {
+	ScoreA *uint  `json:"scoreA"`
+	ScoreB *uint  `json:"scoreB"`
+	Reason string `json:"reason,omitempty"`
+}
 
-func appendToLogFile(entry string) {
-	f, err := os.OpenFile(logFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+// SetScores corrects one or both scores at once
+// @Summary Correct scores
+// @Description Sets one or both scores, validated against the rules profile (e.g. the 3x3 score cap). Broadcasts a single state_sync and logs a single correction.
+// @Tags score
+// @Accept json
+// @Produce json
+// @Param scores body SetScoresRequest true "Corrected scores"
+// @Success 200 {object} models.ScoreboardState
+// @Failure 400 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /api/score [put]
+func (h *ScoreboardHandler) SetScores(c *gin.Context) {
+	var req SetScoresRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.ScoreA == nil && req.ScoreB == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "scoreA or scoreB is required"})
+		return
+	}
+	state, err := h.controlService.CorrectScores(req.ScoreA, req.ScoreB, withReason(restActor(c), req.Reason))
 	if err != nil {
-		log.Printf("Failed to open log file: %v", err)
+		correctionError(c, err)
 		return
 	}
-	defer f.Close()
-	f.WriteString(entry + "\n")
+	c.JSON(http.StatusOK, state)
 }
 
-func clearLogFile() {
-	os.WriteFile(logFilePath, []byte{}, 0644)
+// SetFoulsRequest is the request body for SetFouls. Omitted teams keep their count.
+// @Description Corrected foul counts and an optional reason
+// @example {"foulA": 4, "foulB": 6}
+type SetFoulsRequest struct {
+	FoulA  *uint  `json:"foulA"`
+	FoulB  *uint  `json:"foulB"`
+	Reason string `json:"reason,omitempty"`
 }
 
-func (h *ScoreboardHandler) logChange(field string, value interface{}) {
-	state := h.scoreboardService.GetState()
-	formattedTimer := ""
-	if state.TimerTenths >= 60*10 {
-		minutes := state.TimerTenths / (60 * 10)
-		seconds := (state.TimerTenths / 10) % 60
-		tenths := state.TimerTenths % 10
-		if minutes > 0 {
-			formattedTimer = fmt.Sprintf("%02d:%02d", minutes, seconds)
-		} else {
-			formattedTimer = fmt.Sprintf("%02d.%d", seconds, tenths)
-		}
-	} else {
-		seconds := state.TimerTenths / 10
-		tenths := state.TimerTenths % 10
-		formattedTimer = fmt.Sprintf("%02d.%d", seconds, tenths)
+// SetFouls corrects one or both foul counts at once
+// @Summary Correct fouls
+// @Description Sets one or both foul counts, validated against the rules profile. Broadcasts a single state_sync and logs a single correction.
+// @Tags fouls
+// @Accept json
+// @Produce json
+// @Param fouls body SetFoulsRequest true "Corrected foul counts"
+// @Success 200 {object} models.ScoreboardState
+// @Failure 400 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /api/fouls [put]
+func (h *ScoreboardHandler) SetFouls(c *gin.Context) {
+	var req SetFoulsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-	logEntry := fmt.Sprintf("%s | %s changed to %v", formattedTimer, field, value)
-	changeLog = append(changeLog, logEntry)
-	appendToLogFile(logEntry)
-	log.Println(logEntry)
+	if req.FoulA == nil && req.FoulB == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "foulA or foulB is required"})
+		return
+	}
+	state, err := h.controlService.CorrectFouls(req.FoulA, req.FoulB, withReason(restActor(c), req.Reason))
+	if err != nil {
+		correctionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, state)
+}
+
+// correctionError answers 422 for values the rules profile does not allow
+func correctionError(c *gin.Context, err error) {
+	if errors.Is(err, rules.ErrLimit) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 }
 
 // @Summary Get change log
@@ -353,156 +456,103 @@ func (h *ScoreboardHandler) logChange(field string, value interface{}) {
 // @Success 200 {object} map[string]interface{}
 // @Router /api/log [get]
 func (h *ScoreboardHandler) GetLog(c *gin.Context) {
-	data, err := os.ReadFile(logFilePath)
+	lines, err := h.gameLog.Lines()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read log file"})
 		return
 	}
-	lines := []string{}
-	for _, line := range splitLines(string(data)) {
-		if line != "" {
-			lines = append(lines, line)
-		}
-	}
 	c.JSON(http.StatusOK, gin.H{"log": lines})
 }
 
-func splitLines(s string) []string {
-	var lines []string
-	start := 0
-	for i := 0; i < len(s); i++ {
-		if s[i] == '\n' {
-			lines = append(lines, s[start:i])
-			start = i + 1
+// GetAudit returns the audit trail of administrative and corrective actions
+// @Summary Get audit log
+// @Description Returns audit entries oldest first, each with actor, time, reason and the full state before and after. Filter with action, after (sequence number) and limit (newest N).
+// @Tags log
+// @Produce json
+// @Param action query string false "Only this action, e.g. game_reset"
+// @Param after query int false "Only entries with a higher sequence number"
+// @Param limit query int false "At most this many of the newest entries"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/audit [get]
+func (h *ScoreboardHandler) GetAudit(c *gin.Context) {
+	var q services.AuditQuery
+	q.Action = c.Query("action")
+	if v := c.Query("after"); v != "" {
+		after, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || after < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "after must be a non-negative sequence number"})
+			return
+		}
+		q.AfterSeq = after
+	}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a non-negative number"})
+			return
 		}
+		q.Limit = limit
 	}
-	if start < len(s) {
-		lines = append(lines, s[start:])
+	entries, err := h.auditLog.Entries(q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read audit log"})
+		return
 	}
-	return lines
+	c.JSON(http.StatusOK, gin.H{"entries": entries})
 }
 
 // HandleWebSocket handles WebSocket connections
 func (h *ScoreboardHandler) HandleWebSocket(c *gin.Context) {
 	conn, err := h.websocketService.UpgradeConnection(c.Writer, c.Request)
 	if err != nil {
-		log.Printf("Failed to upgrade connection: %v", err)
+		slog.Warn("Failed to upgrade connection", "requestId", logging.RequestID(c), "error", err)
 		return
 	}
-	defer conn.Close()
-
-	// Create client
-	clientID := fmt.Sprintf("%s_%d", c.ClientIP(), conn.RemoteAddr())
-	client := &models.Client{
-		ID:   clientID,
-		Conn: conn,
-		Send: make(chan models.WebSocketMessage, 256),
-	}
-
-	// Register client
-	h.websocketService.RegisterClient(client)
-	defer h.websocketService.UnregisterClient(client)
-
-	// Send initial state
-	initialState := h.scoreboardService.GetState()
-	initialMessage := models.WebSocketMessage{
-		Type: "state_sync",
-		Data: initialState,
-	}
-	client.Send <- initialMessage
-
-	// Start goroutines for handling WebSocket communication
-	go h.writePump(conn, client)
-	h.readPump(conn, client)
-}
 
-// readPump handles reading messages from WebSocket
-func (h *ScoreboardHandler) readPump(conn *websocket.Conn, client *models.Client) {
-	defer func() {
-		h.websocketService.UnregisterClient(client)
-		conn.Close()
-	}()
+	encoding := services.ClientEncoding(conn, c.Request)
+	client := h.websocketService.NewClient(conn.RemoteAddr().String(), encoding)
 
-	for {
-		var message models.WebSocketMessage
-		err := conn.ReadJSON(&message)
-		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Printf("WebSocket error: %v", err)
-			}
-			break
-		}
-
-		// Handle different message types
-		h.handleWebSocketMessage(message)
-	}
-}
-
-// writePump handles writing messages to WebSocket
-func (h *ScoreboardHandler) writePump(conn *websocket.Conn, client *models.Client) {
-	defer conn.Close()
-
-	for {
-		select {
-		case message, ok := <-client.Send:
-			if !ok {
-				conn.WriteMessage(websocket.CloseMessage, []byte{})
-				return
-			}
+	// Queue the initial state before registering so it is always the first message
+	client.Send <- models.NewMessage(h.controlService.StateSync())
 
-			if err := conn.WriteJSON(message); err != nil {
-				log.Printf("WebSocket write error: %v", err)
-				return
-			}
-		}
-	}
+	actor := wsActor(c)
+	h.websocketService.ServeClient(conn, client, func(message models.InboundMessage) {
+		h.handleWebSocketMessage(message, actor)
+	})
 }
 
 // handleWebSocketMessage processes incoming WebSocket messages
-func (h *ScoreboardHandler) handleWebSocketMessage(message models.WebSocketMessage) {
+func (h *ScoreboardHandler) handleWebSocketMessage(message models.InboundMessage, actor models.Actor) {
+	actor.Route += "#" + message.Type
 	switch message.Type {
 	case "timer_control":
-		if data, ok := message.Data.(map[string]interface{}); ok {
-			if action, exists := data["action"]; exists {
-				switch action {
-				case "start":
-					h.timerService.StartTimer()
-				case "stop":
-					h.timerService.StopTimer()
-				}
+		var data models.TimerControlData
+		if err := json.Unmarshal(message.Data, &data); err != nil {
+			slog.Warn("Invalid timer_control payload", "actor", actor, "error", err)
+			return
+		}
+		switch data.Action {
+		case "start":
+			if err := h.controlService.StartTimer(actor); err != nil {
+				slog.Warn("Cannot start timer", "actor", actor, "error", err)
 			}
+		case "stop":
+			h.controlService.StopTimer(actor)
 		}
 
 	case "score_update":
-		if data, ok := message.Data.(map[string]interface{}); ok {
-			if scoreInterface, exists := data["score"]; exists {
-				// Handle different number types from JSON
-				var score uint
-				switch v := scoreInterface.(type) {
-				case float64:
-					score = uint(v)
-				case int:
-					score = uint(v)
-				case uint:
-					score = v
-				default:
-					log.Printf("Invalid score type: %T", v)
-					return
-				}
-
-				// Broadcast score update
-				broadcastMessage := models.WebSocketMessage{
-					Type: "score_update",
-					Data: map[string]interface{}{
-						"score": score,
-					},
-				}
-				h.websocketService.BroadcastMessage(broadcastMessage)
-			}
+		var data models.ScoreSetData
+		if err := json.Unmarshal(message.Data, &data); err != nil {
+			slog.Warn("Invalid score_update payload", "actor", actor, "error", err)
+			return
+		}
+		if err := h.controlService.SetScore(data.Team, data.Score, withReason(actor, data.Reason)); err != nil {
+			slog.Warn("Rejected score_update", "team", data.Team, "score", data.Score, "actor", actor, "error", err)
 		}
 
 	default:
-		log.Printf("Unknown message type: %s", message.Type)
+		slog.Warn("Unknown message type", "type", message.Type, "actor", actor)
 	}
 }
 
@@ -513,58 +563,23 @@ func (h *ScoreboardHandler) handleWebSocketMessage(message models.WebSocketMessa
 // @Success 200 {object} map[string]interface{}
 // @Router /api/shotclock/start [post]
 func (h *ScoreboardHandler) StartShotClock(c *gin.Context) {
-	if h.scoreboardService.IsShotClockRunning() {
-		c.JSON(http.StatusOK, gin.H{"error": "Shot clock is already running"})
-		return
-	}
-	if h.scoreboardService.GetShotClockTenths() == 0 {
-		h.websocketService.BroadcastMessage(models.WebSocketMessage{
-			Type: "shotclock_update",
-			Data: map[string]interface{}{
-				"isShotClockRunning": false,
-				"shotClockTenths":    h.scoreboardService.GetShotClockTenths(),
-				"timerTenths":        h.scoreboardService.GetTimerTenths(),
-			},
-		})
-		c.JSON(http.StatusOK, gin.H{"error": "Cannot start shot clock when value is 0"})
-		return
-	}
-	h.scoreboardService.SetShotClockRunning(true)
-	h.timerService.StartTimer() // Start main timer as well
-	// Start shot clock goroutine
-	go func() {
-		ticker := time.NewTicker(100 * time.Millisecond)
-		defer ticker.Stop()
-		lastValue := h.scoreboardService.GetShotClockTenths()
-		for h.scoreboardService.IsShotClockRunning() {
-			<-ticker.C
-			remaining := h.scoreboardService.DecrementShotClockTenths()
-			if remaining != lastValue {
-				lastValue = remaining
-				if remaining == 0 {
-					h.StopShotClock(c)
-					return
-				}
-				h.websocketService.BroadcastMessage(models.WebSocketMessage{
-					Type: "shotclock_update",
-					Data: map[string]interface{}{
-						"isShotClockRunning": true,
-						"shotClockTenths":    h.scoreboardService.GetShotClockTenths(),
-						"timerTenths":        h.scoreboardService.GetTimerTenths(),
-					},
-				})
-			}
-		}
-	}()
+	if err := h.controlService.StartClocks(restActor(c)); err != nil {
+		c.JSON(http.StatusOK, gin.H{"error": shotClockError(err)})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"message": "Shot clock started"})
-	h.websocketService.BroadcastMessage(models.WebSocketMessage{
-		Type: "shotclock_update",
-		Data: map[string]interface{}{
-			"isShotClockRunning": true,
-			"shotClockTenths":    h.scoreboardService.GetShotClockTenths(),
-			"timerTenths":        h.scoreboardService.GetTimerTenths(),
-		},
-	})
+}
+
+// shotClockError keeps the messages the control panel already shows
+func shotClockError(err error) string {
+	switch err {
+	case services.ErrShotClockRunning:
+		return "Shot clock is already running"
+	case services.ErrShotClockZero:
+		return "Cannot start shot clock when value is 0"
+	default:
+		return err.Error()
+	}
 }
 
 // @Summary Stop the shot clock
@@ -573,54 +588,33 @@ func (h *ScoreboardHandler) StartShotClock(c *gin.Context) {
 // @Success 200 {object} map[string]interface{}
 // @Router /api/shotclock/stop [post]
 func (h *ScoreboardHandler) StopShotClock(c *gin.Context) {
-	h.scoreboardService.SetShotClockRunning(false)
-	h.timerService.StopTimer() // Stop main timer as well
-	h.websocketService.BroadcastMessage(models.WebSocketMessage{
-		Type: "shotclock_update",
-		Data: map[string]interface{}{
-			"isShotClockRunning": false,
-			"shotClockTenths":    h.scoreboardService.GetShotClockTenths(),
-			"timerTenths":        h.scoreboardService.GetTimerTenths(),
-		},
-	})
+	h.controlService.StopClocks(restActor(c))
 	c.JSON(http.StatusOK, gin.H{"message": "Shot clock stopped"})
 }
 
-// ResetShotClock resets the shot clock to 12.0
+// ResetShotClock resets the shot clock to the rules profile's value
 // @Summary Reset the shot clock
+// @Description Resets the shot clock to the rules profile's value. A reset from zero restarts the clocks.
 // @Tags shotclock
 // @Produce json
 // @Success 200 {object} map[string]interface{}
 // @Router /api/shotclock/reset [post]
 func (h *ScoreboardHandler) ResetShotClock(c *gin.Context) {
-	wasZero := h.scoreboardService.GetShotClockTenths() == 0
-	h.scoreboardService.ResetShotClock()
-	if wasZero {
-		h.StartShotClock(c)
-	} else {
-		h.websocketService.BroadcastMessage(models.WebSocketMessage{
-			Type: "shotclock_update",
-			Data: map[string]interface{}{
-				"shotClockTenths":    12 * 10,
-				"isShotClockRunning": h.scoreboardService.IsShotClockRunning(),
-				"timerTenths":        h.scoreboardService.GetTimerTenths(),
-			},
-		})
-	}
-
-	c.JSON(http.StatusOK, gin.H{"message": "Shot clock reset to 12.0"})
+	h.controlService.ResetShotClock(restActor(c))
+	c.JSON(http.StatusOK, gin.H{"message": "Shot clock reset to " + format.PreciseShotClock(h.scoreboardService.Rules().ShotClockTenths)})
 }
 
 // SetShotClockRequest is the request body for SetTimer
 // @Description Timer in ss.x format
 // @example {"time": "11.0"}
 type SetShotClockRequest struct {
-	Time string `json:"time"`
+	Time   string `json:"time"`
+	Reason string `json:"reason,omitempty"`
 }
 
 // SetShotClock sets the shot clock to a specific value in ss.x format
 // @Summary Set the shot clock
-// @Description Sets the shot clock to a specific value in ss.x format
+// @Description Sets the shot clock to a specific value in ss.x format, capped at the rules profile's shot clock. The response has the value set.
 // @Tags shotclock
 // @Accept json
 // @Produce json
@@ -633,41 +627,334 @@ func (h *ScoreboardHandler) SetShotClock(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	var sec, tenths int
-	n, err := fmt.Sscanf(req.Time, "%d.%d", &sec, &tenths)
-	if err != nil || n < 1 || sec < 0 || sec > 99 || tenths < 0 || tenths > 9 {
+	totalTenths, err := services.ParseClockTenths(req.Time)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time format, expected ss.x"})
 		return
 	}
-	totalTenths := sec*10 + tenths
-	h.scoreboardService.SetShotClockTenths(totalTenths)
-	h.websocketService.BroadcastMessage(models.WebSocketMessage{
-		Type: "shotclock_update",
-		Data: map[string]interface{}{
-			"shotClockTenths":    totalTenths,
-			"isShotClockRunning": h.scoreboardService.IsShotClockRunning(),
-			"timerTenths":        h.scoreboardService.GetTimerTenths(),
-		},
-	})
+	totalTenths = h.controlService.SetShotClock(totalTenths, withReason(restActor(c), req.Reason))
 	c.JSON(http.StatusOK, gin.H{"message": "Shot clock set", "shotClockTenths": totalTenths})
 }
 
-// ResetGame resets everything to default
+// parseOptionalClock parses a clock value from a request, returning nil when it is empty
+func parseOptionalClock(value string) (*int, error) {
+	if value == "" {
+		return nil, nil
+	}
+	tenths, err := services.ParseClockTenths(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", err, value)
+	}
+	return &tenths, nil
+}
+
+// ResetGameRequest is the optional request body for ResetGame
+// @Description Confirmation token from the first call and an optional reason for the audit log
+// @example {"token": "9f2c...", "reason": "start of game 2"}
+type ResetGameRequest struct {
+	Token  string `json:"token"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// ResetGame resets everything to default in two phases
 // @Summary Reset the game
-// @Description Resets timer, shot clock, and both team scores to default values
+// @Description Without a token, returns 202 with a confirmation token and a summary of what will be lost. With the token, resets timer, shot clock, scores, fouls and the game log, archiving the game first when enabled.
 // @Tags game
+// @Accept json
 // @Produce json
+// @Param reset body ResetGameRequest false "Confirmation token and reason"
 // @Success 200 {object} map[string]interface{}
+// @Success 202 {object} services.ResetConfirmation
+// @Failure 409 {object} map[string]interface{}
 // @Router /api/game/reset [post]
 func (h *ScoreboardHandler) ResetGame(c *gin.Context) {
-	h.timerService.StopTimer()
-	h.scoreboardService.ResetAll()
-	clearLogFile()
-	h.websocketService.BroadcastMessage(models.WebSocketMessage{
-		Type: "game_reset",
-		Data: h.scoreboardService.GetState(),
-	})
-	c.JSON(http.StatusOK, gin.H{"message": "Game reset to default"})
+	var req ResetGameRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	actor := withReason(restActor(c), req.Reason)
+	if req.Token == "" {
+		confirmation, err := h.controlService.PrepareReset(actor)
+		switch {
+		case errors.Is(err, services.ErrReviewActive):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusAccepted, confirmation)
+		return
+	}
+	archiveID, err := h.controlService.ResetGame(req.Token, actor)
+	switch {
+	case errors.Is(err, services.ErrResetTokenInvalid), errors.Is(err, services.ErrReviewActive):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Game reset to default", "archiveId": archiveID})
+}
+
+// ListArchives returns the games archived by resets
+// @Summary List archived games
+// @Description Games saved just before a reset, newest first
+// @Tags game
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/game/archives [get]
+func (h *ScoreboardHandler) ListArchives(c *gin.Context) {
+	archives, err := h.controlService.Archives()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read archives"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"archives": archives})
+}
+
+// RestoreArchive reverts a reset by restoring an archived game
+// @Summary Restore an archived game
+// @Description Replaces the current state and game log with an archived game. Clocks come back stopped. Accepts ?reason= for the audit log.
+// @Tags game
+// @Produce json
+// @Param id path string true "Archive ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/game/archives/{id}/restore [post]
+func (h *ScoreboardHandler) RestoreArchive(c *gin.Context) {
+	state, err := h.controlService.RestoreArchive(c.Param("id"), restActor(c))
+	switch {
+	case errors.Is(err, services.ErrArchiveNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrReviewActive):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Game restored", "state": state})
+}
+
+// ListGames returns the IDs of games with an event log
+// @Summary List games
+// @Description IDs of every game with an event log, newest first, and the ID of the current game
+// @Tags games
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/games [get]
+func (h *ScoreboardHandler) ListGames(c *gin.Context) {
+	games, err := h.scoreboardService.Games()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list games"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"current": h.scoreboardService.GameID(), "games": games})
+}
+
+// GetGameState replays a game's event log
+// @Summary Replay a game
+// @Description Rebuilds a game's state from its events. Without at, returns the final state; with at (game clock in tenths), the state when the game clock showed that time. Use "current" for the game in progress.
+// @Tags games
+// @Produce json
+// @Param id path string true "Game ID or current"
+// @Param at query int false "Game clock in tenths"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/games/{id}/state [get]
+func (h *ScoreboardHandler) GetGameState(c *gin.Context) {
+	id := c.Param("id")
+	if id == "current" {
+		id = h.scoreboardService.GameID()
+	}
+	events, err := h.scoreboardService.GameEvents(id)
+	switch {
+	case errors.Is(err, services.ErrGameNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrCorruptLog):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read game events"})
+		return
+	}
+	at := c.Query("at")
+	if at == "" {
+		c.JSON(http.StatusOK, gin.H{"gameId": id, "events": len(events), "state": services.Replay(events)})
+		return
+	}
+	tenths, err := strconv.Atoi(at)
+	if err != nil || tenths < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "at must be a game clock in tenths"})
+		return
+	}
+	state, err := services.StateAt(events, tenths)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"gameId": id, "at": tenths, "state": state})
+}
+
+// GetReview returns the instant replay review in progress
+// @Summary Get the review in progress
+// @Description Returns {"status": "review", "review": {...}} during a review, otherwise {"status": "live"}
+// @Tags review
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/review [get]
+func (h *ScoreboardHandler) GetReview(c *gin.Context) {
+	review := h.controlService.Review()
+	if review == nil {
+		c.JSON(http.StatusOK, gin.H{"status": models.ReviewStatusLive})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": models.ReviewStatusReview, "review": review})
+}
+
+// StartReviewRequest is the request body for StartReview
+// @Description Why the play is being reviewed
+// @example {"reason": "last shot at the buzzer"}
+type StartReviewRequest struct {
+	Reason string `json:"reason"`
+}
+
+// StartReview freezes the clocks for an instant replay review
+// @Summary Start a review
+// @Description Stops both clocks and broadcasts review_update with status "review". The clocks cannot be started until the review ends.
+// @Tags review
+// @Accept json
+// @Produce json
+// @Param review body StartReviewRequest true "Review reason"
+// @Success 200 {object} services.Review
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/review/start [post]
+func (h *ScoreboardHandler) StartReview(c *gin.Context) {
+	var req StartReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	review, err := h.controlService.StartReview(req.Reason, restActor(c))
+	if err != nil {
+		c.JSON(reviewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, review)
+}
+
+// GetReviewEvents lists the game's events with the state after each one
+// @Summary List rewind points
+// @Description Replays the current game's event log and returns each event, except clock ticks, with the clocks, scores and fouls it left the game in. The event number is what /api/review/rewind takes.
+// @Tags review
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/review/events [get]
+func (h *ScoreboardHandler) GetReviewEvents(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"events": h.controlService.ReviewEvents()})
+}
+
+// RewindReviewRequest is the request body for RewindReview: a game event, or
+// a game clock time to rewind to
+// @Description Game event (seq) or game clock time (mm:ss.t) to rewind to, with an optional reason
+// @example {"event": 12, "reason": "basket came after the buzzer"}
+type RewindReviewRequest struct {
+	Event     uint64 `json:"event"`
+	GameClock string `json:"gameClock"` // mm:ss.t, mm:ss or ss.t
+	Reason    string `json:"reason,omitempty"`
+}
+
+// RewindReview reverts the clocks and score to an earlier moment of the game
+// @Summary Rewind to a game event or game clock time
+// @Description During a review, sets the clocks, scores and fouls to the state just after the given event from /api/review/events, or to the moment the game clock showed gameClock. Both are replayed from the game's event log, exact to the tenth. Recorded as a correction.
+// @Tags review
+// @Accept json
+// @Produce json
+// @Param rewind body RewindReviewRequest true "Event seq or game clock time"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/review/rewind [post]
+func (h *ScoreboardHandler) RewindReview(c *gin.Context) {
+	var req RewindReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if (req.Event == 0) == (req.GameClock == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "give either event or gameClock"})
+		return
+	}
+	gameClock, err := parseOptionalClock(req.GameClock)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	state, err := h.controlService.RewindReview(services.Rewind{Event: req.Event, GameClock: gameClock}, withReason(restActor(c), req.Reason))
+	if err != nil {
+		c.JSON(reviewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Rewound", "state": state})
+}
+
+// EndReviewRequest is the request body for EndReview
+// @Description Review outcome, and whether to restart the clocks straight away
+// @example {"outcome": "basket counts", "resumeClocks": false}
+type EndReviewRequest struct {
+	Outcome      string `json:"outcome"`
+	ResumeClocks bool   `json:"resumeClocks"`
+}
+
+// EndReview records the outcome and returns to live play
+// @Summary End a review
+// @Description Records the outcome and broadcasts review_update with status "live". With resumeClocks the clocks restart; otherwise they stay stopped.
+// @Tags review
+// @Accept json
+// @Produce json
+// @Param review body EndReviewRequest true "Review outcome"
+// @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/review/end [post]
+func (h *ScoreboardHandler) EndReview(c *gin.Context) {
+	var req EndReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	review, err := h.controlService.EndReview(req.Outcome, req.ResumeClocks, restActor(c))
+	switch {
+	case errors.Is(err, services.ErrReviewOutcome), errors.Is(err, services.ErrNoReview):
+		c.JSON(reviewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		// The review has ended; only restarting the clocks failed
+		c.JSON(http.StatusOK, gin.H{"message": "Review ended", "review": review, "outcome": req.Outcome, "error": shotClockError(err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Review ended", "review": review, "outcome": req.Outcome})
+}
+
+// reviewErrorStatus maps review errors to HTTP status codes
+func reviewErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrReviewReason), errors.Is(err, services.ErrReviewOutcome):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrReviewActive), errors.Is(err, services.ErrNoReview):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrEventNotFound), errors.Is(err, services.ErrClockNotReached):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
 }
 
 // TriggerStateSync sends a state_sync message to all WebSocket clients
@@ -677,10 +964,17 @@ func (h *ScoreboardHandler) ResetGame(c *gin.Context) {
 // @Success 200 {object} map[string]interface{}
 // @Router /api/state/sync [post]
 func (h *ScoreboardHandler) TriggerStateSync(c *gin.Context) {
-	state := h.scoreboardService.GetState()
-	h.websocketService.BroadcastMessage(models.WebSocketMessage{
-		Type: "state_sync",
-		Data: state,
-	})
+	h.controlService.SyncState()
 	c.JSON(http.StatusOK, gin.H{"message": "state_sync broadcasted"})
 }
+
+// GetSchema returns the JSON Schema for every WebSocket message
+// @Summary Get WebSocket message schema
+// @Description Returns a JSON Schema (draft 2020-12) describing the versioned envelope and every typed payload
+// @Tags state
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/schema [get]
+func (h *ScoreboardHandler) GetSchema(c *gin.Context) {
+	c.JSON(http.StatusOK, schema.Protocol("/api/schema"))
+}
@@ -0,0 +1,119 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"scoreboard-backend/internal/clock"
+	"scoreboard-backend/internal/config"
+	"scoreboard-backend/internal/server"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+func newServer(t *testing.T) *server.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	cfg := config.Default()
+	cfg.DataDir = t.TempDir()
+	srv, err := server.New(cfg, clock.NewFake(time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		srv.SaveState()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		srv.WebSocket.Shutdown(ctx)
+	})
+	return srv
+}
+
+// call sends a request to the router and decodes the JSON answer
+func call(t *testing.T, srv *server.Server, method, path, body string) (int, map[string]interface{}) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	srv.Router.ServeHTTP(rec, req)
+	var answer map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &answer)
+	return rec.Code, answer
+}
+
+func TestIncrementPastTheCap(t *testing.T) {
+	srv := newServer(t)
+	if code, _ := call(t, srv, "PUT", "/api/score", `{"scoreA": 22}`); code != http.StatusOK {
+		t.Fatalf("PUT /api/score: %d", code)
+	}
+	code, answer := call(t, srv, "POST", "/api/scoreA/increment", "")
+	if code != http.StatusUnprocessableEntity {
+		t.Errorf("increment at 22: %d %v, want 422", code, answer)
+	}
+	if got := srv.Scoreboard.GetState().ScoreA; got != 22 {
+		t.Errorf("score %d after a refused increment", got)
+	}
+	if code, answer := call(t, srv, "POST", "/api/scoreA/decrement", ""); code != http.StatusOK || answer["scoreA"] != 21.0 {
+		t.Errorf("decrement: %d %v", code, answer)
+	}
+}
+
+func TestClockSetsCapped(t *testing.T) {
+	srv := newServer(t)
+	tests := []struct {
+		path, body, field string
+		code              int
+		want              float64
+	}{
+		{"/api/shotclock/set", `{"time": "30.0"}`, "shotClockTenths", 200, 120},
+		{"/api/shotclock/set", `{"time": "12"}`, "shotClockTenths", 200, 120},
+		{"/api/shotclock/set", `{"time": "7.5"}`, "shotClockTenths", 200, 75},
+		{"/api/shotclock/set", `{"time": "7.5x"}`, "", 400, 0},
+		{"/api/timer/set", `{"time": "15:00"}`, "timerTenths", 200, 6000},
+		{"/api/timer/set", `{"time": "04:30.5"}`, "timerTenths", 200, 2705},
+	}
+	for _, tt := range tests {
+		code, answer := call(t, srv, "POST", tt.path, tt.body)
+		if code != tt.code || (tt.field != "" && answer[tt.field] != tt.want) {
+			t.Errorf("POST %s %s: %d %v; want %d with %s %v", tt.path, tt.body, code, answer, tt.code, tt.field, tt.want)
+		}
+	}
+	if state := srv.Scoreboard.GetState(); state.ShotClockTenths != 75 || state.TimerTenths != 2705 {
+		t.Errorf("clocks %d and %d, want 75 and 2705", state.ShotClockTenths, state.TimerTenths)
+	}
+}
+
+func TestWebSocketScoreUpdatePastTheCap(t *testing.T) {
+	srv := newServer(t)
+	ts := httptest.NewServer(srv.Router)
+	defer ts.Close()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/ws/state", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	for _, message := range []string{
+		`{"type": "score_update", "data": {"team": "A", "score": 99}}`,
+		`{"type": "score_update", "data": {"team": "B", "score": 7}}`,
+	} {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(message)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for srv.Scoreboard.GetState().ScoreB != 7 {
+		if time.Now().After(deadline) {
+			t.Fatal("the second score_update was not applied")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := srv.Scoreboard.GetState().ScoreA; got != 0 {
+		t.Errorf("score_update A=99 was applied: score %d", got)
+	}
+}
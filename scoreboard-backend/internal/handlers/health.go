@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	hubHeartbeatTimeout = 2 * time.Second
+	clockTickMaxAge     = time.Second // Ten missed 100ms ticks
+)
+
+// HealthHandler serves liveness and readiness checks for docker and nginx
+type HealthHandler struct {
+	websocketService Hub
+	timerService     GameClock
+	shotClock        ShotClock
+	gameLog          GameLog
+	auditLog         AuditLog
+}
+
+func NewHealthHandler(
+	websocketService Hub,
+	timerService GameClock,
+	shotClock ShotClock,
+	gameLog GameLog,
+	auditLog AuditLog,
+) *HealthHandler {
+	return &HealthHandler{
+		websocketService: websocketService,
+		timerService:     timerService,
+		shotClock:        shotClock,
+		gameLog:          gameLog,
+		auditLog:         auditLog,
+	}
+}
+
+// CheckResult is the outcome of one health check
+type CheckResult struct {
+	Status     string `json:"status"` // "ok" or "fail"
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// HealthResponse is the body of /healthz/live and /healthz/ready
+type HealthResponse struct {
+	Status string                 `json:"status"` // "ok" when every check passed
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// Live reports whether the process should be restarted
+// @Summary Liveness check
+// @Description Checks that the WebSocket hub loop is processing. Returns 503 with details when it is wedged.
+// @Tags health
+// @Produce json
+// @Success 200 {object} HealthResponse
+// @Failure 503 {object} HealthResponse
+// @Router /healthz/live [get]
+func (h *HealthHandler) Live(c *gin.Context) {
+	h.respond(c, map[string]func() error{
+		"websocketHub": h.pingHub,
+	})
+}
+
+// Ready reports whether the backend can serve a game
+// @Summary Readiness check
+// @Description Checks the WebSocket hub loop, that the game and audit logs are writable, and that running clocks are ticking
+// @Tags health
+// @Produce json
+// @Success 200 {object} HealthResponse
+// @Failure 503 {object} HealthResponse
+// @Router /healthz/ready [get]
+func (h *HealthHandler) Ready(c *gin.Context) {
+	h.respond(c, map[string]func() error{
+		"websocketHub": h.pingHub,
+		"gameLog":      h.gameLog.CheckWritable,
+		"auditLog":     h.auditLog.CheckWritable,
+		"gameClock": func() error {
+			return h.timerService.CheckAlive(clockTickMaxAge)
+		},
+		"shotClock": func() error {
+			return h.shotClock.CheckAlive(clockTickMaxAge)
+		},
+	})
+}
+
+func (h *HealthHandler) pingHub() error {
+	return h.websocketService.Ping(hubHeartbeatTimeout)
+}
+
+func (h *HealthHandler) respond(c *gin.Context, checks map[string]func() error) {
+	response := HealthResponse{Status: "ok", Checks: map[string]CheckResult{}}
+	for name, check := range checks {
+		start := time.Now()
+		err := check()
+		result := CheckResult{Status: "ok", DurationMs: time.Since(start).Milliseconds()}
+		if err != nil {
+			result.Status = "fail"
+			result.Error = err.Error()
+			response.Status = "fail"
+		}
+		response.Checks[name] = result
+	}
+	status := http.StatusOK
+	if response.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, response)
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+	"scoreboard-backend/internal/overlay"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OverlayHandler serves broadcast graphics: the overlay page and its data feed
+type OverlayHandler struct {
+	scoreboardService Scoreboard
+	controlService    Control
+	playbackService   Playback
+}
+
+func NewOverlayHandler(scoreboardService Scoreboard, controlService Control, playbackService Playback) *OverlayHandler {
+	return &OverlayHandler{
+		scoreboardService: scoreboardService,
+		controlService:    controlService,
+		playbackService:   playbackService,
+	}
+}
+
+// GetOverlay serves the transparent overlay page for OBS browser sources
+// @Summary Broadcast overlay
+// @Description Transparent HTML overlay driven by /ws/state. layout is bug, full or lower-third; teamA, teamB, colorA and colorB (hex without '#') label the teams.
+// @Tags overlay
+// @Produce html
+// @Param layout query string false "bug, full or lower-third" default(bug)
+// @Param teamA query string false "Team A name"
+// @Param teamB query string false "Team B name"
+// @Param colorA query string false "Team A colour, hex without '#'"
+// @Param colorB query string false "Team B colour, hex without '#'"
+// @Success 200 {string} string "HTML"
+// @Failure 400 {object} map[string]interface{}
+// @Router /overlay [get]
+func (h *OverlayHandler) GetOverlay(c *gin.Context) {
+	if layout := c.Query("layout"); layout != "" && !overlay.ValidLayout(layout) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "layout must be one of " + strings.Join(overlay.Layouts, ", ")})
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", overlay.Page())
+}
+
+// GetOverlayData returns the scoreboard as flat, display-ready strings
+// @Summary Overlay data feed
+// @Description One flat JSON object of pre-formatted strings for vMix and CasparCG data sources. teamA and teamB set the team names. During a playback the played back game is shown and playback is "PLAYBACK".
+// @Tags overlay
+// @Produce json
+// @Param teamA query string false "Team A name"
+// @Param teamB query string false "Team B name"
+// @Success 200 {object} overlay.Data
+// @Router /api/overlay/data [get]
+func (h *OverlayHandler) GetOverlayData(c *gin.Context) {
+	data := overlay.NewData(
+		h.controlService.StateSync(),
+		h.scoreboardService.GameID(),
+		c.DefaultQuery("teamA", overlay.DefaultTeamA),
+		c.DefaultQuery("teamB", overlay.DefaultTeamB),
+		h.playbackService.Status(),
+		h.controlService.DisplayRules(),
+	)
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, data)
+}
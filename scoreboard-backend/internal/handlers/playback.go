@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"scoreboard-backend/internal/models"
+	"scoreboard-backend/internal/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxPlaybackUpload bounds an uploaded event file; a full game's log with
+// every clock tick is a few megabytes
+const maxPlaybackUpload = 32 << 20
+
+// PlaybackHandler plays archived games back to the displays
+type PlaybackHandler struct {
+	playbackService   Playback
+	scoreboardService Scoreboard
+}
+
+func NewPlaybackHandler(playbackService Playback, scoreboardService Scoreboard) *PlaybackHandler {
+	return &PlaybackHandler{
+		playbackService:   playbackService,
+		scoreboardService: scoreboardService,
+	}
+}
+
+// GetPlayback returns the playback in progress
+// @Summary Get the playback in progress
+// @Description Returns {"status": "playing", "playback": {...}} during a playback, otherwise {"status": "idle"}
+// @Tags playback
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/playback [get]
+func (h *PlaybackHandler) GetPlayback(c *gin.Context) {
+	playback := h.playbackService.Status()
+	if playback == nil {
+		c.JSON(http.StatusOK, gin.H{"status": "idle"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "playing", "playback": playback})
+}
+
+// StartPlayback plays a past game, or uploaded events, back to the displays
+// @Summary Start a playback
+// @Description Broadcasts a game's events to every display as if it were live. With gameId (or "current") the game's event log is played; without it the request body is the events, as a JSON array or NDJSON like a file from the games directory. speed is 1, 2 or 10; step=true instead plays one event per /api/playback/step. The clocks must be stopped. When the playback ends a state_sync puts the live game back.
+// @Tags playback
+// @Accept json
+// @Produce json
+// @Param gameId query string false "Game ID or current"
+// @Param speed query int false "1, 2 or 10" default(1)
+// @Param step query bool false "Step through the events one at a time"
+// @Success 200 {object} services.Playback
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/playback/start [post]
+func (h *PlaybackHandler) StartPlayback(c *gin.Context) {
+	speed, err := strconv.Atoi(c.DefaultQuery("speed", "1"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": services.ErrPlaybackSpeed.Error()})
+		return
+	}
+	step := c.Query("step") == "true"
+
+	source := c.Query("gameId")
+	if source == "current" {
+		source = h.scoreboardService.GameID()
+	}
+	var events []models.Event
+	if source != "" {
+		events, err = h.scoreboardService.GameEvents(source)
+		switch {
+		case errors.Is(err, services.ErrGameNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, services.ErrCorruptLog):
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read game events"})
+			return
+		}
+	} else {
+		source = "file"
+		data, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxPlaybackUpload))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+			return
+		} else if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if events, err = services.ParseEvents(data); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	playback, err := h.playbackService.Start(source, events, speed, step)
+	if err != nil {
+		c.JSON(playbackErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, playback)
+}
+
+// StepPlayback plays the next event of a step-by-step playback
+// @Summary Step a playback
+// @Description Broadcasts the next event. After the last one the playback ends and the live game is put back.
+// @Tags playback
+// @Produce json
+// @Success 200 {object} services.Playback
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/playback/step [post]
+func (h *PlaybackHandler) StepPlayback(c *gin.Context) {
+	playback, err := h.playbackService.Step()
+	if err != nil {
+		c.JSON(playbackErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, playback)
+}
+
+// StopPlayback ends the playback and puts the live game back on the displays
+// @Summary Stop a playback
+// @Tags playback
+// @Produce json
+// @Success 200 {object} services.Playback
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/playback/stop [post]
+func (h *PlaybackHandler) StopPlayback(c *gin.Context) {
+	playback, err := h.playbackService.Stop()
+	if err != nil {
+		c.JSON(playbackErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, playback)
+}
+
+// playbackErrorStatus maps playback errors to HTTP status codes
+func playbackErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrPlaybackSpeed), errors.Is(err, services.ErrPlaybackEmpty):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrPlaybackActive), errors.Is(err, services.ErrNoPlayback),
+		errors.Is(err, services.ErrPlaybackNotStepping), errors.Is(err, services.ErrPlaybackLive):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
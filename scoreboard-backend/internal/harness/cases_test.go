@@ -0,0 +1,310 @@
+package harness
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// clients is how many displays each case connects. Every one of them must
+// receive the same broadcasts.
+const clients = 2
+
+// step is one request, the response it gets and the broadcasts it must cause
+type step struct {
+	Method string
+	Path   string
+	Body   interface{} // see Harness.Do; a bodyFunc is called first
+	Status int
+	// Response, when set, is the expected response body; see Match
+	Response string
+	// Advance moves the fake clock on after the request, so clock ticks
+	// are part of the expected broadcasts
+	Advance time.Duration
+	// Broadcasts are the messages every client receives, in order, and
+	// nothing else
+	Broadcasts []Broadcast
+}
+
+// bodyFunc builds a request body from the previous step's response, e.g.
+// to send back a confirmation token
+type bodyFunc func(previous []byte) interface{}
+
+// testCase is a sequence of steps run against a fresh backend
+type testCase struct {
+	Name  string
+	Steps []step
+}
+
+func TestCases(t *testing.T) {
+	for _, c := range cases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			t.Parallel()
+			c.run(t)
+		})
+	}
+}
+
+// run starts a backend, connects the displays and runs the steps
+func (c testCase) run(t *testing.T) {
+	h, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(h.Close)
+	displays := make([]*Client, clients)
+	for i := range displays {
+		if displays[i], err = h.Dial(); err != nil {
+			t.Fatalf("client %d: %v", i+1, err)
+		}
+		defer displays[i].Close()
+	}
+	var previous []byte
+	for i, step := range c.Steps {
+		body := step.Body
+		if f, ok := body.(bodyFunc); ok {
+			body = f(previous)
+		}
+		status, response, err := h.Do(step.Method, step.Path, body)
+		if err != nil {
+			t.Fatalf("step %d %s %s: %v", i+1, step.Method, step.Path, err)
+		}
+		if status != step.Status {
+			t.Fatalf("step %d %s %s: status %d, want %d: %s", i+1, step.Method, step.Path, status, step.Status, response)
+		}
+		if step.Response != "" {
+			if err := Match(step.Response, response); err != nil {
+				t.Fatalf("step %d %s %s: response: %v", i+1, step.Method, step.Path, err)
+			}
+		}
+		if step.Advance > 0 {
+			h.Clock.Advance(step.Advance)
+		}
+		for j, display := range displays {
+			if _, err := display.Expect(step.Broadcasts...); err != nil {
+				t.Fatalf("step %d %s %s: client %d: %v", i+1, step.Method, step.Path, j+1, err)
+			}
+		}
+		previous = response
+	}
+}
+
+// resetToken sends back the token from a reset confirmation
+var resetToken bodyFunc = func(previous []byte) interface{} {
+	var confirmation struct {
+		Token string `json:"token"`
+	}
+	json.Unmarshal(previous, &confirmation)
+	return map[string]string{"token": confirmation.Token}
+}
+
+// playbackFile is an uploaded game: a start, a basket after one second and a
+// foul after two
+const playbackFile = `{"seq":1,"time":"2026-01-01T10:00:00Z","type":"game_started","state":{"timerTenths":6000,"shotClockTenths":120}}
+{"seq":2,"time":"2026-01-01T10:00:01Z","type":"score_adjusted","team":"A","value":2}
+{"seq":3,"time":"2026-01-01T10:00:03Z","type":"foul_adjusted","team":"B","value":1}
+`
+
+func post(path string, body interface{}, response string, broadcasts ...Broadcast) step {
+	return step{Method: http.MethodPost, Path: path, Body: body, Status: http.StatusOK, Response: response, Broadcasts: broadcasts}
+}
+
+// The state every case starts from
+const (
+	startState     = `{"timerTenths":6000,"scoreA":0,"scoreB":0,"foulA":0,"foulB":0,"shotClockTenths":120,"isShotClockRunning":false}`
+	startStateSync = `{"timerTenths":6000,"scoreA":0,"scoreB":0,"foulA":0,"foulB":0,"shotClockTenths":120,"isShotClockRunning":false,` +
+		`"formattedTimer":"10:00","formattedShotclock":"12.0","version":1}`
+)
+
+// tick is a shot clock update while the clocks run. The game clock ticks on
+// a goroutine of its own, so the value it shows may be a tick ahead or behind.
+func tick(tenths int, running bool, formatted string) Broadcast {
+	return Broadcast{"shotclock_update", fmt.Sprintf(`{"shotClockTenths":%d,"isShotClockRunning":%t,"timerTenths":%q,"formattedTimer":%q,"formattedShotclock":%q}`,
+		tenths, running, Any, Any, formatted)}
+}
+
+// cases covers every endpoint that changes the game
+var cases = []testCase{
+	{"score increment", []step{
+		post("/api/scoreA/increment", nil, `{"scoreA":1}`,
+			Broadcast{"score_update", `{"team":"A","scoreA":1,"scoreB":0}`}),
+	}},
+	{"score decrement", []step{
+		post("/api/scoreB/increment", nil, `{"scoreB":1}`,
+			Broadcast{"score_update", `{"team":"B","scoreA":0,"scoreB":1}`}),
+		post("/api/scoreB/decrement", nil, `{"scoreB":0}`,
+			Broadcast{"score_update", `{"team":"B","scoreA":0,"scoreB":0}`}),
+	}},
+	{"foul increment", []step{
+		post("/api/foulA/increment", nil, `{"foulA":1}`,
+			Broadcast{"foul_update", `{"team":"A","foulA":1,"foulB":0}`}),
+	}},
+	{"foul decrement", []step{
+		post("/api/foulB/decrement", nil, `{"foulB":0}`,
+			Broadcast{"foul_update", `{"team":"B","foulA":0,"foulB":0}`}),
+	}},
+	{"score correction", []step{
+		{Method: http.MethodPut, Path: "/api/score", Body: map[string]uint{"scoreA": 5, "scoreB": 3}, Status: http.StatusOK,
+			Response: `{"timerTenths":6000,"scoreA":5,"scoreB":3,"foulA":0,"foulB":0,"shotClockTenths":120,"isShotClockRunning":false}`,
+			Broadcasts: []Broadcast{{"state_sync", `{"timerTenths":6000,"scoreA":5,"scoreB":3,"foulA":0,"foulB":0,"shotClockTenths":120,"isShotClockRunning":false,` +
+				`"formattedTimer":"10:00","formattedShotclock":"12.0","version":2}`}}},
+	}},
+	{"score correction over the cap", []step{
+		{Method: http.MethodPut, Path: "/api/score", Body: map[string]uint{"scoreA": 99}, Status: http.StatusUnprocessableEntity,
+			Response: `{"error":"outside rules profile limits: score 99 is above the fiba3x3 cap of 21"}`},
+	}},
+	{"foul correction", []step{
+		{Method: http.MethodPut, Path: "/api/fouls", Body: map[string]uint{"foulA": 2}, Status: http.StatusOK,
+			Response: `{"timerTenths":6000,"scoreA":0,"scoreB":0,"foulA":2,"foulB":0,"shotClockTenths":120,"isShotClockRunning":false}`,
+			Broadcasts: []Broadcast{{"state_sync", `{"timerTenths":6000,"scoreA":0,"scoreB":0,"foulA":2,"foulB":0,"shotClockTenths":120,"isShotClockRunning":false,` +
+				`"formattedTimer":"10:00","formattedShotclock":"12.0","version":2}`}}},
+	}},
+	{"timer set", []step{
+		post("/api/timer/set", map[string]string{"time": "05:00"}, `{"message":"Timer set","timerTenths":3000}`,
+			Broadcast{"timer_update", `{"timerTenths":3000,"formattedTimer":"05:00"}`}),
+	}},
+	{"timer set, bad value", []step{
+		{Method: http.MethodPost, Path: "/api/timer/set", Body: map[string]string{"time": "five"}, Status: http.StatusBadRequest,
+			Response: `{"error":"Invalid time format, expected mm:ss or mm:ss.t"}`},
+	}},
+	{"timer reset", []step{
+		post("/api/timer/reset", nil, `{"message":"Timer reset to 10:00"}`,
+			Broadcast{"timer_update", `{"timerTenths":6000,"formattedTimer":"10:00"}`}),
+	}},
+	{"clock adjust", []step{
+		// Already at 10:00, so the game clock stays there
+		post("/api/clock/adjust", map[string]int{"gameClockDeltaTenths": 7}, `{"state":`+startState+`,"clamped":true}`,
+			Broadcast{"state_sync", `{"timerTenths":6000,"scoreA":0,"scoreB":0,"foulA":0,"foulB":0,"shotClockTenths":120,"isShotClockRunning":false,` +
+				`"formattedTimer":"10:00","formattedShotclock":"12.0","version":2}`}),
+	}},
+	{"shot clock set", []step{
+		post("/api/shotclock/set", map[string]string{"time": "8.0"}, `{"message":"Shot clock set","shotClockTenths":80}`,
+			Broadcast{"shotclock_update", `{"shotClockTenths":80,"isShotClockRunning":false,"timerTenths":6000,"formattedTimer":"10:00","formattedShotclock":"08.0"}`}),
+	}},
+	{"shot clock reset", []step{
+		post("/api/shotclock/reset", nil, `{"message":"Shot clock reset to 12.0"}`,
+			Broadcast{"shotclock_update", `{"shotClockTenths":120,"isShotClockRunning":false,"timerTenths":6000,"formattedTimer":"10:00","formattedShotclock":"12.0"}`}),
+	}},
+	{"clocks start, tick and stop", []step{
+		{Method: http.MethodPost, Path: "/api/shotclock/start", Status: http.StatusOK, Response: `{"message":"Shot clock started"}`, Advance: 300 * time.Millisecond,
+			Broadcasts: []Broadcast{
+				{"shotclock_update", `{"shotClockTenths":120,"isShotClockRunning":true,"timerTenths":6000,"formattedTimer":"10:00","formattedShotclock":"12.0"}`},
+				tick(119, true, "11.9"),
+				tick(118, true, "11.8"),
+				tick(117, true, "11.7"),
+			}},
+		post("/api/shotclock/stop", nil, `{"message":"Shot clock stopped"}`, tick(117, false, "11.7")),
+	}},
+	{"clocks start twice", []step{
+		post("/api/shotclock/start", nil, `{"message":"Shot clock started"}`,
+			Broadcast{"shotclock_update", `{"shotClockTenths":120,"isShotClockRunning":true,"timerTenths":6000,"formattedTimer":"10:00","formattedShotclock":"12.0"}`}),
+		post("/api/shotclock/start", nil, `{"error":"Shot clock is already running"}`), // refused, but with 200 for the control panel
+	}},
+	{"shot clock expiry", []step{
+		post("/api/shotclock/set", map[string]string{"time": "0.2"}, `{"message":"Shot clock set","shotClockTenths":2}`,
+			Broadcast{"shotclock_update", `{"shotClockTenths":2,"isShotClockRunning":false,"timerTenths":6000,"formattedTimer":"10:00","formattedShotclock":"00.2"}`}),
+		{Method: http.MethodPost, Path: "/api/shotclock/start", Status: http.StatusOK, Response: `{"message":"Shot clock started"}`, Advance: 500 * time.Millisecond,
+			Broadcasts: []Broadcast{
+				{"shotclock_update", `{"shotClockTenths":2,"isShotClockRunning":true,"timerTenths":6000,"formattedTimer":"10:00","formattedShotclock":"00.2"}`},
+				tick(1, true, "00.1"),
+				tick(0, false, "00.0"), // Both clocks stop
+			}},
+		// Play goes on after the violation
+		post("/api/shotclock/reset", nil, `{"message":"Shot clock reset to 12.0"}`, tick(120, true, "12.0")),
+	}},
+	{"state sync", []step{
+		post("/api/state/sync", nil, `{"message":"state_sync broadcasted"}`, Broadcast{"state_sync", startStateSync}),
+	}},
+	{"game reset", []step{
+		{Method: http.MethodPost, Path: "/api/game/reset", Status: http.StatusAccepted,
+			Response: `{"token":"<any>","expiresAt":"2026-01-01T12:01:00Z","summary":{"state":` + startState + `,"gameLogEntries":0,"archived":true,` +
+				`"description":"Score 0-0, fouls 0-0, 10:00 on the game clock and 0 log entries will be cleared. The game will be archived and can be restored."}}`},
+		post("/api/game/reset", resetToken, `{"archiveId":"20260101T120000.000Z","message":"Game reset to default"}`,
+			Broadcast{"game_reset", `{"timerTenths":6000,"scoreA":0,"scoreB":0,"foulA":0,"foulB":0,"shotClockTenths":120,"isShotClockRunning":false,` +
+				`"formattedTimer":"10:00","formattedShotclock":"12.0"}`}),
+	}},
+	{"game reset, bad token", []step{
+		{Method: http.MethodPost, Path: "/api/game/reset", Body: map[string]string{"token": "nope"}, Status: http.StatusConflict,
+			Response: `{"error":"reset confirmation token is invalid or expired"}`},
+	}},
+	{"review", []step{
+		post("/api/scoreA/increment", nil, `{"scoreA":1}`,
+			Broadcast{"score_update", `{"team":"A","scoreA":1,"scoreB":0}`}),
+		post("/api/review/start", map[string]string{"reason": "shot at the buzzer"},
+			`{"reason":"shot at the buzzer","startedAt":"2026-01-01T12:00:00Z",`+
+				`"actor":{"source":"rest","requestId":"<any>","clientIp":"127.0.0.1","route":"/api/review/start"},`+
+				`"before":{"timerTenths":6000,"scoreA":1,"scoreB":0,"foulA":0,"foulB":0,"shotClockTenths":120,"isShotClockRunning":false}}`,
+			Broadcast{"shotclock_update", `{"shotClockTenths":120,"isShotClockRunning":false,"timerTenths":6000,"formattedTimer":"10:00","formattedShotclock":"12.0"}`},
+			Broadcast{"review_update", `{"status":"review","reason":"shot at the buzzer"}`}),
+		post("/api/shotclock/start", nil, `{"error":"a review is in progress"}`),
+		post("/api/review/rewind", map[string]int{"event": 1}, `{"message":"Rewound","state":`+startState+`}`,
+			Broadcast{"state_sync", `{"timerTenths":6000,"scoreA":0,"scoreB":0,"foulA":0,"foulB":0,"shotClockTenths":120,"isShotClockRunning":false,` +
+				`"formattedTimer":"10:00","formattedShotclock":"12.0","version":3,"review":{"status":"review","reason":"shot at the buzzer","rewoundTo":1}}`}),
+		post("/api/review/end", map[string]string{"outcome": "no basket"},
+			`{"message":"Review ended","outcome":"no basket","review":{"reason":"shot at the buzzer","startedAt":"2026-01-01T12:00:00Z",`+
+				`"actor":{"source":"rest","requestId":"<any>","clientIp":"127.0.0.1","route":"/api/review/start"},`+
+				`"before":{"timerTenths":6000,"scoreA":1,"scoreB":0,"foulA":0,"foulB":0,"shotClockTenths":120,"isShotClockRunning":false},"rewoundTo":1}}`,
+			Broadcast{"review_update", `{"status":"live","reason":"shot at the buzzer","outcome":"no basket","rewoundTo":1}`}),
+	}},
+	{"playback, stepped", []step{
+		post("/api/scoreA/increment", nil, `{"scoreA":1}`,
+			Broadcast{"score_update", `{"team":"A","scoreA":1,"scoreB":0}`}),
+		post("/api/playback/start?gameId=current&step=true", nil,
+			`{"source":"20260101T120000.000Z","step":true,"position":1,"events":2,"startedAt":"2026-01-01T12:00:00Z","state":`+startState+`}`,
+			Broadcast{"state_sync", `{"timerTenths":6000,"scoreA":0,"scoreB":0,"foulA":0,"foulB":0,"shotClockTenths":120,"isShotClockRunning":false,` +
+				`"formattedTimer":"10:00","formattedShotclock":"12.0","version":2}`}),
+		{Method: http.MethodPost, Path: "/api/playback/start?gameId=current&step=true", Status: http.StatusConflict,
+			Response: `{"error":"a playback is already running"}`},
+		// The last event, then the live game
+		post("/api/playback/step", nil,
+			`{"source":"20260101T120000.000Z","step":true,"position":2,"events":2,"startedAt":"2026-01-01T12:00:00Z",`+
+				`"state":{"timerTenths":6000,"scoreA":1,"scoreB":0,"foulA":0,"foulB":0,"shotClockTenths":120,"isShotClockRunning":false}}`,
+			Broadcast{"score_update", `{"team":"A","scoreA":1,"scoreB":0}`},
+			Broadcast{"state_sync", `{"timerTenths":6000,"scoreA":1,"scoreB":0,"foulA":0,"foulB":0,"shotClockTenths":120,"isShotClockRunning":false,` +
+				`"formattedTimer":"10:00","formattedShotclock":"12.0","version":2}`}),
+		{Method: http.MethodPost, Path: "/api/playback/step", Status: http.StatusConflict, Response: `{"error":"no playback is running"}`},
+	}},
+	{"playback, timed", []step{
+		post("/api/playback/start?speed=2", playbackFile,
+			`{"source":"file","speed":2,"step":false,"position":1,"events":3,"startedAt":"2026-01-01T12:00:00Z","state":`+startState+`}`,
+			Broadcast{"state_sync", startStateSync}),
+		// Each wait is half the recorded gap, and starts once the previous
+		// event has gone out
+		{Method: http.MethodGet, Path: "/api/playback", Status: http.StatusOK, Advance: 500 * time.Millisecond,
+			Response:   `{"playback":{"source":"file","speed":2,"step":false,"position":1,"events":3,"startedAt":"2026-01-01T12:00:00Z","state":` + startState + `},"status":"playing"}`,
+			Broadcasts: []Broadcast{{"score_update", `{"team":"A","scoreA":2,"scoreB":0}`}}},
+		{Method: http.MethodGet, Path: "/api/playback", Status: http.StatusOK, Advance: 999 * time.Millisecond,
+			Response: `{"playback":{"source":"file","speed":2,"step":false,"position":2,"events":3,"startedAt":"2026-01-01T12:00:00Z",` +
+				`"state":{"timerTenths":6000,"scoreA":2,"scoreB":0,"foulA":0,"foulB":0,"shotClockTenths":120,"isShotClockRunning":false}},"status":"playing"}`},
+		{Method: http.MethodGet, Path: "/api/playback", Status: http.StatusOK, Advance: time.Millisecond,
+			Response: `{"playback":{"source":"file","speed":2,"step":false,"position":2,"events":3,"startedAt":"2026-01-01T12:00:00Z",` +
+				`"state":{"timerTenths":6000,"scoreA":2,"scoreB":0,"foulA":0,"foulB":0,"shotClockTenths":120,"isShotClockRunning":false}},"status":"playing"}`,
+			// The last event, then the live game
+			Broadcasts: []Broadcast{
+				{"foul_update", `{"team":"B","foulA":0,"foulB":1}`},
+				{"state_sync", startStateSync},
+			}},
+	}},
+	{"playback, stopped", []step{
+		post("/api/playback/start?speed=10", playbackFile,
+			`{"source":"file","speed":10,"step":false,"position":1,"events":3,"startedAt":"2026-01-01T12:00:00Z","state":`+startState+`}`,
+			Broadcast{"state_sync", startStateSync}),
+		post("/api/playback/stop", nil,
+			`{"source":"file","speed":10,"step":false,"position":1,"events":3,"startedAt":"2026-01-01T12:00:00Z","state":`+startState+`}`,
+			Broadcast{"state_sync", startStateSync}),
+		{Method: http.MethodPost, Path: "/api/playback/stop", Status: http.StatusConflict, Response: `{"error":"no playback is running"}`},
+	}},
+	{"playback, bad speed", []step{
+		{Method: http.MethodPost, Path: "/api/playback/start?speed=3", Body: playbackFile, Status: http.StatusBadRequest,
+			Response: `{"error":"playback speed must be 1, 2 or 10"}`},
+	}},
+	{"playback while the clocks run", []step{
+		post("/api/shotclock/start", nil, `{"message":"Shot clock started"}`,
+			Broadcast{"shotclock_update", `{"shotClockTenths":120,"isShotClockRunning":true,"timerTenths":6000,"formattedTimer":"10:00","formattedShotclock":"12.0"}`}),
+		{Method: http.MethodPost, Path: "/api/playback/start", Body: playbackFile, Status: http.StatusConflict,
+			Response: `{"error":"stop the clocks before starting a playback"}`},
+	}},
+}
@@ -0,0 +1,262 @@
+// Package harness runs the whole backend on an httptest server, with a fake
+// clock and a throwaway data directory, and drives it over HTTP and real
+// WebSocket connections. Its tests pin down the exact broadcasts each
+// endpoint sends.
+package harness
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"scoreboard-backend/internal/clock"
+	"scoreboard-backend/internal/config"
+	"scoreboard-backend/internal/server"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// Start is the fake clock's reading when a harness starts
+var Start = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+const (
+	// MessageTimeout bounds the wait for each expected broadcast
+	MessageTimeout = 2 * time.Second
+	// QuietPeriod is how long a client must then receive nothing else
+	QuietPeriod = 100 * time.Millisecond
+)
+
+// Harness is one backend served over HTTP on a local port
+type Harness struct {
+	Server *server.Server
+	Clock  *clock.Fake
+	HTTP   *httptest.Server
+	dir    string
+}
+
+// New starts a backend with the default configuration in a new temporary
+// data directory
+func New() (*Harness, error) {
+	dir, err := os.MkdirTemp("", "scoreboard-harness-")
+	if err != nil {
+		return nil, err
+	}
+	gin.SetMode(gin.TestMode)
+	cfg := config.Default()
+	cfg.DataDir = dir
+	fake := clock.NewFake(Start)
+	srv, err := server.New(cfg, fake)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	return &Harness{Server: srv, Clock: fake, HTTP: httptest.NewServer(srv.Router), dir: dir}, nil
+}
+
+// Close stops the clocks, the server and the hub, and removes the data directory
+func (h *Harness) Close() {
+	h.Server.SaveState()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	h.Server.WebSocket.Shutdown(ctx)
+	h.HTTP.Close()
+	os.RemoveAll(h.dir)
+}
+
+// Do sends a request with body encoded as JSON, or sent as is when it is a
+// string, and returns the status and response body
+func (h *Harness) Do(method, path string, body interface{}) (int, []byte, error) {
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case string:
+		reader = strings.NewReader(b)
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			return 0, nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, h.HTTP.URL+path, reader)
+	if err != nil {
+		return 0, nil, err
+	}
+	if reader != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := h.HTTP.Client().Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	return resp.StatusCode, data, err
+}
+
+// Message is a broadcast as a client received it
+type Message struct {
+	Version int             `json:"v"`
+	Type    string          `json:"type"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// Client is a display connected to /ws/state with the JSON encoding
+type Client struct {
+	conn     *websocket.Conn
+	messages chan Message
+	err      error // set before messages is closed
+}
+
+// Dial connects a client and waits for the state_sync every new client gets
+func (h *Harness) Dial() (*Client, error) {
+	url := "ws" + strings.TrimPrefix(h.HTTP.URL, "http") + "/ws/state"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{conn: conn, messages: make(chan Message, 256)}
+	go c.read()
+	m, err := c.Next(MessageTimeout)
+	if err == nil && m.Type != "state_sync" {
+		err = fmt.Errorf("got %s", m.Type)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("initial sync: %w", err)
+	}
+	return c, nil
+}
+
+func (c *Client) read() {
+	defer close(c.messages)
+	for {
+		var m Message
+		if err := c.conn.ReadJSON(&m); err != nil {
+			c.err = err
+			return
+		}
+		c.messages <- m
+	}
+}
+
+// Next returns the next broadcast, waiting up to timeout
+func (c *Client) Next(timeout time.Duration) (Message, error) {
+	select {
+	case m, ok := <-c.messages:
+		if !ok {
+			return Message{}, fmt.Errorf("connection closed: %w", c.err)
+		}
+		return m, nil
+	case <-time.After(timeout):
+		return Message{}, errTimeout
+	}
+}
+
+var errTimeout = errors.New("timed out")
+
+// Broadcast is an expected message. Data is its payload as JSON, compared
+// as a value, so key order and spacing do not matter. A value of Any matches
+// anything.
+type Broadcast struct {
+	Type string
+	Data string
+}
+
+// Any stands for a value that varies between runs, such as the game clock in
+// a shot clock tick, which the game clock's own goroutine may or may not
+// have ticked yet
+const Any = "<any>"
+
+// Expect reads broadcasts until it has one for each expected, in order, and
+// fails on any difference, including a message arriving within QuietPeriod
+// after the last expected one
+func (c *Client) Expect(want ...Broadcast) ([]Message, error) {
+	got := make([]Message, 0, len(want))
+	for i, w := range want {
+		m, err := c.Next(MessageTimeout)
+		if err != nil {
+			return got, fmt.Errorf("message %d: want %s: %w (got %s)", i+1, w.Type, err, typesOf(got))
+		}
+		got = append(got, m)
+		if m.Type != w.Type {
+			return got, fmt.Errorf("message %d: want %s, got %s %s (so far %s)", i+1, w.Type, m.Type, m.Data, typesOf(got))
+		}
+		if err := Match(w.Data, m.Data); err != nil {
+			return got, fmt.Errorf("message %d, %s: %w", i+1, m.Type, err)
+		}
+	}
+	if m, err := c.Next(QuietPeriod); err == nil {
+		return got, fmt.Errorf("unexpected %s %s after %s", m.Type, m.Data, typesOf(got))
+	} else if !errors.Is(err, errTimeout) {
+		return got, err
+	}
+	return got, nil
+}
+
+// Match compares JSON to the expected JSON, in which Any matches any value
+func Match(want string, got []byte) error {
+	var w, g interface{}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		return fmt.Errorf("expected JSON: %w", err)
+	}
+	if err := json.Unmarshal(got, &g); err != nil {
+		return err
+	}
+	if !matches(w, g) {
+		return fmt.Errorf("got %s, want %s", got, want)
+	}
+	return nil
+}
+
+func matches(want, got interface{}) bool {
+	switch w := want.(type) {
+	case string:
+		return w == Any || w == got
+	case map[string]interface{}:
+		g, ok := got.(map[string]interface{})
+		if !ok || len(g) != len(w) {
+			return false
+		}
+		for k, v := range w {
+			if gv, ok := g[k]; !ok || !matches(v, gv) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		g, ok := got.([]interface{})
+		if !ok || len(g) != len(w) {
+			return false
+		}
+		for i := range w {
+			if !matches(w[i], g[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return want == got
+	}
+}
+
+// Close disconnects the client
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+func typesOf(messages []Message) string {
+	types := make([]string, len(messages))
+	for i, m := range messages {
+		types[i] = m.Type
+	}
+	return "[" + strings.Join(types, " ") + "]"
+}
@@ -0,0 +1,24 @@
+package harness
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		want, got string
+		ok        bool
+	}{
+		{`{"a":1,"b":"x"}`, `{"b":"x","a":1}`, true},
+		{`{"a":1}`, `{"a":2}`, false},
+		{`{"a":1}`, `{"a":1,"b":2}`, false},
+		{`{"a":1,"b":2}`, `{"a":1}`, false},
+		{`{"a":"<any>","b":[1,{"c":"<any>"}]}`, `{"a":{"deep":true},"b":[1,{"c":5}]}`, true},
+		{`{"a":"<any>"}`, `{}`, false},
+		{`[1,2]`, `[1,2,3]`, false},
+		{`{"a":"1"}`, `{"a":1}`, false},
+	}
+	for _, tt := range tests {
+		if err := Match(tt.want, []byte(tt.got)); (err == nil) != tt.ok {
+			t.Errorf("Match(%s, %s): %v, want ok %v", tt.want, tt.got, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package input
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"scoreboard-backend/internal/models"
+	"scoreboard-backend/internal/remote"
+	"scoreboard-backend/internal/services"
+	"sync"
+	"time"
+)
+
+const reopenDelay = 2 * time.Second
+
+// Config selects the devices to read and how keys map to commands
+type Config struct {
+	Devices []string // Device paths or globs, e.g. /dev/input/by-id/*-event-kbd
+	KeyMap  KeyMap
+	Grab    bool // Take exclusive access so key presses don't reach the console
+}
+
+// Daemon reads key presses from evdev devices and dispatches the mapped
+// commands through the control service, exactly like the REST endpoints.
+// A device that disappears is reopened every few seconds. A regular file
+// (a recorded event stream) is read once.
+type Daemon struct {
+	control *services.ControlService
+	config  Config
+	stop    chan struct{}
+	once    sync.Once // Closes stop
+	wg      sync.WaitGroup
+	mutex   sync.Mutex
+	open    map[string]*os.File
+}
+
+func NewDaemon(control *services.ControlService, config Config) *Daemon {
+	return &Daemon{
+		control: control,
+		config:  config,
+		stop:    make(chan struct{}),
+		open:    make(map[string]*os.File),
+	}
+}
+
+// Start expands the device globs and reads each device in the background.
+func (d *Daemon) Start() error {
+	var paths []string
+	for _, pattern := range d.config.Devices {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, matches...)
+	}
+	if len(paths) == 0 {
+		return errors.New("no input devices match the configured paths")
+	}
+	for _, path := range paths {
+		d.wg.Add(1)
+		go d.readDevice(path)
+	}
+	return nil
+}
+
+// Close stops every reader and waits for them to exit. Calling it more than
+// once is safe.
+func (d *Daemon) Close() error {
+	d.once.Do(func() { close(d.stop) })
+	d.mutex.Lock()
+	for _, f := range d.open {
+		f.Close()
+	}
+	d.mutex.Unlock()
+	d.wg.Wait()
+	return nil
+}
+
+func (d *Daemon) stopping() bool {
+	select {
+	case <-d.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (d *Daemon) readDevice(path string) {
+	defer d.wg.Done()
+	actor := models.Actor{Source: models.SourceInput, ID: filepath.Base(path), Route: path}
+	for !d.stopping() {
+		replay, err := d.readOnce(path, actor)
+		if d.stopping() || replay {
+			return
+		}
+		slog.Warn("Input device unavailable", "device", path, "error", err, "retryIn", reopenDelay.String())
+		select {
+		case <-d.stop:
+			return
+		case <-time.After(reopenDelay):
+		}
+	}
+}
+
+// readOnce opens the device and dispatches events until it fails. replay is
+// true when path is a regular file that has been read to the end.
+func (d *Daemon) readOnce(path string, actor models.Actor) (replay bool, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return false, err
+	}
+	replay = info.Mode().IsRegular()
+
+	// Close may have run between the open and here, and it only closes
+	// the files it finds registered
+	d.mutex.Lock()
+	if d.stopping() {
+		d.mutex.Unlock()
+		f.Close()
+		return false, errors.New("input daemon closed")
+	}
+	d.open[path] = f
+	d.mutex.Unlock()
+	defer func() {
+		d.mutex.Lock()
+		delete(d.open, path)
+		d.mutex.Unlock()
+		f.Close()
+	}()
+
+	if d.config.Grab && !replay {
+		if err := grab(f); err != nil {
+			slog.Warn("Input device grab failed", "device", path, "error", err)
+		}
+	}
+	slog.Info("Reading key events", "device", path)
+
+	for {
+		event, err := ReadEvent(f)
+		if err != nil {
+			if replay && errors.Is(err, io.EOF) {
+				slog.Info("Finished replaying key events", "device", path)
+				return true, nil
+			}
+			return replay, err
+		}
+		d.dispatch(event, actor)
+	}
+}
+
+// dispatch runs the command mapped to a key press. Releases and auto-repeat
+// are ignored so holding a key cannot add points.
+func (d *Daemon) dispatch(event Event, actor models.Actor) {
+	if event.Type != EvKey || event.Value != KeyPress {
+		return
+	}
+	cmd, ok := d.config.KeyMap[event.Code]
+	if !ok {
+		return
+	}
+	reply, err := remote.Execute(d.control, cmd, actor)
+	if err != nil {
+		slog.Warn("Input command failed", "key", event.Code, "actor", actor, "error", err)
+		return
+	}
+	slog.Debug("Input command", "key", event.Code, "actor", actor, "reply", reply)
+}
@@ -0,0 +1,53 @@
+package input
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+// A pipe blocks in read like an idle keypad. Close must end the read
+// whether it runs before, during or after the device is opened.
+func TestCloseWhileReading(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		d := NewDaemon(nil, Config{Devices: []string{fmt.Sprintf("/proc/self/fd/%d", r.Fd())}})
+		if err := d.Start(); err != nil {
+			t.Fatal(err)
+		}
+		if i%2 == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		closed := make(chan struct{})
+		go func() {
+			d.Close()
+			close(closed)
+		}()
+		select {
+		case <-closed:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("run %d: Close hung on a blocked read", i)
+		}
+		r.Close()
+		w.Close()
+	}
+}
+
+func TestCloseTwice(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	d := NewDaemon(nil, Config{Devices: []string{fmt.Sprintf("/proc/self/fd/%d", r.Fd())}})
+	if err := d.Start(); err != nil {
+		t.Fatal(err)
+	}
+	d.Close()
+	d.Close() // Shutdown and a deferred Close may both run
+}
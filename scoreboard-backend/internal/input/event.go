@@ -0,0 +1,66 @@
+// Package input reads key presses from Linux evdev devices
+// (/dev/input/event*) and turns them into scoreboard commands, so a USB
+// keypad on the backend box can replace the browser control panel.
+package input
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"time"
+)
+
+// Event type and key values from linux/input-event-codes.h
+const (
+	EvKey = 0x01
+
+	KeyRelease = 0
+	KeyPress   = 1
+	KeyRepeat  = 2
+)
+
+// eventSize is sizeof(struct input_event) on 64-bit Linux: a 16-byte
+// timeval followed by type, code and value.
+const eventSize = 24
+
+// Event is one struct input_event
+type Event struct {
+	Time  time.Time
+	Type  uint16
+	Code  uint16
+	Value int32
+}
+
+// ReadEvent reads one event in the kernel's binary layout. A recorded event
+// file (cat /dev/input/eventN > keys.bin) has the same layout.
+func ReadEvent(r io.Reader) (Event, error) {
+	var buf [eventSize]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return Event{}, io.EOF
+		}
+		return Event{}, err
+	}
+	sec := int64(binary.LittleEndian.Uint64(buf[0:8]))
+	usec := int64(binary.LittleEndian.Uint64(buf[8:16]))
+	return Event{
+		Time:  time.Unix(sec, usec*1000),
+		Type:  binary.LittleEndian.Uint16(buf[16:18]),
+		Code:  binary.LittleEndian.Uint16(buf[18:20]),
+		Value: int32(binary.LittleEndian.Uint32(buf[20:24])),
+	}, nil
+}
+
+// WriteEvent writes one event in the kernel's binary layout, for recording
+// test fixtures or feeding uinput.
+func WriteEvent(w io.Writer, e Event) error {
+	var buf [eventSize]byte
+	usec := e.Time.UnixNano() / 1000
+	binary.LittleEndian.PutUint64(buf[0:8], uint64(usec/1e6))
+	binary.LittleEndian.PutUint64(buf[8:16], uint64(usec%1e6))
+	binary.LittleEndian.PutUint16(buf[16:18], e.Type)
+	binary.LittleEndian.PutUint16(buf[18:20], e.Code)
+	binary.LittleEndian.PutUint32(buf[20:24], uint32(e.Value))
+	_, err := w.Write(buf[:])
+	return err
+}
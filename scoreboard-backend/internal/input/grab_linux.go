@@ -0,0 +1,20 @@
+//go:build linux
+
+package input
+
+import (
+	"os"
+	"syscall"
+)
+
+// eviocgrab is EVIOCGRAB from linux/input.h: _IOW('E', 0x90, int)
+const eviocgrab = 0x40044590
+
+// grab takes exclusive access to the device
+func grab(f *os.File) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), eviocgrab, 1)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package input
+
+import (
+	"errors"
+	"os"
+)
+
+// grab is only supported on Linux
+func grab(f *os.File) error {
+	return errors.New("exclusive grab is only supported on linux")
+}
@@ -0,0 +1,86 @@
+package input
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"scoreboard-backend/internal/remote"
+)
+
+// keyCodes maps linux/input-event-codes.h names to codes for the keys an
+// operator keypad is likely to have.
+var keyCodes = map[string]uint16{
+	"KEY_ESC": 1, "KEY_1": 2, "KEY_2": 3, "KEY_3": 4, "KEY_4": 5, "KEY_5": 6,
+	"KEY_6": 7, "KEY_7": 8, "KEY_8": 9, "KEY_9": 10, "KEY_0": 11,
+	"KEY_MINUS": 12, "KEY_EQUAL": 13, "KEY_BACKSPACE": 14, "KEY_TAB": 15,
+	"KEY_Q": 16, "KEY_W": 17, "KEY_E": 18, "KEY_R": 19, "KEY_T": 20, "KEY_Y": 21,
+	"KEY_U": 22, "KEY_I": 23, "KEY_O": 24, "KEY_P": 25, "KEY_ENTER": 28,
+	"KEY_A": 30, "KEY_S": 31, "KEY_D": 32, "KEY_F": 33, "KEY_G": 34, "KEY_H": 35,
+	"KEY_J": 36, "KEY_K": 37, "KEY_L": 38, "KEY_Z": 44, "KEY_X": 45, "KEY_C": 46,
+	"KEY_V": 47, "KEY_B": 48, "KEY_N": 49, "KEY_M": 50, "KEY_SPACE": 57,
+	"KEY_F1": 59, "KEY_F2": 60, "KEY_F3": 61, "KEY_F4": 62, "KEY_F5": 63,
+	"KEY_F6": 64, "KEY_F7": 65, "KEY_F8": 66, "KEY_F9": 67, "KEY_F10": 68,
+	"KEY_NUMLOCK": 69, "KEY_KP7": 71, "KEY_KP8": 72, "KEY_KP9": 73,
+	"KEY_KPMINUS": 74, "KEY_KP4": 75, "KEY_KP5": 76, "KEY_KP6": 77,
+	"KEY_KPPLUS": 78, "KEY_KP1": 79, "KEY_KP2": 80, "KEY_KP3": 81,
+	"KEY_KP0": 82, "KEY_KPDOT": 83, "KEY_F11": 87, "KEY_F12": 88,
+	"KEY_KPENTER": 96, "KEY_KPSLASH": 98, "KEY_KPASTERISK": 55,
+}
+
+// KeyMap maps key codes to parsed commands
+type KeyMap map[uint16]remote.Command
+
+// DefaultKeyMap is a numpad layout: Team A on the left column, Team B on the
+// right, clocks along the bottom.
+var DefaultKeyMap = map[string]string{
+	"KEY_KP7":        "A+1",
+	"KEY_KP4":        "A+2",
+	"KEY_KP1":        "A-1",
+	"KEY_KP9":        "B+1",
+	"KEY_KP6":        "B+2",
+	"KEY_KP3":        "B-1",
+	"KEY_KPSLASH":    "FA+",
+	"KEY_KPASTERISK": "FB+",
+	"KEY_KP8":        "FA-",
+	"KEY_KPMINUS":    "FB-",
+	"KEY_KP0":        "CLOCK START",
+	"KEY_KPDOT":      "CLOCK STOP",
+	"KEY_KPENTER":    "SC RESET",
+	"KEY_KP5":        "SYNC",
+}
+
+// ParseKeyMap resolves key names and parses each command with the same
+// grammar the remote control listener uses.
+func ParseKeyMap(names map[string]string) (KeyMap, error) {
+	keymap := KeyMap{}
+	for name, line := range names {
+		code, ok := keyCodes[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown key %q", name)
+		}
+		cmd, err := remote.ParseCommand(line)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %q: %w", name, line, err)
+		}
+		keymap[code] = cmd
+	}
+	return keymap, nil
+}
+
+// LoadKeyMap reads a JSON object of key name to command, e.g.
+// {"KEY_KP7": "A+1", "KEY_KP0": "CLOCK START"}. An empty path returns
+// DefaultKeyMap.
+func LoadKeyMap(path string) (KeyMap, error) {
+	if path == "" {
+		return ParseKeyMap(DefaultKeyMap)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var names map[string]string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return nil, fmt.Errorf("key map %s: %w", path, err)
+	}
+	return ParseKeyMap(names)
+}
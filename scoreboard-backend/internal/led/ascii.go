@@ -0,0 +1,119 @@
+package led
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	stx = 0x02
+	etx = 0x03
+)
+
+// Field widths of an ASCII frame's payload
+const (
+	asciiGameClock = 5 // mm:ss or ss.t
+	asciiShotClock = 4
+	asciiScore     = 3
+	asciiFoul      = 2
+	asciiPayload   = asciiGameClock + asciiShotClock + 2*asciiScore + 2*asciiFoul + 1
+)
+
+// ASCII is the fixed-width text framing most scoreboard controllers with a
+// serial "data in" port accept:
+//
+//	STX, address (2 digits), payload, ETX, checksum (2 hex digits)
+//
+// The payload is the game clock (5), shot clock (4), score A and B (3 each),
+// fouls A and B (2 each), each right-aligned and space padded, then '1' while
+// the shot clock runs or '0'. The checksum is the XOR of every byte from the
+// address through ETX.
+type ASCII struct{}
+
+func (ASCII) Encode(f Frame, address int) []byte {
+	running := "0"
+	if f.ShotClockRunning {
+		running = "1"
+	}
+	var b strings.Builder
+	b.WriteByte(stx)
+	b.WriteString(fit(strconv.Itoa(address%100), 2))
+	b.WriteString(fit(f.GameClock, asciiGameClock))
+	b.WriteString(fit(f.ShotClock, asciiShotClock))
+	b.WriteString(fit(strconv.FormatUint(uint64(f.ScoreA), 10), asciiScore))
+	b.WriteString(fit(strconv.FormatUint(uint64(f.ScoreB), 10), asciiScore))
+	b.WriteString(fit(strconv.FormatUint(uint64(f.FoulA), 10), asciiFoul))
+	b.WriteString(fit(strconv.FormatUint(uint64(f.FoulB), 10), asciiFoul))
+	b.WriteString(running)
+	b.WriteByte(etx)
+	frame := []byte(b.String())
+	return append(frame, fmt.Sprintf("%02X", xorChecksum(frame[1:]))...)
+}
+
+func (ASCII) Decode(r *bufio.Reader) (Frame, int, error) {
+	if err := skipTo(r, stx); err != nil {
+		return Frame{}, 0, err
+	}
+	body := make([]byte, 2+asciiPayload+1+2)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return Frame{}, 0, err
+	}
+	if body[len(body)-3] != etx {
+		return Frame{}, 0, errors.New("ascii frame: no ETX after the payload")
+	}
+	sum, err := strconv.ParseUint(string(body[len(body)-2:]), 16, 8)
+	if err != nil || byte(sum) != xorChecksum(body[:len(body)-2]) {
+		return Frame{}, 0, errors.New("ascii frame: bad checksum")
+	}
+	address, err := strconv.Atoi(strings.TrimSpace(string(body[:2])))
+	if err != nil {
+		return Frame{}, 0, fmt.Errorf("ascii frame: bad address %q", body[:2])
+	}
+
+	payload := string(body[2 : 2+asciiPayload])
+	next := func(width int) string {
+		field := strings.TrimSpace(payload[:width])
+		payload = payload[width:]
+		return field
+	}
+	var f Frame
+	f.GameClock = next(asciiGameClock)
+	f.ShotClock = next(asciiShotClock)
+	for _, field := range []struct {
+		dst   *uint
+		width int
+	}{{&f.ScoreA, asciiScore}, {&f.ScoreB, asciiScore}, {&f.FoulA, asciiFoul}, {&f.FoulB, asciiFoul}} {
+		n, err := strconv.ParseUint(next(field.width), 10, 32)
+		if err != nil {
+			return Frame{}, 0, fmt.Errorf("ascii frame: %w", err)
+		}
+		*field.dst = uint(n)
+	}
+	f.ShotClockRunning = payload == "1"
+	return f, address, nil
+}
+
+func xorChecksum(data []byte) byte {
+	var sum byte
+	for _, b := range data {
+		sum ^= b
+	}
+	return sum
+}
+
+// skipTo discards input up to and including the next start byte
+func skipTo(r *bufio.Reader, start byte) error {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if b == start {
+			return nil
+		}
+	}
+}
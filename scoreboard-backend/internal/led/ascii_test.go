@@ -0,0 +1,40 @@
+package led
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+func TestASCIIEncode(t *testing.T) {
+	tests := []struct {
+		name    string
+		frame   Frame
+		address int
+		want    string
+	}{
+		{"running", Frame{GameClock: "04:12", ShotClock: "08.0", ScoreA: 14, ScoreB: 101, FoulA: 3, FoulB: 5, ShotClockRunning: true}, 7,
+			"\x02 704:1208.0 14101 3 51\x031D"},
+		{"last minute", Frame{GameClock: "59.9", ShotClock: "12"}, 0,
+			"\x02 0 59.9  12  0  0 0 00\x031B"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ASCII{}.Encode(tt.frame, tt.address)
+			if !bytes.Equal(got, []byte(tt.want)) {
+				t.Fatalf("Encode = %q, want %q", got, tt.want)
+			}
+			f, address, err := ASCII{}.Decode(bufio.NewReader(bytes.NewReader(append([]byte("noise"), got...))))
+			if err != nil || f != tt.frame || address != tt.address {
+				t.Errorf("Decode = %+v, %d, %v, want %+v, %d", f, address, err, tt.frame, tt.address)
+			}
+		})
+	}
+}
+
+func TestASCIIDecodeBadChecksum(t *testing.T) {
+	frame := []byte("\x02 704:1208.0 14101 3 51\x031E")
+	if _, _, err := (ASCII{}).Decode(bufio.NewReader(bytes.NewReader(frame))); err == nil {
+		t.Error("frame with a bad checksum decoded")
+	}
+}
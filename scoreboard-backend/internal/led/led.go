@@ -0,0 +1,78 @@
+// Package led drives LED and seven-segment scoreboard hardware. An Output
+// follows the same broadcasts the displays get and pushes a Frame to each
+// Driver whenever the board changes, and again every refresh interval since
+// most controllers blank when data stops. PortDriver writes frames to a
+// serial port (RS-232 or RS-485) or to a simulated one, a pty, in one of the
+// Protocols.
+package led
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Frame is what a board shows. The clocks are already formatted by the
+// display rules, so boards show the same text as the browser displays.
+type Frame struct {
+	GameClock        string
+	ShotClock        string
+	ScoreA           uint
+	ScoreB           uint
+	FoulA            uint
+	FoulB            uint
+	ShotClockRunning bool
+}
+
+// Driver pushes frames to one board. Send is only called from one goroutine
+// at a time.
+type Driver interface {
+	Name() string
+	Send(f Frame) error
+	Close() error
+}
+
+// Protocol is a controller's wire format. Decode reads the next frame,
+// skipping anything before it, so a monitor can check what a board receives.
+type Protocol interface {
+	Encode(f Frame, address int) []byte
+	Decode(r *bufio.Reader) (f Frame, address int, err error)
+}
+
+// Protocols by the names the configuration uses
+var Protocols = map[string]Protocol{
+	"ascii":    ASCII{},
+	"segments": Segments{},
+}
+
+// DefaultProtocol is used when none is configured
+const DefaultProtocol = "ascii"
+
+// ProtocolNames lists the protocol names, sorted
+func ProtocolNames() []string {
+	names := make([]string, 0, len(Protocols))
+	for name := range Protocols {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupProtocol returns the protocol called name
+func LookupProtocol(name string) (Protocol, error) {
+	p, ok := Protocols[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown LED protocol %q, want one of %s", name, strings.Join(ProtocolNames(), ", "))
+	}
+	return p, nil
+}
+
+// fit right-aligns s in width columns, keeping the rightmost columns of a
+// longer s, the way a fixed set of digits shows a number
+func fit(s string, width int) string {
+	if len(s) > width {
+		return s[len(s)-width:]
+	}
+	return strings.Repeat(" ", width-len(s)) + s
+}
@@ -0,0 +1,211 @@
+package led
+
+import (
+	"log/slog"
+	"scoreboard-backend/internal/models"
+	"scoreboard-backend/internal/services"
+	"sync"
+	"time"
+)
+
+// DefaultRefresh is how often an unchanged frame is sent again
+const DefaultRefresh = time.Second
+
+const resubscribeDelay = time.Second
+
+// Output keeps boards showing what the displays show. It joins the hub as a
+// client of its own, so it follows live games and playbacks alike, and
+// counts as a connected client. Each driver sends from its own goroutine, so
+// a slow or missing board holds up neither the hub nor the other boards.
+type Output struct {
+	websocketService *services.WebSocketService
+	control          *services.ControlService
+	refresh          time.Duration
+	boards           []*board
+	stop             chan struct{}
+	wg               sync.WaitGroup
+	mutex            sync.Mutex
+	frame            Frame
+}
+
+type board struct {
+	driver  Driver
+	changed chan struct{} // Holds at most one pending change
+	failing bool
+}
+
+func NewOutput(websocketService *services.WebSocketService, control *services.ControlService, drivers []Driver, refresh time.Duration) *Output {
+	if refresh <= 0 {
+		refresh = DefaultRefresh
+	}
+	o := &Output{
+		websocketService: websocketService,
+		control:          control,
+		refresh:          refresh,
+		stop:             make(chan struct{}),
+	}
+	for _, driver := range drivers {
+		o.boards = append(o.boards, &board{driver: driver, changed: make(chan struct{}, 1)})
+	}
+	return o
+}
+
+// Start subscribes to the broadcasts and starts sending to every board
+func (o *Output) Start() error {
+	client := o.subscribe()
+	o.wg.Add(1)
+	go o.follow(client)
+	for _, b := range o.boards {
+		o.wg.Add(1)
+		go o.drive(b)
+		slog.Info("Driving LED board", "board", b.driver.Name())
+	}
+	return nil
+}
+
+// Close unsubscribes, sends every board the final state and closes them
+func (o *Output) Close() error {
+	close(o.stop)
+	o.wg.Wait()
+	// The last broadcasts may not have arrived
+	o.mutex.Lock()
+	o.frame = o.currentFrame()
+	o.mutex.Unlock()
+	for _, b := range o.boards {
+		o.send(b)
+		b.driver.Close()
+	}
+	return nil
+}
+
+// subscribe joins the hub with a fresh client and starts from the current
+// state, since broadcasts only carry changes
+func (o *Output) subscribe() *models.Client {
+	client := o.websocketService.NewClient("led", models.EncodingJSON)
+	o.websocketService.RegisterClient(client)
+	o.mutex.Lock()
+	o.frame = o.currentFrame()
+	o.changed()
+	o.mutex.Unlock()
+	return client
+}
+
+// follow folds broadcasts into the frame. It owns the hub client, which only
+// it replaces and unregisters. The hub closes the client's queue when it
+// falls behind; the output then subscribes again.
+func (o *Output) follow(client *models.Client) {
+	defer o.wg.Done()
+	for {
+		if !o.receive(client) {
+			o.websocketService.UnregisterClient(client)
+			return
+		}
+		select {
+		case <-o.stop:
+			return
+		case <-time.After(resubscribeDelay):
+		}
+		client = o.subscribe()
+		slog.Warn("LED output fell behind the broadcasts, resubscribed")
+	}
+}
+
+// receive applies the client's broadcasts until the hub closes its queue, or
+// returns false once the output stops
+func (o *Output) receive(client *models.Client) bool {
+	for {
+		select {
+		case <-o.stop:
+			return false
+		case message, ok := <-client.Send:
+			if !ok {
+				return true
+			}
+			o.apply(message)
+		}
+	}
+}
+
+func (o *Output) apply(message models.WebSocketMessage) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	f := o.frame
+	switch data := message.Data.(type) {
+	case models.StateSyncData:
+		f = stateFrame(data.ScoreboardState, data.FormattedClocks)
+	case models.GameResetData:
+		f = stateFrame(data.ScoreboardState, data.FormattedClocks)
+	case models.ScoreUpdateData:
+		f.ScoreA, f.ScoreB = data.ScoreA, data.ScoreB
+	case models.FoulUpdateData:
+		f.FoulA, f.FoulB = data.FoulA, data.FoulB
+	case models.TimerUpdateData:
+		f.GameClock = data.FormattedTimer
+	case models.ShotClockUpdateData:
+		f.GameClock = data.FormattedTimer
+		f.ShotClock = data.FormattedShotclock
+		f.ShotClockRunning = data.IsShotClockRunning
+	}
+	if f != o.frame {
+		o.frame = f
+		o.changed()
+	}
+}
+
+// changed wakes every board. Call with the mutex held.
+func (o *Output) changed() {
+	for _, b := range o.boards {
+		select {
+		case b.changed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (o *Output) drive(b *board) {
+	defer o.wg.Done()
+	ticker := time.NewTicker(o.refresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-o.stop:
+			return
+		case <-b.changed:
+		case <-ticker.C:
+		}
+		o.send(b)
+	}
+}
+
+// send writes the latest frame, logging when a board starts and stops failing
+func (o *Output) send(b *board) {
+	o.mutex.Lock()
+	f := o.frame
+	o.mutex.Unlock()
+	err := b.driver.Send(f)
+	switch {
+	case err != nil && !b.failing:
+		b.failing = true
+		slog.Warn("LED board unavailable", "board", b.driver.Name(), "error", err)
+	case err == nil && b.failing:
+		b.failing = false
+		slog.Info("LED board recovered", "board", b.driver.Name())
+	}
+}
+
+func (o *Output) currentFrame() Frame {
+	state := o.control.StateSync()
+	return stateFrame(state.ScoreboardState, state.FormattedClocks)
+}
+
+func stateFrame(state models.ScoreboardState, clocks models.FormattedClocks) Frame {
+	return Frame{
+		GameClock:        clocks.FormattedTimer,
+		ShotClock:        clocks.FormattedShotclock,
+		ScoreA:           state.ScoreA,
+		ScoreB:           state.ScoreB,
+		FoulA:            state.FoulA,
+		FoulB:            state.FoulB,
+		ShotClockRunning: state.IsShotClockRunning,
+	}
+}
@@ -0,0 +1,101 @@
+package led
+
+import (
+	"context"
+	"scoreboard-backend/internal/clock"
+	"scoreboard-backend/internal/config"
+	"scoreboard-backend/internal/models"
+	"scoreboard-backend/internal/server"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordDriver keeps every frame it is sent
+type recordDriver struct {
+	mutex  sync.Mutex
+	frames []Frame
+	closed bool
+}
+
+func (d *recordDriver) Name() string { return "record" }
+
+func (d *recordDriver) Send(f Frame) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.frames = append(d.frames, f)
+	return nil
+}
+
+func (d *recordDriver) Close() error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.closed = true
+	return nil
+}
+
+func (d *recordDriver) last() (Frame, bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if len(d.frames) == 0 {
+		return Frame{}, d.closed
+	}
+	return d.frames[len(d.frames)-1], d.closed
+}
+
+func (d *recordDriver) waitFor(t *testing.T, want Frame) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got, _ := d.last()
+		if got == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("board shows %+v, want %+v", got, want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestOutputFollowsTheGame(t *testing.T) {
+	cfg := config.Default()
+	cfg.DataDir = t.TempDir()
+	srv, err := server.New(cfg, clock.NewFake(time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		srv.SaveState()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		srv.WebSocket.Shutdown(ctx)
+	})
+	actor := models.Actor{Source: models.SourceSystem}
+
+	d := &recordDriver{}
+	o := NewOutput(srv.WebSocket, srv.Control, []Driver{d}, time.Hour)
+	if err := o.Start(); err != nil {
+		t.Fatal(err)
+	}
+	want := Frame{GameClock: "10:00", ShotClock: "12.0"}
+	d.waitFor(t, want)
+
+	if _, err := srv.Control.AdjustScore(models.TeamA, 2, actor); err != nil {
+		t.Fatal(err)
+	}
+	want.ScoreA = 2
+	d.waitFor(t, want)
+
+	// Close sends the final state even if its broadcast has not arrived
+	if _, err := srv.Control.AdjustFoul(models.TeamB, 1, actor); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want.FoulB = 1
+	if got, closed := d.last(); got != want || !closed {
+		t.Errorf("after Close the board shows %+v, closed %v, want %+v, closed", got, closed, want)
+	}
+}
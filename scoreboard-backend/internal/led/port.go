@@ -0,0 +1,128 @@
+package led
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"scoreboard-backend/internal/clock"
+	"time"
+)
+
+// PortSimulated opens a pty instead of a device; its path is logged
+const PortSimulated = "pty"
+
+const (
+	writeTimeout = time.Second
+	reopenDelay  = 2 * time.Second
+)
+
+// Config is one board on one port
+type Config struct {
+	Port     string // Device path, e.g. /dev/ttyUSB0, or PortSimulated
+	Protocol string // A key of Protocols
+	Address  int    // Controller address, for boards sharing an RS-485 bus
+	Serial   SerialConfig
+}
+
+// PortDriver writes frames to a serial port. A device that fails, such as a
+// USB adapter pulled out, is reopened on a later Send. A frame the port
+// cannot take within a second is dropped; the next one replaces it anyway.
+type PortDriver struct {
+	config   Config
+	protocol Protocol
+	clock    clock.Clock
+	port     *os.File // nil while the device is unavailable
+	slave    *os.File // Held open for a simulated port
+	opened   time.Time
+}
+
+var errPortUnavailable = errors.New("port unavailable")
+
+// Validate checks the protocol, line settings and address
+func (c Config) Validate() error {
+	if _, err := LookupProtocol(c.Protocol); err != nil {
+		return err
+	}
+	if err := c.Serial.Validate(); err != nil {
+		return fmt.Errorf("led: %w", err)
+	}
+	if c.Address < 0 || c.Address > 99 {
+		return fmt.Errorf("led address must be 0-99, not %d", c.Address)
+	}
+	return nil
+}
+
+// Open opens the board's port, timing reopens on clk. A simulated port logs
+// the path to read the frames from.
+func Open(config Config, clk clock.Clock) (*PortDriver, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	protocol, err := LookupProtocol(config.Protocol)
+	if err != nil {
+		return nil, err
+	}
+	d := &PortDriver{config: config, protocol: protocol, clock: clk}
+	if config.Port == PortSimulated {
+		d.port, d.slave, err = openPTY()
+		if err != nil {
+			return nil, err
+		}
+		slog.Info("Simulated LED port ready", "path", d.slave.Name(), "protocol", config.Protocol)
+		return d, nil
+	}
+	if err := d.open(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Name is the port and protocol, for logs
+func (d *PortDriver) Name() string {
+	if d.slave != nil {
+		return fmt.Sprintf("%s (%s)", d.slave.Name(), d.config.Protocol)
+	}
+	return fmt.Sprintf("%s (%s)", d.config.Port, d.config.Protocol)
+}
+
+func (d *PortDriver) Send(f Frame) error {
+	if d.port == nil {
+		if d.clock.Since(d.opened) < reopenDelay {
+			return errPortUnavailable
+		}
+		if err := d.open(); err != nil {
+			return err
+		}
+	}
+	// The deadline is the kernel's, so it is on the system clock
+	if err := d.port.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil && !errors.Is(err, os.ErrNoDeadline) {
+		return err
+	}
+	_, err := d.port.Write(d.protocol.Encode(f, d.config.Address))
+	if err != nil && !errors.Is(err, os.ErrDeadlineExceeded) && d.slave == nil {
+		d.port.Close()
+		d.port = nil
+	}
+	return err
+}
+
+func (d *PortDriver) Close() error {
+	if d.slave != nil {
+		d.slave.Close()
+	}
+	if d.port == nil {
+		return nil
+	}
+	return d.port.Close()
+}
+
+func (d *PortDriver) open() error {
+	d.opened = d.clock.Now()
+	port, err := OpenSerial(d.config.Port, d.config.Serial)
+	if err != nil {
+		return err
+	}
+	d.port = port
+	return nil
+}
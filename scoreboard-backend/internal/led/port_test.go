@@ -0,0 +1,38 @@
+package led
+
+import (
+	"errors"
+	"path/filepath"
+	"scoreboard-backend/internal/clock"
+	"testing"
+	"time"
+)
+
+func TestPortReopenIsThrottled(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC))
+	d := &PortDriver{
+		config:   Config{Port: filepath.Join(t.TempDir(), "ttyUSB0"), Protocol: "ascii"},
+		protocol: ASCII{},
+		clock:    fake,
+	}
+	tries := []struct {
+		after  time.Duration
+		reopen bool
+	}{
+		{0, true}, // Never opened
+		{0, false},
+		{reopenDelay - time.Millisecond, false},
+		{time.Millisecond, true},
+		{time.Second, false},
+	}
+	for i, try := range tries {
+		fake.Advance(try.after)
+		err := d.Send(Frame{})
+		if err == nil {
+			t.Fatalf("send %d reached a missing device", i+1)
+		}
+		if reopened := !errors.Is(err, errPortUnavailable); reopened != try.reopen {
+			t.Errorf("send %d: %v, want reopen %v", i+1, err, try.reopen)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package led
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const segmentsSync = 0xAA
+
+// Digit windows of a segments frame, in wire order
+var segmentWindows = []int{
+	4, // Game clock
+	2, // Shot clock
+	3, // Score A
+	3, // Score B
+	2, // Foul A
+	2, // Foul B
+}
+
+const segmentDigits = 16
+
+// segmentDP is the decimal point. In the game clock the second digit's point
+// is wired as the colon, so mm:ss and ss.t both fit four digits.
+const segmentDP = 0x80
+
+// Segment patterns, bit 0 for segment a through bit 6 for g
+var segmentGlyphs = map[byte]byte{
+	'0': 0x3F, '1': 0x06, '2': 0x5B, '3': 0x4F, '4': 0x66,
+	'5': 0x6D, '6': 0x7D, '7': 0x07, '8': 0x7F, '9': 0x6F,
+	'-': 0x40, ' ': 0x00,
+}
+
+// Segments drives seven-segment digit boards that take the segments to light
+// rather than text:
+//
+//	0xAA, address, digit count (16), segment bytes, checksum
+//
+// Digits run game clock (4), shot clock (2), score A and B (3 each) and
+// fouls A and B (2 each), each right-aligned. A shot clock in tenths below
+// 10s keeps its tenth, and a longer one keeps its rightmost digits. The
+// checksum is the sum of every byte after the sync byte, modulo 256.
+type Segments struct{}
+
+func (Segments) Encode(f Frame, address int) []byte {
+	gameClock := f.GameClock
+	if strings.IndexByte(gameClock, ':') == 1 {
+		// Keep the colon on the second digit without leading zeros, 4:12
+		gameClock = " " + gameClock
+	}
+	frame := []byte{segmentsSync, byte(address), segmentDigits}
+	for i, text := range []string{
+		gameClock,
+		f.ShotClock,
+		strconv.FormatUint(uint64(f.ScoreA), 10),
+		strconv.FormatUint(uint64(f.ScoreB), 10),
+		strconv.FormatUint(uint64(f.FoulA), 10),
+		strconv.FormatUint(uint64(f.FoulB), 10),
+	} {
+		frame = append(frame, segmentCells(text, segmentWindows[i])...)
+	}
+	return append(frame, sumChecksum(frame[1:]))
+}
+
+// segmentCells lights text right-aligned in width digits. '.' and ':' light
+// the point of the digit before them.
+func segmentCells(text string, width int) []byte {
+	var cells []byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c == '.' || c == ':' {
+			if len(cells) == 0 {
+				cells = append(cells, 0)
+			}
+			cells[len(cells)-1] |= segmentDP
+			continue
+		}
+		cells = append(cells, segmentGlyphs[c])
+	}
+	if len(cells) > width {
+		return cells[len(cells)-width:]
+	}
+	return append(make([]byte, width-len(cells)), cells...)
+}
+
+func (Segments) Decode(r *bufio.Reader) (Frame, int, error) {
+	if err := skipTo(r, segmentsSync); err != nil {
+		return Frame{}, 0, err
+	}
+	body := make([]byte, 2+segmentDigits+1)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return Frame{}, 0, err
+	}
+	if body[1] != segmentDigits {
+		return Frame{}, 0, fmt.Errorf("segments frame: %d digits, want %d", body[1], segmentDigits)
+	}
+	if body[len(body)-1] != sumChecksum(body[:len(body)-1]) {
+		return Frame{}, 0, errors.New("segments frame: bad checksum")
+	}
+
+	cells := body[2 : 2+segmentDigits]
+	var text []string
+	for _, width := range segmentWindows {
+		text = append(text, segmentText(cells[:width], len(text) == 0))
+		cells = cells[width:]
+	}
+	f := Frame{GameClock: text[0], ShotClock: text[1]}
+	for i, dst := range []*uint{&f.ScoreA, &f.ScoreB, &f.FoulA, &f.FoulB} {
+		n, err := strconv.ParseUint(text[2+i], 10, 32)
+		if err != nil {
+			return Frame{}, 0, fmt.Errorf("segments frame: %w", err)
+		}
+		*dst = uint(n)
+	}
+	return f, int(body[0]), nil
+}
+
+// segmentText reads digits back as text. In the game clock a point on the
+// second digit is the colon.
+func segmentText(cells []byte, gameClock bool) string {
+	var b strings.Builder
+	for i, cell := range cells {
+		b.WriteByte(segmentChar(cell &^ segmentDP))
+		if cell&segmentDP != 0 {
+			if gameClock && i == 1 {
+				b.WriteByte(':')
+			} else {
+				b.WriteByte('.')
+			}
+		}
+	}
+	return strings.TrimLeft(b.String(), " ")
+}
+
+func segmentChar(glyph byte) byte {
+	for c, g := range segmentGlyphs {
+		if g == glyph {
+			return c
+		}
+	}
+	return '?'
+}
+
+func sumChecksum(data []byte) byte {
+	var sum byte
+	for _, b := range data {
+		sum += b
+	}
+	return sum
+}
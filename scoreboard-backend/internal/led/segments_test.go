@@ -0,0 +1,60 @@
+package led
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+func TestSegmentsEncode(t *testing.T) {
+	tests := []struct {
+		name    string
+		frame   Frame
+		address int
+		want    []byte
+		decoded Frame // What the board shows, where digits are lost
+	}{
+		{"colon and a shot clock in tenths", Frame{GameClock: "04:12", ShotClock: "08.0", ScoreA: 14, ScoreB: 101, FoulA: 3, FoulB: 5}, 7, []byte{
+			0xAA, 0x07, 16,
+			0x3F, 0xE6, 0x06, 0x5B, // 04:12
+			0xFF, 0x3F, // 8.0, keeping the tenth
+			0x00, 0x06, 0x66, // 14
+			0x06, 0x3F, 0x06, // 101
+			0x00, 0x4F, // 3
+			0x00, 0x6D, // 5
+			0x4E,
+		}, Frame{GameClock: "04:12", ShotClock: "8.0", ScoreA: 14, ScoreB: 101, FoulA: 3, FoulB: 5}},
+		{"last minute", Frame{GameClock: "59.9", ShotClock: "12"}, 0, []byte{
+			0xAA, 0x00, 16,
+			0x00, 0x6D, 0xEF, 0x6F, // 59.9
+			0x06, 0x5B, // 12
+			0x00, 0x00, 0x3F,
+			0x00, 0x00, 0x3F,
+			0x00, 0x3F,
+			0x00, 0x3F,
+			0x38,
+		}, Frame{GameClock: "59.9", ShotClock: "12"}},
+		{"no leading zeros", Frame{GameClock: "4:12", ShotClock: "8"}, 0, []byte{
+			0xAA, 0x00, 16,
+			0x00, 0xE6, 0x06, 0x5B, // 4:12, the colon still on the second digit
+			0x00, 0x7F, // 8
+			0x00, 0x00, 0x3F,
+			0x00, 0x00, 0x3F,
+			0x00, 0x3F,
+			0x00, 0x3F,
+			0xD2,
+		}, Frame{GameClock: "4:12", ShotClock: "8"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Segments{}.Encode(tt.frame, tt.address)
+			if !bytes.Equal(got, tt.want) {
+				t.Fatalf("Encode = % X, want % X", got, tt.want)
+			}
+			f, address, err := Segments{}.Decode(bufio.NewReader(bytes.NewReader(got)))
+			if err != nil || f != tt.decoded || address != tt.address {
+				t.Errorf("Decode = %+v, %d, %v, want %+v, %d", f, address, err, tt.decoded, tt.address)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package led
+
+import "fmt"
+
+// SerialConfig is the line setting of a serial port. Data bits are always 8.
+type SerialConfig struct {
+	Baud     int    // e.g. 9600
+	Parity   string // none, even or odd
+	StopBits int    // 1 or 2
+	RS485    bool   // Let the kernel drive RTS as the RS-485 transmit enable
+}
+
+// DefaultSerialConfig is 9600 8N1, what most controllers ship with
+func DefaultSerialConfig() SerialConfig {
+	return SerialConfig{Baud: 9600, Parity: "none", StopBits: 1}
+}
+
+// Validate rejects settings the port cannot be opened with
+func (c SerialConfig) Validate() error {
+	if _, ok := bauds[c.Baud]; !ok {
+		return fmt.Errorf("unsupported baud rate %d", c.Baud)
+	}
+	switch c.Parity {
+	case "none", "even", "odd":
+	default:
+		return fmt.Errorf("parity must be none, even or odd, not %q", c.Parity)
+	}
+	if c.StopBits != 1 && c.StopBits != 2 {
+		return fmt.Errorf("stop bits must be 1 or 2, not %d", c.StopBits)
+	}
+	return nil
+}
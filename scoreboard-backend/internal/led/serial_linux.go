@@ -0,0 +1,148 @@
+//go:build linux
+
+package led
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// Not exported by package syscall; the same on every Linux architecture
+// this runs on
+const (
+	cbaud   = 0x100f     // CBAUD from asm-generic/termbits.h
+	crtscts = 0x80000000 // CRTSCTS from asm-generic/termbits.h
+
+	tcflsh            = 0x540B // TCFLSH from asm-generic/ioctls.h
+	tiocsrs485        = 0x542F // TIOCSRS485 from asm-generic/ioctls.h
+	serRS485Enabled   = 1 << 0 // SER_RS485_ENABLED from linux/serial.h
+	serRS485RTSOnSend = 1 << 1 // SER_RS485_RTS_ON_SEND
+)
+
+var bauds = map[int]uint32{
+	1200:   syscall.B1200,
+	2400:   syscall.B2400,
+	4800:   syscall.B4800,
+	9600:   syscall.B9600,
+	19200:  syscall.B19200,
+	38400:  syscall.B38400,
+	57600:  syscall.B57600,
+	115200: syscall.B115200,
+	230400: syscall.B230400,
+}
+
+// serialRS485 is struct serial_rs485 from linux/serial.h
+type serialRS485 struct {
+	Flags              uint32
+	DelayRTSBeforeSend uint32
+	DelayRTSAfterSend  uint32
+	Padding            [5]uint32
+}
+
+// OpenSerial opens a serial port in raw mode with cfg's line settings,
+// discarding unread input. The file is left non-blocking, so write deadlines
+// work.
+func OpenSerial(path string, cfg SerialConfig) (*os.File, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := setRaw(f, cfg); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("configure %s: %w", path, err)
+	}
+	// Drop anything received before now, such as frames a simulated port
+	// queued with nobody reading; the argument is TCIFLUSH, which is 0
+	if err := ioctl(f, tcflsh, nil); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("flush %s: %w", path, err)
+	}
+	if cfg.RS485 {
+		rs485 := serialRS485{Flags: serRS485Enabled | serRS485RTSOnSend}
+		if err := ioctl(f, tiocsrs485, unsafe.Pointer(&rs485)); err != nil {
+			// USB adapters with automatic direction control need nothing
+			slog.Warn("Serial port has no kernel RS-485 mode", "port", path, "error", err)
+		}
+	}
+	return f, nil
+}
+
+// setRaw sets the port up like cfmakeraw with cfg's line settings
+func setRaw(f *os.File, cfg SerialConfig) error {
+	var t syscall.Termios
+	if err := ioctl(f, syscall.TCGETS, unsafe.Pointer(&t)); err != nil {
+		return err
+	}
+	speed := bauds[cfg.Baud]
+	t.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP |
+		syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON | syscall.IXOFF | syscall.IXANY
+	t.Oflag &^= syscall.OPOST
+	t.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	t.Cflag &^= syscall.CSIZE | syscall.PARENB | syscall.PARODD | syscall.CSTOPB | cbaud | crtscts
+	t.Cflag |= syscall.CS8 | syscall.CREAD | syscall.CLOCAL | speed
+	switch cfg.Parity {
+	case "even":
+		t.Cflag |= syscall.PARENB
+	case "odd":
+		t.Cflag |= syscall.PARENB | syscall.PARODD
+	}
+	if cfg.StopBits == 2 {
+		t.Cflag |= syscall.CSTOPB
+	}
+	t.Ispeed, t.Ospeed = speed, speed
+	t.Cc[syscall.VMIN] = 1
+	t.Cc[syscall.VTIME] = 0
+	return ioctl(f, syscall.TCSETS, unsafe.Pointer(&t))
+}
+
+// openPTY opens a simulated serial port. Frames written to the returned
+// master can be read from the slave's /dev/pts path, which the returned
+// slave keeps open so the pty stays up between readers.
+func openPTY() (master, slave *os.File, err error) {
+	master, err = os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	var unlock int32
+	var n uint32
+	if err := ioctl(master, syscall.TIOCSPTLCK, unsafe.Pointer(&unlock)); err != nil {
+		master.Close()
+		return nil, nil, fmt.Errorf("unlock pty: %w", err)
+	}
+	if err := ioctl(master, syscall.TIOCGPTN, unsafe.Pointer(&n)); err != nil {
+		master.Close()
+		return nil, nil, fmt.Errorf("pty number: %w", err)
+	}
+	// Raw, or the line discipline echoes frames back and rewrites bytes
+	slave, err = OpenSerial(fmt.Sprintf("/dev/pts/%d", n), DefaultSerialConfig())
+	if err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+	return master, slave, nil
+}
+
+// ioctl runs on the raw descriptor without f.Fd(), which would switch the
+// file to blocking mode and disable deadlines
+func ioctl(f *os.File, req uintptr, arg unsafe.Pointer) error {
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var errno syscall.Errno
+	if err := conn.Control(func(fd uintptr) {
+		_, _, errno = syscall.Syscall(syscall.SYS_IOCTL, fd, req, uintptr(arg))
+	}); err != nil {
+		return err
+	}
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+//go:build !linux
+
+package led
+
+import (
+	"errors"
+	"os"
+)
+
+var bauds = map[int]uint32{1200: 0, 2400: 0, 4800: 0, 9600: 0, 19200: 0, 38400: 0, 57600: 0, 115200: 0, 230400: 0}
+
+// OpenSerial is only supported on Linux
+func OpenSerial(path string, cfg SerialConfig) (*os.File, error) {
+	return nil, errors.New("serial ports are only supported on linux")
+}
+
+// openPTY is only supported on Linux
+func openPTY() (master, slave *os.File, err error) {
+	return nil, nil, errors.New("simulated serial ports are only supported on linux")
+}
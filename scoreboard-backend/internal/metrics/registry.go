@@ -0,0 +1,277 @@
+// Package metrics exposes scoreboard and hub health in the Prometheus text
+// exposition format. It is a small stdlib-only implementation of counters,
+// gauges and histograms with labels.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type collector interface {
+	write(w io.Writer)
+}
+
+// Registry holds every collector exposed on /metrics
+type Registry struct {
+	mutex      sync.Mutex
+	collectors []collector
+}
+
+func (r *Registry) register(c collector) {
+	r.mutex.Lock()
+	r.collectors = append(r.collectors, c)
+	r.mutex.Unlock()
+}
+
+// Handler serves the registry in the Prometheus text format
+func (r *Registry) Handler() http.Handler {
+	return Handler(r)
+}
+
+// Handler serves several registries as one exposition, in order
+func Handler(registries ...*Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		for _, r := range registries {
+			r.write(w)
+		}
+	})
+}
+
+// write writes every collector in the Prometheus text format
+func (r *Registry) write(w io.Writer) {
+	r.mutex.Lock()
+	collectors := append([]collector(nil), r.collectors...)
+	r.mutex.Unlock()
+	for _, c := range collectors {
+		c.write(w)
+	}
+}
+
+// Default is the registry served by the backend
+var Default = &Registry{}
+
+// series is the shared label bookkeeping for vector metrics
+type series struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+	mutex  sync.Mutex
+	keys   []string // insertion order of label value sets
+	index  map[string][]string
+}
+
+func newSeries(name, help, kind string, labels []string) series {
+	return series{name: name, help: help, kind: kind, labels: labels, index: map[string][]string{}}
+}
+
+// key joins label values; callers hold the mutex
+func (s *series) key(values []string) string {
+	if len(values) != len(s.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", s.name, len(s.labels), len(values)))
+	}
+	k := strings.Join(values, "\xff")
+	if _, ok := s.index[k]; !ok {
+		s.index[k] = append([]string(nil), values...)
+		s.keys = append(s.keys, k)
+	}
+	return k
+}
+
+func (s *series) header(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", s.name, helpEscaper.Replace(s.help), s.name, s.kind)
+}
+
+// sortedKeys returns label sets in a stable order; callers hold the mutex
+func (s *series) sortedKeys() []string {
+	keys := append([]string(nil), s.keys...)
+	sort.Strings(keys)
+	return keys
+}
+
+func labelString(names, values []string, extra ...string) string {
+	if len(names) == 0 && len(extra) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=\"%s\"", name, escape(values[i]))
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=\"%s\"", extra[i], escape(extra[i+1]))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+var (
+	escaper     = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`) // HELP text keeps its quotes
+)
+
+func escape(v string) string {
+	return escaper.Replace(v)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// CounterVec is a monotonically increasing value per label set
+type CounterVec struct {
+	series
+	values map[string]float64
+}
+
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{series: newSeries(name, help, "counter", labels), values: map[string]float64{}}
+	r.register(c)
+	return c
+}
+
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	c.mutex.Lock()
+	c.values[c.key(labelValues)] += delta
+	c.mutex.Unlock()
+}
+
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *CounterVec) write(w io.Writer) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.header(w)
+	for _, k := range c.sortedKeys() {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, labelString(c.labels, c.index[k]), formatFloat(c.values[k]))
+	}
+}
+
+// GaugeVec is a value per label set that can go up and down
+type GaugeVec struct {
+	series
+	values map[string]float64
+}
+
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{series: newSeries(name, help, "gauge", labels), values: map[string]float64{}}
+	r.register(g)
+	return g
+}
+
+func (g *GaugeVec) Set(v float64, labelValues ...string) {
+	g.mutex.Lock()
+	g.values[g.key(labelValues)] = v
+	g.mutex.Unlock()
+}
+
+func (g *GaugeVec) write(w io.Writer) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.header(w)
+	for _, k := range g.sortedKeys() {
+		fmt.Fprintf(w, "%s%s %s\n", g.name, labelString(g.labels, g.index[k]), formatFloat(g.values[k]))
+	}
+}
+
+// GaugeFunc is a gauge whose value is read when /metrics is scraped
+type GaugeFunc struct {
+	series
+	values []string
+	fn     func() float64
+}
+
+// NewGaugeFunc registers fn under name with fixed label pairs ("court", "1", ...)
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64, labelPairs ...string) *GaugeFunc {
+	var names, values []string
+	for i := 0; i+1 < len(labelPairs); i += 2 {
+		names = append(names, labelPairs[i])
+		values = append(values, labelPairs[i+1])
+	}
+	g := &GaugeFunc{series: newSeries(name, help, "gauge", names), values: values, fn: fn}
+	r.register(g)
+	return g
+}
+
+func (g *GaugeFunc) write(w io.Writer) {
+	g.header(w)
+	fmt.Fprintf(w, "%s%s %s\n", g.name, labelString(g.labels, g.values), formatFloat(g.fn()))
+}
+
+// HistogramVec counts observations into cumulative buckets per label set
+type HistogramVec struct {
+	series
+	buckets []float64
+	counts  map[string][]uint64
+	sums    map[string]float64
+	totals  map[string]uint64
+}
+
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{
+		series:  newSeries(name, help, "histogram", labels),
+		buckets: buckets,
+		counts:  map[string][]uint64{},
+		sums:    map[string]float64{},
+		totals:  map[string]uint64{},
+	}
+	r.register(h)
+	return h
+}
+
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	k := h.key(labelValues)
+	counts, ok := h.counts[k]
+	if !ok {
+		counts = make([]uint64, len(h.buckets))
+		h.counts[k] = counts
+	}
+	for i, upper := range h.buckets {
+		if v <= upper {
+			counts[i]++
+		}
+	}
+	h.sums[k] += v
+	h.totals[k]++
+}
+
+func (h *HistogramVec) write(w io.Writer) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.header(w)
+	for _, k := range h.sortedKeys() {
+		values := h.index[k]
+		for i, upper := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, labelString(h.labels, values, "le", formatFloat(upper)), h.counts[k][i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, labelString(h.labels, values, "le", "+Inf"), h.totals[k])
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, labelString(h.labels, values), formatFloat(h.sums[k]))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, labelString(h.labels, values), h.totals[k])
+	}
+}
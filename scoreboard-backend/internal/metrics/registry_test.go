@@ -0,0 +1,110 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func scrape(t *testing.T, registries ...*Registry) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	Handler(registries...).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != "text/plain; version=0.0.4; charset=utf-8" {
+		t.Errorf("Content-Type %q", ct)
+	}
+	body, _ := io.ReadAll(rec.Body)
+	return string(body)
+}
+
+func TestCounterExposition(t *testing.T) {
+	r := &Registry{}
+	c := r.NewCounterVec("test_requests_total", "Requests, by route.", "route")
+	c.Inc("/b")
+	c.Add(2.5, "/a")
+	c.Inc("/b")
+
+	want := `# HELP test_requests_total Requests, by route.
+# TYPE test_requests_total counter
+test_requests_total{route="/a"} 2.5
+test_requests_total{route="/b"} 2
+`
+	if got := scrape(t, r); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestLabelAndHelpEscaping(t *testing.T) {
+	r := &Registry{}
+	g := r.NewGaugeVec("test_gauge", "Help with a \\ backslash,\na newline and \"quotes\".", "name")
+	g.Set(-1, `back\slash "quoted"`+"\nnext line")
+
+	want := `# HELP test_gauge Help with a \\ backslash,\na newline and "quotes".
+# TYPE test_gauge gauge
+test_gauge{name="back\\slash \"quoted\"\nnext line"} -1
+`
+	if got := scrape(t, r); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGaugeFuncExposition(t *testing.T) {
+	r := &Registry{}
+	value := 3.0
+	r.NewGaugeFunc("test_clients", "Connected clients.", func() float64 { return value })
+	r.NewGaugeFunc("test_clock_tenths", "Clock.", func() float64 { return 1200 }, "court", `c"1`)
+
+	value = 7
+	want := `# HELP test_clients Connected clients.
+# TYPE test_clients gauge
+test_clients 7
+# HELP test_clock_tenths Clock.
+# TYPE test_clock_tenths gauge
+test_clock_tenths{court="c\"1"} 1200
+`
+	if got := scrape(t, r); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHistogramExposition(t *testing.T) {
+	r := &Registry{}
+	h := r.NewHistogramVec("test_seconds", "Latency.", []float64{0.1, 1}, "clock")
+	h.Observe(0.05, "game")
+	h.Observe(0.5, "game")
+	h.Observe(5, "game")
+
+	want := `# HELP test_seconds Latency.
+# TYPE test_seconds histogram
+test_seconds_bucket{clock="game",le="0.1"} 1
+test_seconds_bucket{clock="game",le="1"} 2
+test_seconds_bucket{clock="game",le="+Inf"} 3
+test_seconds_sum{clock="game"} 5.55
+test_seconds_count{clock="game"} 3
+`
+	if got := scrape(t, r); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHandlerServesRegistriesInOrder(t *testing.T) {
+	first, second := &Registry{}, &Registry{}
+	second.NewGaugeFunc("test_second", "Second.", func() float64 { return 2 })
+	first.NewGaugeFunc("test_first", "First.", func() float64 { return 1 })
+
+	got := scrape(t, first, second)
+	if i, j := strings.Index(got, "test_first 1"), strings.Index(got, "test_second 2"); i < 0 || j < i {
+		t.Errorf("registries out of order:\n%s", got)
+	}
+}
+
+func TestWrongLabelCountPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic for a missing label value")
+		}
+	}()
+	r := &Registry{}
+	r.NewCounterVec("test_total", "Total.", "a", "b").Inc("only-one")
+}
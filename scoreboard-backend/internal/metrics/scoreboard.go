@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	latencyBuckets = []float64{0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1}
+	jitterBuckets  = []float64{0.0005, 0.001, 0.002, 0.005, 0.01, 0.02, 0.05, 0.1}
+)
+
+// WebSocket hub
+var (
+	BroadcastsTotal = Default.NewCounterVec("scoreboard_websocket_broadcasts_total",
+		"WebSocket messages broadcast, by message type.", "type")
+	BroadcastDuration = Default.NewHistogramVec("scoreboard_websocket_broadcast_duration_seconds",
+		"Time from BroadcastMessage until the message is queued for every client.", latencyBuckets)
+	SlowClientsTotal = Default.NewCounterVec("scoreboard_websocket_slow_clients_total",
+		"Clients whose send queue was full, by the action taken.", "action")
+)
+
+// REST API
+var (
+	HTTPRequestsTotal = Default.NewCounterVec("scoreboard_http_requests_total",
+		"HTTP requests, by method, route and status.", "method", "route", "status")
+	HTTPRequestDuration = Default.NewHistogramVec("scoreboard_http_request_duration_seconds",
+		"HTTP request latency, by method and route.", latencyBuckets, "method", "route")
+)
+
+// Clocks
+var (
+	ClockTickJitter = Default.NewHistogramVec("scoreboard_clock_tick_jitter_seconds",
+		"Absolute difference between each clock tick interval and the nominal interval.", jitterBuckets, "clock")
+	ClockDrift = Default.NewGaugeVec("scoreboard_clock_drift_seconds",
+		"Wall-clock time since the clock last started minus the time it counted. Positive means the clock runs slow.", "clock")
+)
+
+// GinMiddleware counts requests and latency by route template, so
+// /api/games/1 and /api/games/2 share one series.
+func GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		method := c.Request.Method
+		HTTPRequestsTotal.Inc(method, route, strconv.Itoa(c.Writer.Status()))
+		HTTPRequestDuration.Observe(time.Since(start).Seconds(), method, route)
+	}
+}
+
+// TickTracker records jitter and drift for one run of a clock ticker. It is
+// not safe for concurrent use; each ticking goroutine owns its tracker.
+type TickTracker struct {
+	clock    string
+	interval time.Duration
+	start    time.Time
+	last     time.Time
+	ticks    int
+}
+
+// NewTickTracker starts tracking a clock that should tick every interval,
+// started at start
+func NewTickTracker(clock string, interval time.Duration, start time.Time) *TickTracker {
+	return &TickTracker{clock: clock, interval: interval, start: start, last: start}
+}
+
+// Tick records one tick observed at now
+func (t *TickTracker) Tick(now time.Time) {
+	jitter := now.Sub(t.last) - t.interval
+	if jitter < 0 {
+		jitter = -jitter
+	}
+	t.last = now
+	t.ticks++
+	ClockTickJitter.Observe(jitter.Seconds(), t.clock)
+	counted := time.Duration(t.ticks) * t.interval
+	ClockDrift.Set((now.Sub(t.start) - counted).Seconds(), t.clock)
+}
@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+// Event types. Each one records the result of a command, so folding a game's
+// events in order always gives the same state.
+const (
+	EventGameStarted       = "game_started"        // State is the starting state
+	EventStateRestored     = "state_restored"      // State replaces everything, clocks stopped
+	EventScoreAdjusted     = "score_adjusted"      // Team's score is now Value
+	EventScoreSet          = "score_set"           // Team's score was overwritten with Value
+	EventFoulAdjusted      = "foul_adjusted"       // Team's foul count is now Value
+	EventFoulSet           = "foul_set"            // Team's foul count was overwritten with Value
+	EventTimerSet          = "timer_set"           // Game clock set to Value tenths
+	EventTimerAdjusted     = "timer_adjusted"      // Game clock corrected to Value tenths
+	EventTimerReset        = "timer_reset"         // Game clock back to the profile's Value tenths
+	EventTimerTicked       = "timer_ticked"        // Game clock ran down one tenth
+	EventShotClockSet      = "shot_clock_set"      // Shot clock set to Value tenths
+	EventShotClockAdjusted = "shot_clock_adjusted" // Shot clock corrected to Value tenths
+	EventShotClockReset    = "shot_clock_reset"    // Shot clock back to the profile's Value tenths
+	EventShotClockTicked   = "shot_clock_ticked"   // Shot clock ran down one tenth
+	EventShotClockStarted  = "shot_clock_started"
+	EventShotClockStopped  = "shot_clock_stopped"
+)
+
+// Event is one entry in a game's event log
+type Event struct {
+	Seq   uint64           `json:"seq"` // 1-based position in the game
+	Time  time.Time        `json:"time"`
+	Type  string           `json:"type"`
+	Team  string           `json:"team,omitempty"`
+	Value int              `json:"value,omitempty"`
+	State *ScoreboardState `json:"state,omitempty"`
+}
+
+// Apply returns state with the event folded in
+func (e Event) Apply(state ScoreboardState) ScoreboardState {
+	switch e.Type {
+	case EventGameStarted, EventStateRestored:
+		if e.State != nil {
+			state = *e.State
+		}
+		if e.Type == EventStateRestored {
+			state.IsShotClockRunning = false
+		}
+	case EventScoreAdjusted, EventScoreSet:
+		if e.Team == TeamB {
+			state.ScoreB = uint(e.Value)
+		} else {
+			state.ScoreA = uint(e.Value)
+		}
+	case EventFoulAdjusted, EventFoulSet:
+		if e.Team == TeamB {
+			state.FoulB = uint(e.Value)
+		} else {
+			state.FoulA = uint(e.Value)
+		}
+	case EventTimerSet, EventTimerAdjusted, EventTimerReset:
+		state.TimerTenths = e.Value
+	case EventTimerTicked:
+		if state.TimerTenths > 0 {
+			state.TimerTenths--
+		}
+	case EventShotClockSet, EventShotClockAdjusted, EventShotClockReset:
+		state.ShotClockTenths = e.Value
+	case EventShotClockTicked:
+		if state.ShotClockTenths > 0 {
+			state.ShotClockTenths--
+		}
+	case EventShotClockStarted:
+		state.IsShotClockRunning = true
+	case EventShotClockStopped:
+		state.IsShotClockRunning = false
+	}
+	return state
+}
@@ -0,0 +1,163 @@
+package models
+
+import "encoding/json"
+
+// ProtocolVersion is stamped on every WebSocket envelope. Bump it when a
+// payload changes in a way old clients cannot ignore.
+const ProtocolVersion = 1
+
+// Team identifiers used in payloads
+const (
+	TeamA = "A"
+	TeamB = "B"
+)
+
+// Payload is implemented by every typed WebSocket message body.
+type Payload interface {
+	MessageType() string
+}
+
+// WebSocketMessage is the versioned envelope for server-to-client messages
+type WebSocketMessage struct {
+	Version int     `json:"v"`
+	Type    string  `json:"type"`
+	Data    Payload `json:"data"`
+}
+
+// NewMessage wraps a payload in an envelope with its type and the current protocol version
+func NewMessage(data Payload) WebSocketMessage {
+	return WebSocketMessage{
+		Version: ProtocolVersion,
+		Type:    data.MessageType(),
+		Data:    data,
+	}
+}
+
+// InboundMessage is the envelope for client-to-server messages. Data is
+// decoded into the payload type matching Type by the receiver.
+type InboundMessage struct {
+	Version int             `json:"v,omitempty"`
+	Type    string          `json:"type"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// Server-to-client payloads
+
+// FormattedClocks are both clocks as the display should show them, following
+// the backend's display rules, so a display need not format tenths itself
+type FormattedClocks struct {
+	FormattedTimer     string `json:"formattedTimer"`
+	FormattedShotclock string `json:"formattedShotclock"`
+}
+
+// StateSyncData carries the full scoreboard state as of one version, and the
+// review in progress so a display connecting mid-review shows it. A client
+// can drop a state_sync older than the last one it applied.
+type StateSyncData struct {
+	ScoreboardState
+	FormattedClocks
+	Version uint64            `json:"version"`
+	Review  *ReviewUpdateData `json:"review,omitempty"`
+}
+
+// GameResetData carries the full scoreboard state after a reset
+type GameResetData struct {
+	ScoreboardState
+	FormattedClocks
+}
+
+// TimerUpdateData is sent when the game clock is set or reset
+type TimerUpdateData struct {
+	TimerTenths    int    `json:"timerTenths"`
+	FormattedTimer string `json:"formattedTimer"`
+}
+
+// ScoreUpdateData is sent when either team's score changes. Both scores are
+// always included; Team names the side that changed.
+type ScoreUpdateData struct {
+	Team   string `json:"team" enum:"A,B"`
+	ScoreA uint   `json:"scoreA"`
+	ScoreB uint   `json:"scoreB"`
+}
+
+// FoulUpdateData is sent when either team's foul count changes. Both counts
+// are always included; Team names the side that changed.
+type FoulUpdateData struct {
+	Team  string `json:"team" enum:"A,B"`
+	FoulA uint   `json:"foulA"`
+	FoulB uint   `json:"foulB"`
+}
+
+// ShotClockUpdateData is sent on every shot clock tick and state change
+type ShotClockUpdateData struct {
+	ShotClockTenths    int  `json:"shotClockTenths"`
+	IsShotClockRunning bool `json:"isShotClockRunning"`
+	TimerTenths        int  `json:"timerTenths"`
+	FormattedClocks
+}
+
+// ServerShutdownData is the last message before the server closes every
+// connection. Displays should show that they are reconnecting.
+type ServerShutdownData struct {
+	Reason string `json:"reason"`
+}
+
+// Review statuses
+const (
+	ReviewStatusLive   = "live"   // Normal play
+	ReviewStatusReview = "review" // Clocks frozen for an instant replay review
+)
+
+// ReviewUpdateData is sent when an instant replay review starts or ends.
+// Displays should show the review status while Status is "review".
+type ReviewUpdateData struct {
+	Status    string `json:"status" enum:"live,review"`
+	Reason    string `json:"reason,omitempty"`
+	Outcome   string `json:"outcome,omitempty"`   // Set when the review ends
+	RewoundTo uint64 `json:"rewoundTo,omitempty"` // Seq of the game event the clocks and score were rewound to
+}
+
+func (StateSyncData) MessageType() string       { return "state_sync" }
+func (GameResetData) MessageType() string       { return "game_reset" }
+func (TimerUpdateData) MessageType() string     { return "timer_update" }
+func (ScoreUpdateData) MessageType() string     { return "score_update" }
+func (FoulUpdateData) MessageType() string      { return "foul_update" }
+func (ShotClockUpdateData) MessageType() string { return "shotclock_update" }
+func (ServerShutdownData) MessageType() string  { return "server_shutdown" }
+func (ReviewUpdateData) MessageType() string    { return "review_update" }
+
+// Client-to-server payloads
+
+// TimerControlData represents timer control actions
+type TimerControlData struct {
+	Action string `json:"action" enum:"start,stop"`
+}
+
+// ScoreSetData sets one team's score from a client. It is audited as a
+// score override; Reason is recorded with it.
+type ScoreSetData struct {
+	Team   string `json:"team" enum:"A,B"`
+	Score  uint   `json:"score"`
+	Reason string `json:"reason,omitempty"`
+}
+
+func (TimerControlData) MessageType() string { return "timer_control" }
+func (ScoreSetData) MessageType() string     { return "score_update" }
+
+// ServerMessages lists one zero value of every server-to-client payload
+var ServerMessages = []Payload{
+	StateSyncData{},
+	GameResetData{},
+	TimerUpdateData{},
+	ScoreUpdateData{},
+	FoulUpdateData{},
+	ShotClockUpdateData{},
+	ServerShutdownData{},
+	ReviewUpdateData{},
+}
+
+// ClientMessages lists one zero value of every client-to-server payload
+var ClientMessages = []Payload{
+	TimerControlData{},
+	ScoreSetData{},
+}
@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"log/slog"
+	"time"
+)
 
 // ScoreboardState represents the current state of the scoreboard
 type ScoreboardState struct {
@@ -13,27 +17,18 @@ type ScoreboardState struct {
 	IsShotClockRunning bool `json:"isShotClockRunning"` // Shot clock running status
 }
 
-// WebSocketMessage represents a WebSocket message
-type WebSocketMessage struct {
-	Type string      `json:"type"`
-	Data interface{} `json:"data"`
-}
-
-// TimerControlData represents timer control actions
-type TimerControlData struct {
-	Action string `json:"action"` // "start" or "stop"
-}
-
-// ScoreUpdateData represents score update data
-type ScoreUpdateData struct {
-	Score uint `json:"score"`
-}
+// Frame encodings a WebSocket client can negotiate
+const (
+	EncodingJSON    = "json"    // Every message as a JSON text frame (default)
+	EncodingCompact = "compact" // Clock updates as binary frames, everything else JSON
+)
 
 // Client represents a WebSocket client
 type Client struct {
-	ID   string
-	Conn interface{} // WebSocket connection (interface for flexibility)
-	Send chan WebSocketMessage
+	ID       string
+	Conn     interface{} // WebSocket connection (interface for flexibility)
+	Send     chan WebSocketMessage
+	Encoding string // EncodingJSON or EncodingCompact
 }
 
 // TimerState represents the internal timer state
@@ -42,3 +37,68 @@ type TimerState struct {
 	ElapsedSeconds int
 	IsRunning      bool
 }
+
+// Actor sources
+const (
+	SourceREST      = "rest"
+	SourceWebSocket = "ws"
+	SourceRemote    = "remote"
+	SourceInput     = "input"
+	SourceSystem    = "system" // The server itself, e.g. stopping clocks at shutdown
+)
+
+// Actor identifies who made a change and through which request
+type Actor struct {
+	Source    string `json:"source"`              // SourceREST, SourceWebSocket, SourceRemote, SourceInput or SourceSystem
+	ID        string `json:"id,omitempty"`        // Device or operator ID, empty for anonymous callers
+	RequestID string `json:"requestId,omitempty"` // HTTP request or WebSocket connection ID
+	ClientIP  string `json:"clientIp,omitempty"`  // Address the change came from
+	Route     string `json:"route,omitempty"`     // REST route, WebSocket message type, remote transport or input device
+	Reason    string `json:"reason,omitempty"`    // Operator's explanation, recorded for audited actions
+}
+
+// LogValue groups the non-empty fields for structured logs
+func (a Actor) LogValue() slog.Value {
+	attrs := []slog.Attr{slog.String("source", a.Source)}
+	for _, f := range []struct{ key, value string }{
+		{"id", a.ID},
+		{"requestId", a.RequestID},
+		{"clientIp", a.ClientIP},
+		{"route", a.Route},
+		{"reason", a.Reason},
+	} {
+		if f.value != "" {
+			attrs = append(attrs, slog.String(f.key, f.value))
+		}
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// Audit actions
+const (
+	AuditGameReset     = "game_reset"
+	AuditGameRestore   = "game_restore"
+	AuditTimerSet      = "timer_set"
+	AuditTimerReset    = "timer_reset"
+	AuditShotClockSet  = "shotclock_set"
+	AuditScoreOverride = "score_override"
+	AuditScoreCorrect  = "score_correction"
+	AuditFoulCorrect   = "foul_correction"
+	AuditClockCorrect  = "clock_correction"
+	AuditReviewStart   = "review_start"
+	AuditReviewRewind  = "review_rewind"
+	AuditReviewEnd     = "review_end"
+	AuditConfigChange  = "config_change"
+)
+
+// AuditEntry is one administrative or corrective action in the audit log
+type AuditEntry struct {
+	Seq     int64           `json:"seq"`               // Position in the log, starting at 1
+	Time    time.Time       `json:"time"`              // When the action was applied
+	Action  string          `json:"action"`            // One of the Audit* actions
+	Actor   Actor           `json:"actor"`             // Who did it
+	Reason  string          `json:"reason,omitempty"`  // Free text given by the operator
+	Before  ScoreboardState `json:"before"`            // Full state before the action
+	After   ScoreboardState `json:"after"`             // Full state after the action
+	Details json.RawMessage `json:"details,omitempty"` // Action specific data, e.g. the new configuration
+}
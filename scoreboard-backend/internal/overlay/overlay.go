@@ -0,0 +1,94 @@
+// Package overlay serves broadcast graphics: a transparent HTML page for OBS
+// browser sources, driven by the WebSocket messages, and a flat data feed
+// for vMix and CasparCG data sources.
+package overlay
+
+import (
+	_ "embed"
+	"scoreboard-backend/internal/format"
+	"scoreboard-backend/internal/models"
+	"scoreboard-backend/internal/services"
+	"strconv"
+)
+
+//go:embed overlay.html
+var page []byte
+
+// Page is the overlay HTML. It reads the layout and team names from its own
+// query string and connects to /ws/state on the host that served it.
+func Page() []byte { return page }
+
+// Layouts the page can draw
+const (
+	LayoutBug        = "bug"         // Compact corner score bug
+	LayoutFull       = "full"        // Full scoreboard with fouls
+	LayoutLowerThird = "lower-third" // Wide strip along the bottom
+)
+
+// Layouts lists every layout, default first
+var Layouts = []string{LayoutBug, LayoutFull, LayoutLowerThird}
+
+// ValidLayout reports whether the page can draw layout
+func ValidLayout(layout string) bool {
+	for _, l := range Layouts {
+		if layout == l {
+			return true
+		}
+	}
+	return false
+}
+
+// Default team names, overridden by ?teamA= and ?teamB=
+const (
+	DefaultTeamA = "Team A"
+	DefaultTeamB = "Team B"
+)
+
+// Data is one flat record of display-ready strings. Graphics systems bind
+// fields by name, so there is no nesting and nothing left to format.
+type Data struct {
+	TeamA            string `json:"teamA"`
+	TeamB            string `json:"teamB"`
+	ScoreA           string `json:"scoreA"`
+	ScoreB           string `json:"scoreB"`
+	FoulA            string `json:"foulA"`
+	FoulB            string `json:"foulB"`
+	GameClock        string `json:"gameClock"`        // By the display rules, e.g. mm:ss or ss.t
+	ShotClock        string `json:"shotClock"`        // By the display rules, e.g. ss or s.t
+	ShotClockRunning string `json:"shotClockRunning"` // "true" or "false"
+	Status           string `json:"status"`           // "live" or "review"
+	Review           string `json:"review"`           // "REVIEW" during a review, otherwise empty
+	Playback         string `json:"playback"`         // "PLAYBACK" while a past game is played back, otherwise empty
+	GameID           string `json:"gameId"`
+	Version          string `json:"version"`
+}
+
+// NewData formats a state sync for the data feed by the display rules. With
+// playback set, the state is the played back game's.
+func NewData(sync models.StateSyncData, gameID, teamA, teamB string, playback *services.Playback, display format.Rules) Data {
+	state := sync.ScoreboardState
+	data := Data{
+		TeamA:            teamA,
+		TeamB:            teamB,
+		Status:           models.ReviewStatusLive,
+		GameID:           gameID,
+		Version:          strconv.FormatUint(sync.Version, 10),
+		ShotClockRunning: strconv.FormatBool(state.IsShotClockRunning),
+	}
+	if sync.Review != nil && sync.Review.Status == models.ReviewStatusReview {
+		data.Status = models.ReviewStatusReview
+		data.Review = "REVIEW"
+	}
+	if playback != nil {
+		state = playback.State
+		data.ShotClockRunning = strconv.FormatBool(state.IsShotClockRunning)
+		data.Playback = "PLAYBACK"
+	}
+	data.ScoreA = strconv.FormatUint(uint64(state.ScoreA), 10)
+	data.ScoreB = strconv.FormatUint(uint64(state.ScoreB), 10)
+	data.FoulA = strconv.FormatUint(uint64(state.FoulA), 10)
+	data.FoulB = strconv.FormatUint(uint64(state.FoulB), 10)
+	data.GameClock = display.GameClock(state.TimerTenths)
+	data.ShotClock = display.ShotClock(state.ShotClockTenths)
+	return data
+}
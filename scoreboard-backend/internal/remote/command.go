@@ -0,0 +1,161 @@
+// Package remote lets hardware controllers (ESP32 keypads, USB numpads on a
+// Pi) drive the scoreboard over a line-based TCP/UDP protocol.
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"scoreboard-backend/internal/models"
+	"scoreboard-backend/internal/services"
+	"strconv"
+	"strings"
+)
+
+// Command is a parsed controller instruction
+type Command struct {
+	Action string // One of the Action* constants
+	Team   string // models.TeamA or models.TeamB for score and foul commands
+	Delta  int    // Signed change for score and foul commands
+	Tenths int    // Clock value for set commands
+}
+
+const (
+	ActionScore          = "score"
+	ActionFoul           = "foul"
+	ActionClockStart     = "clock_start"
+	ActionClockStop      = "clock_stop"
+	ActionClockReset     = "clock_reset"
+	ActionClockSet       = "clock_set"
+	ActionShotClockReset = "shotclock_reset"
+	ActionShotClockSet   = "shotclock_set"
+	ActionSync           = "sync"
+	ActionPing           = "ping"
+)
+
+var ErrUnknownCommand = errors.New("unknown command")
+
+var (
+	scorePattern = regexp.MustCompile(`^([AB])([+-])(\d+)$`)
+	foulPattern  = regexp.MustCompile(`^F([AB])([+-])(\d*)$`)
+)
+
+// ParseCommand parses one command line. Commands are case-insensitive:
+//
+//	A+1, A+2, B-1        adjust a team's score
+//	FA+, FB-, FA+2       adjust a team's fouls (default step 1)
+//	CLOCK START|STOP     start or stop both clocks together
+//	CLOCK RESET          reset the game clock to the profile's game length
+//	CLOCK SET mm:ss.t    set the game clock (mm:ss.t, mm:ss, ss.t or ss)
+//	SC RESET             reset the shot clock to the profile's value
+//	SC START|STOP        same as CLOCK START|STOP
+//	SC SET ss.t          set the shot clock (the same formats)
+//	SYNC                 rebroadcast the full state to displays
+//	PING                 liveness check, changes nothing
+func ParseCommand(line string) (Command, error) {
+	fields := strings.Fields(strings.ToUpper(strings.TrimSpace(line)))
+	if len(fields) == 0 {
+		return Command{}, ErrUnknownCommand
+	}
+
+	if len(fields) == 1 {
+		if m := scorePattern.FindStringSubmatch(fields[0]); m != nil {
+			delta, err := signed(m[2], m[3])
+			if err != nil {
+				return Command{}, err
+			}
+			return Command{Action: ActionScore, Team: m[1], Delta: delta}, nil
+		}
+		if m := foulPattern.FindStringSubmatch(fields[0]); m != nil {
+			delta, err := signed(m[2], m[3])
+			if err != nil {
+				return Command{}, err
+			}
+			return Command{Action: ActionFoul, Team: m[1], Delta: delta}, nil
+		}
+		switch fields[0] {
+		case "SYNC":
+			return Command{Action: ActionSync}, nil
+		case "PING":
+			return Command{Action: ActionPing}, nil
+		}
+		return Command{}, ErrUnknownCommand
+	}
+
+	switch fields[0] + " " + fields[1] {
+	case "CLOCK START", "SC START":
+		return Command{Action: ActionClockStart}, nil
+	case "CLOCK STOP", "SC STOP":
+		return Command{Action: ActionClockStop}, nil
+	case "CLOCK RESET":
+		return Command{Action: ActionClockReset}, nil
+	case "SC RESET":
+		return Command{Action: ActionShotClockReset}, nil
+	case "CLOCK SET", "SC SET":
+		if len(fields) != 3 {
+			return Command{}, fmt.Errorf("%s needs a time", fields[0]+" "+fields[1])
+		}
+		// The same grammar as the REST and WebSocket clock values
+		tenths, err := services.ParseClockTenths(fields[2])
+		if err != nil {
+			return Command{}, err
+		}
+		action := ActionClockSet
+		if fields[0] == "SC" {
+			action = ActionShotClockSet
+		}
+		return Command{Action: action, Tenths: tenths}, nil
+	}
+	return Command{}, ErrUnknownCommand
+}
+
+// signed turns a sign and an optional step into a delta; no step means 1
+func signed(sign, digits string) (int, error) {
+	n := 1
+	if digits != "" {
+		var err error
+		if n, err = strconv.Atoi(digits); err != nil {
+			return 0, fmt.Errorf("invalid step %q", digits)
+		}
+	}
+	if sign == "-" {
+		return -n, nil
+	}
+	return n, nil
+}
+
+// Execute applies a command through the control service, the same path the
+// REST handlers use, and returns a short reply for the controller.
+func Execute(control *services.ControlService, cmd Command, actor models.Actor) (string, error) {
+	switch cmd.Action {
+	case ActionScore:
+		score, err := control.AdjustScore(cmd.Team, cmd.Delta, actor)
+		return fmt.Sprintf("SCORE%s %d", cmd.Team, score), err
+	case ActionFoul:
+		fouls, err := control.AdjustFoul(cmd.Team, cmd.Delta, actor)
+		return fmt.Sprintf("FOUL%s %d", cmd.Team, fouls), err
+	case ActionClockStart:
+		if err := control.StartClocks(actor); err != nil {
+			return "", err
+		}
+		return "CLOCK STARTED", nil
+	case ActionClockStop:
+		control.StopClocks(actor)
+		return "CLOCK STOPPED", nil
+	case ActionClockReset:
+		return "CLOCK RESET", control.ResetTimer(actor)
+	case ActionClockSet:
+		return fmt.Sprintf("CLOCK %d", control.SetTimer(cmd.Tenths, actor)), nil
+	case ActionShotClockReset:
+		control.ResetShotClock(actor)
+		return "SC RESET", nil
+	case ActionShotClockSet:
+		return fmt.Sprintf("SC %d", control.SetShotClock(cmd.Tenths, actor)), nil
+	case ActionSync:
+		control.SyncState()
+		return "SYNCED", nil
+	case ActionPing:
+		return "PONG", nil
+	}
+	return "", ErrUnknownCommand
+}
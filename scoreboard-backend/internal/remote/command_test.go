@@ -0,0 +1,91 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"scoreboard-backend/internal/clock"
+	"scoreboard-backend/internal/config"
+	"scoreboard-backend/internal/models"
+	"scoreboard-backend/internal/rules"
+	"scoreboard-backend/internal/server"
+	"testing"
+	"time"
+)
+
+func newControl(t *testing.T) *server.Server {
+	t.Helper()
+	cfg := config.Default()
+	cfg.DataDir = t.TempDir()
+	srv, err := server.New(cfg, clock.NewFake(time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		srv.SaveState()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		srv.WebSocket.Shutdown(ctx)
+	})
+	return srv
+}
+
+func TestExecuteEnforcesRules(t *testing.T) {
+	srv := newControl(t)
+	actor := models.Actor{Source: models.SourceRemote, ID: "pad"}
+	run := func(line string) (string, error) {
+		cmd, err := ParseCommand(line)
+		if err != nil {
+			t.Fatalf("%s: %v", line, err)
+		}
+		return Execute(srv.Control, cmd, actor)
+	}
+
+	if _, err := run("A+99"); !errors.Is(err, rules.ErrLimit) {
+		t.Errorf("A+99: got %v, want rules.ErrLimit", err)
+	}
+	if _, err := run("FB+100"); !errors.Is(err, rules.ErrLimit) {
+		t.Errorf("FB+100: got %v, want rules.ErrLimit", err)
+	}
+	tests := []struct{ line, want string }{
+		{"A+3", "SCOREA 3"},
+		{"CLOCK SET 99:00", "CLOCK 6000"},
+		{"CLOCK SET 4:30", "CLOCK 2700"},
+		{"SC SET 30.0", "SC 120"},
+		{"SC SET 7.5", "SC 75"},
+	}
+	for _, tt := range tests {
+		if reply, err := run(tt.line); err != nil || reply != tt.want {
+			t.Errorf("%s: got %q, %v; want %q", tt.line, reply, err, tt.want)
+		}
+	}
+	state := srv.Scoreboard.GetState()
+	if state.ScoreA != 3 || state.FoulB != 0 || state.TimerTenths != 2700 || state.ShotClockTenths != 75 {
+		t.Errorf("state %+v", state)
+	}
+}
+
+func TestParseClockSet(t *testing.T) {
+	tests := []struct {
+		line   string
+		action string
+		tenths int
+	}{
+		{"CLOCK SET 10:00", ActionClockSet, 6000},
+		{"clock set 4:30.5", ActionClockSet, 2705},
+		{"CLOCK SET 59.9", ActionClockSet, 599},
+		{"SC SET 5", ActionShotClockSet, 50},
+		{"SC SET 7.5", ActionShotClockSet, 75},
+		{"SC SET 0.1", ActionShotClockSet, 1},
+	}
+	for _, tt := range tests {
+		cmd, err := ParseCommand(tt.line)
+		if err != nil || cmd.Action != tt.action || cmd.Tenths != tt.tenths {
+			t.Errorf("%s: got %+v, %v; want %s %d", tt.line, cmd, err, tt.action, tt.tenths)
+		}
+	}
+	for _, line := range []string{"CLOCK SET 10:00xyz", "CLOCK SET 4:60", "CLOCK SET -1:00", "SC SET 5.05", "SC SET 99.x", "SC SET"} {
+		if cmd, err := ParseCommand(line); err == nil {
+			t.Errorf("%s: parsed as %+v", line, cmd)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package remote
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"scoreboard-backend/internal/models"
+	"scoreboard-backend/internal/services"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Config holds the listener addresses and the pre-shared key. An empty
+// address disables that transport.
+type Config struct {
+	TCPAddr string // e.g. ":7070"
+	UDPAddr string // e.g. ":7071"
+	Key     string // Pre-shared key every device must present
+}
+
+// Server accepts controller commands over TCP and UDP.
+//
+// TCP: the first line must be "HELLO <device-id> <key>". Every following
+// line is a command; each gets one reply line, "OK <detail>" or "ERR <reason>".
+//
+// UDP: each datagram is "<device-id> <seq> <command> <mac>" and gets one
+// reply datagram. The key never goes on the wire: mac is the hex
+// HMAC-SHA256, keyed with the pre-shared key, of everything before the last
+// space. seq must be greater than the last one accepted from that device, so
+// a captured datagram can't be replayed. Sequence numbers are only kept in
+// memory and start over when the server restarts.
+//
+// Neither transport is encrypted; anyone on the network can read commands.
+type Server struct {
+	control  *services.ControlService
+	config   Config
+	tcp      net.Listener
+	udp      net.PacketConn
+	wg       sync.WaitGroup
+	mutex    sync.Mutex
+	conns    map[net.Conn]struct{}
+	closing  bool
+	sequence map[string]uint64 // Last accepted UDP sequence number per device
+}
+
+func NewServer(control *services.ControlService, config Config) *Server {
+	return &Server{
+		control:  control,
+		config:   config,
+		conns:    make(map[net.Conn]struct{}),
+		sequence: make(map[string]uint64),
+	}
+}
+
+// Start opens the configured listeners and serves them in the background.
+func (s *Server) Start() error {
+	if s.config.Key == "" {
+		return errors.New("remote control needs a pre-shared key")
+	}
+	if s.config.TCPAddr != "" {
+		l, err := net.Listen("tcp", s.config.TCPAddr)
+		if err != nil {
+			return fmt.Errorf("remote tcp listen: %w", err)
+		}
+		s.tcp = l
+		s.wg.Add(1)
+		go s.acceptTCP()
+		slog.Info("Remote control listening", "network", "tcp", "addr", l.Addr().String())
+	}
+	if s.config.UDPAddr != "" {
+		pc, err := net.ListenPacket("udp", s.config.UDPAddr)
+		if err != nil {
+			s.Close()
+			return fmt.Errorf("remote udp listen: %w", err)
+		}
+		s.udp = pc
+		s.wg.Add(1)
+		go s.serveUDP()
+		slog.Info("Remote control listening", "network", "udp", "addr", pc.LocalAddr().String())
+	}
+	return nil
+}
+
+// Close stops both listeners, disconnects every TCP device and waits for
+// all connections to finish.
+func (s *Server) Close() error {
+	if s.tcp != nil {
+		s.tcp.Close()
+	}
+	if s.udp != nil {
+		s.udp.Close()
+	}
+	s.mutex.Lock()
+	s.closing = true
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mutex.Unlock()
+	s.wg.Wait()
+	return nil
+}
+
+func (s *Server) authorized(key string) bool {
+	return subtle.ConstantTimeCompare([]byte(key), []byte(s.config.Key)) == 1
+}
+
+func (s *Server) acceptTCP() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.tcp.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			slog.Warn("Remote tcp accept error", "error", err)
+			continue
+		}
+		if !s.track(conn) {
+			conn.Close()
+			return
+		}
+		s.wg.Add(1)
+		go s.serveTCP(conn)
+	}
+}
+
+// track registers a connection so Close can end it, unless Close has
+// already started
+func (s *Server) track(conn net.Conn) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closing {
+		return false
+	}
+	s.conns[conn] = struct{}{}
+	return true
+}
+
+func (s *Server) serveTCP(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mutex.Lock()
+		delete(s.conns, conn)
+		s.mutex.Unlock()
+		conn.Close()
+	}()
+	scanner := bufio.NewScanner(conn)
+	if !scanner.Scan() {
+		return
+	}
+	hello := strings.Fields(scanner.Text())
+	if len(hello) != 3 || !strings.EqualFold(hello[0], "HELLO") || !s.authorized(hello[2]) {
+		fmt.Fprintln(conn, "ERR unauthorized")
+		slog.Warn("Remote tcp client rejected", "clientIp", conn.RemoteAddr().String())
+		return
+	}
+	actor := models.Actor{Source: models.SourceRemote, ID: hello[1], ClientIP: conn.RemoteAddr().String(), Route: "tcp"}
+	fmt.Fprintln(conn, "OK")
+	slog.Info("Remote device connected", "actor", actor)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fmt.Fprintln(conn, s.handle(line, actor))
+	}
+	slog.Info("Remote device disconnected", "actor", actor)
+}
+
+func (s *Server) serveUDP() {
+	defer s.wg.Done()
+	buf := make([]byte, 512)
+	for {
+		n, addr, err := s.udp.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			slog.Warn("Remote udp read error", "error", err)
+			continue
+		}
+		device, command, err := s.verifyDatagram(strings.TrimSpace(string(buf[:n])))
+		if err != nil {
+			s.udp.WriteTo([]byte("ERR "+err.Error()+"\n"), addr)
+			slog.Warn("Remote udp datagram rejected", "clientIp", addr.String(), "error", err)
+			continue
+		}
+		actor := models.Actor{Source: models.SourceRemote, ID: device, ClientIP: addr.String(), Route: "udp"}
+		s.udp.WriteTo([]byte(s.handle(command, actor)+"\n"), addr)
+	}
+}
+
+var (
+	errUnauthorized = errors.New("unauthorized")
+	errReplayed     = errors.New("stale sequence number")
+)
+
+// verifyDatagram checks a datagram's MAC and sequence number and returns the
+// device ID and the command
+func (s *Server) verifyDatagram(datagram string) (device, command string, err error) {
+	i := strings.LastIndexByte(datagram, ' ')
+	if i < 0 {
+		return "", "", errUnauthorized
+	}
+	signed, mac := datagram[:i], datagram[i+1:]
+	parts := strings.SplitN(signed, " ", 3)
+	if len(parts) != 3 || !s.validMAC(signed, mac) {
+		return "", "", errUnauthorized
+	}
+	seq, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return "", "", errUnauthorized
+	}
+	// Only serveUDP reads and writes the sequence numbers
+	if last, seen := s.sequence[parts[0]]; seen && seq <= last {
+		return "", "", errReplayed
+	}
+	s.sequence[parts[0]] = seq
+	return parts[0], parts[2], nil
+}
+
+func (s *Server) validMAC(message, mac string) bool {
+	got, err := hex.DecodeString(mac)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(got, Sign(s.config.Key, message))
+}
+
+// Sign returns the HMAC-SHA256 of a UDP datagram's "<device-id> <seq>
+// <command>" part, keyed with the pre-shared key. Devices send it hex encoded.
+func Sign(key, message string) []byte {
+	h := hmac.New(sha256.New, []byte(key))
+	h.Write([]byte(message))
+	return h.Sum(nil)
+}
+
+// handle parses and executes one command and formats the reply line
+func (s *Server) handle(line string, actor models.Actor) string {
+	cmd, err := ParseCommand(line)
+	if err != nil {
+		return "ERR " + err.Error()
+	}
+	reply, err := Execute(s.control, cmd, actor)
+	if err != nil {
+		return "ERR " + err.Error()
+	}
+	return "OK " + reply
+}
@@ -0,0 +1,118 @@
+package remote
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testKey = "secret"
+
+func startServer(t *testing.T) *Server {
+	t.Helper()
+	// PING touches no service, so these tests need no control service
+	s := NewServer(nil, Config{TCPAddr: "127.0.0.1:0", UDPAddr: "127.0.0.1:0", Key: testKey})
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestCloseDisconnectsTCPDevices(t *testing.T) {
+	s := startServer(t)
+	conn, err := net.Dial("tcp", s.tcp.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	fmt.Fprintf(conn, "HELLO pad %s\nPING\n", testKey)
+	for _, want := range []string{"OK\n", "OK PONG\n"} {
+		if line, err := r.ReadString('\n'); err != nil || line != want {
+			t.Fatalf("got %q, %v; want %q", line, err, want)
+		}
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		s.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close waited on a connected device")
+	}
+	if _, err := r.ReadString('\n'); err == nil {
+		t.Error("the device is still connected after Close")
+	}
+}
+
+func TestUDPDatagrams(t *testing.T) {
+	s := startServer(t)
+	defer s.Close()
+	conn, err := net.Dial("udp", s.udp.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	signed := func(key, message string) string {
+		return message + " " + hex.EncodeToString(Sign(key, message))
+	}
+
+	tests := []struct {
+		name     string
+		datagram string
+		want     string
+	}{
+		{"valid", signed(testKey, "pad 5 PING"), "OK PONG"},
+		{"replayed", signed(testKey, "pad 5 PING"), "ERR stale sequence number"},
+		{"older", signed(testKey, "pad 4 PING"), "ERR stale sequence number"},
+		{"newer", signed(testKey, "pad 6 ping"), "OK PONG"},
+		{"other device", signed(testKey, "keypad 1 PING"), "OK PONG"},
+		{"wrong key", signed("guess", "pad 7 PING"), "ERR unauthorized"},
+		{"plaintext key", "pad " + testKey + " PING", "ERR unauthorized"},
+		{"tampered", strings.Replace(signed(testKey, "pad 8 A+1"), "A+1", "A+9", 1), "ERR unauthorized"},
+		{"bad sequence", signed(testKey, "pad x PING"), "ERR unauthorized"},
+		{"command with spaces", signed(testKey, "pad 9 SC SET 99.x"), "ERR invalid clock value, expected mm:ss.t, mm:ss or ss.t"},
+	}
+	buf := make([]byte, 512)
+	for _, tt := range tests {
+		if _, err := conn.Write([]byte(tt.datagram)); err != nil {
+			t.Fatal(err)
+		}
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := strings.TrimSpace(string(buf[:n])); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseCommandStep(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    Command
+		wantErr bool
+	}{
+		{"a+2", Command{Action: ActionScore, Team: "A", Delta: 2}, false},
+		{"B-1", Command{Action: ActionScore, Team: "B", Delta: -1}, false},
+		{"FA+", Command{Action: ActionFoul, Team: "A", Delta: 1}, false},
+		{"FB-3", Command{Action: ActionFoul, Team: "B", Delta: -3}, false},
+		{"A+99999999999999999999", Command{}, true},
+		{"FA+99999999999999999999", Command{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseCommand(tt.line)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseCommand(%q) = %+v, %v", tt.line, got, err)
+		}
+	}
+}
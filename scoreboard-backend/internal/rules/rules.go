@@ -0,0 +1,114 @@
+// Package rules defines the clock and score limits of each supported game format.
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrLimit is wrapped by every error reporting a value outside a profile's limits
+var ErrLimit = errors.New("outside rules profile limits")
+
+// Profile holds the limits of one game format
+type Profile struct {
+	Name            string `json:"name"`
+	GameClockTenths int    `json:"gameClockTenths"` // Game clock at the start of a game
+	ShotClockTenths int    `json:"shotClockTenths"` // Shot clock after a reset
+	ScoreCap        uint   `json:"scoreCap"`        // First team to reach this wins; 0 means no cap
+	MaxFouls        uint   `json:"maxFouls"`        // Upper bound accepted for a team's foul count
+}
+
+// DefaultProfile is used when no profile is configured
+const DefaultProfile = "fiba3x3"
+
+var profiles = map[string]Profile{
+	"fiba3x3": {
+		Name:            "fiba3x3",
+		GameClockTenths: 10 * 60 * 10, // 10:00
+		ShotClockTenths: 12 * 10,      // 12s
+		ScoreCap:        21,
+		MaxFouls:        99,
+	},
+	"practice": {
+		Name:            "practice",
+		GameClockTenths: 10 * 60 * 10,
+		ShotClockTenths: 12 * 10,
+		ScoreCap:        0,
+		MaxFouls:        99,
+	},
+}
+
+// CheckScores reports whether a pair of scores can occur under the profile.
+// With a cap, a two-pointer at cap-1 can end the game one past the cap, and
+// only one team can have reached it.
+func (p Profile) CheckScores(scoreA, scoreB uint) error {
+	if p.ScoreCap == 0 {
+		return nil
+	}
+	for _, score := range []uint{scoreA, scoreB} {
+		if score > p.ScoreCap+1 {
+			return fmt.Errorf("%w: score %d is above the %s cap of %d", ErrLimit, score, p.Name, p.ScoreCap)
+		}
+	}
+	if scoreA >= p.ScoreCap && scoreB >= p.ScoreCap {
+		return fmt.Errorf("%w: both teams cannot reach the %s cap of %d", ErrLimit, p.Name, p.ScoreCap)
+	}
+	return nil
+}
+
+// CheckFouls reports whether both foul counts are within the profile's maximum
+func (p Profile) CheckFouls(foulA, foulB uint) error {
+	for _, fouls := range []uint{foulA, foulB} {
+		if fouls > p.MaxFouls {
+			return fmt.Errorf("%w: %d fouls is above the %s maximum of %d", ErrLimit, fouls, p.Name, p.MaxFouls)
+		}
+	}
+	return nil
+}
+
+// ClampGameClock limits tenths to the range from zero to the profile's
+// starting game clock, reporting whether it had to be changed
+func (p Profile) ClampGameClock(tenths int) (int, bool) {
+	return clamp(tenths, p.GameClockTenths)
+}
+
+// ClampShotClock limits tenths to the range from zero to the profile's shot
+// clock, reporting whether it had to be changed
+func (p Profile) ClampShotClock(tenths int) (int, bool) {
+	return clamp(tenths, p.ShotClockTenths)
+}
+
+func clamp(tenths, max int) (int, bool) {
+	switch {
+	case tenths < 0:
+		return 0, true
+	case tenths > max:
+		return max, true
+	}
+	return tenths, false
+}
+
+// Lookup returns the named profile
+func Lookup(name string) (Profile, error) {
+	p, ok := profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("unknown rules profile %q (available: %v)", name, Names())
+	}
+	return p, nil
+}
+
+// Default returns the DefaultProfile
+func Default() Profile {
+	return profiles[DefaultProfile]
+}
+
+// Names lists the available profiles
+func Names() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
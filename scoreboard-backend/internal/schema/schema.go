@@ -0,0 +1,160 @@
+// Package schema builds a JSON Schema (draft 2020-12) for the WebSocket
+// protocol from the typed payloads in the models package, so the published
+// schema cannot drift from what the server actually sends.
+package schema
+
+import (
+	"reflect"
+	"scoreboard-backend/internal/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema node. Only the keywords the generator emits are modelled.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Const                interface{}        `json:"const,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *int               `json:"minimum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// Protocol returns the schema for every message in models.ServerMessages and
+// models.ClientMessages. The root accepts either direction; generators can
+// target $defs/ServerMessage or $defs/ClientMessage directly.
+func Protocol(id string) *Schema {
+	g := &generator{defs: map[string]*Schema{}}
+	root := &Schema{
+		Schema:      draft,
+		ID:          id,
+		Title:       "Scoreboard WebSocket protocol",
+		Description: "Envelope version " + strconv.Itoa(models.ProtocolVersion),
+		Defs:        g.defs,
+	}
+
+	server := &Schema{Description: "Messages sent by the server on /ws/state"}
+	for _, p := range models.ServerMessages {
+		server.OneOf = append(server.OneOf, g.envelope(p, true))
+	}
+	client := &Schema{Description: "Messages accepted from clients on /ws/state"}
+	for _, p := range models.ClientMessages {
+		client.OneOf = append(client.OneOf, g.envelope(p, false))
+	}
+	g.defs["ServerMessage"] = server
+	g.defs["ClientMessage"] = client
+
+	root.AnyOf = []*Schema{
+		{Ref: "#/$defs/ServerMessage"},
+		{Ref: "#/$defs/ClientMessage"},
+	}
+	return root
+}
+
+type generator struct {
+	defs map[string]*Schema
+}
+
+// envelope describes {v, type, data} with type pinned to the payload's message type.
+func (g *generator) envelope(p models.Payload, versionRequired bool) *Schema {
+	s := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"v":    {Type: "integer", Const: models.ProtocolVersion},
+			"type": {Type: "string", Const: p.MessageType()},
+			"data": g.of(reflect.TypeOf(p)),
+		},
+		Required: []string{"type", "data"},
+	}
+	if versionRequired {
+		s.Required = append([]string{"v"}, s.Required...)
+	}
+	return s
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (g *generator) of(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	zero := 0
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Minimum: &zero}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.of(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.of(t.Elem())}
+	case reflect.Struct:
+		name := t.Name()
+		if _, done := g.defs[name]; !done {
+			g.defs[name] = nil // reserve to stop recursion
+			g.defs[name] = g.object(t)
+		}
+		return &Schema{Ref: "#/$defs/" + name}
+	default:
+		return &Schema{}
+	}
+}
+
+// object describes a struct, flattening embedded structs the way encoding/json does.
+func (g *generator) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}, AdditionalProperties: false}
+	g.addFields(s, t)
+	return s
+}
+
+func (g *generator) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			g.addFields(s, f.Type)
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		prop := g.of(f.Type)
+		if enum := f.Tag.Get("enum"); enum != "" {
+			prop.Enum = strings.Split(enum, ",")
+		}
+		s.Properties[name] = prop
+		if !strings.Contains(opts, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
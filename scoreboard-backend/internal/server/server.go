@@ -0,0 +1,241 @@
+// Package server wires the services together and builds the HTTP router.
+// main.go runs it with the system clock; the harness package runs it on an
+// httptest server with a fake one.
+package server
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"scoreboard-backend/internal/clock"
+	"scoreboard-backend/internal/config"
+	"scoreboard-backend/internal/handlers"
+	"scoreboard-backend/internal/logging"
+	"scoreboard-backend/internal/metrics"
+	"scoreboard-backend/internal/models"
+	"scoreboard-backend/internal/rules"
+	"scoreboard-backend/internal/services"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+)
+
+// Server is one backend: its services and the router serving them
+type Server struct {
+	Config     config.Config
+	EventStore *services.EventStore
+	Scoreboard *services.ScoreboardService
+	WebSocket  *services.WebSocketService
+	Timer      *services.TimerService
+	ShotClock  *services.ShotClockService
+	GameLog    *services.GameLog
+	AuditLog   *services.AuditLog
+	Control    *services.ControlService
+	Playback   *services.PlaybackService
+	Metrics    *metrics.Registry // This backend's gauges, served after metrics.Default
+	Router     *gin.Engine
+	Clock      clock.Clock
+}
+
+// New creates the data directory, starts the services on clk and picks up
+// the game saved at the last graceful shutdown, if any
+func New(cfg config.Config, clk clock.Clock) (*Server, error) {
+	if err := os.MkdirAll(cfg.DataDir, 0755); err != nil {
+		return nil, err
+	}
+	profile, _ := rules.Lookup(cfg.RulesProfile)
+
+	eventStore, err := services.NewEventStore(cfg.GamesDir())
+	if err != nil {
+		return nil, err
+	}
+	scoreboardService := services.NewScoreboardServiceWithRules(profile, eventStore, clk)
+	wsConfig := services.DefaultWebSocketConfig()
+	wsConfig.AllowedOrigins = cfg.AllowedOrigins
+	websocketService := services.NewWebSocketServiceWithConfig(wsConfig)
+	timerService := services.NewTimerService(scoreboardService, websocketService, clk)
+	shotClockService := services.NewShotClockService(scoreboardService, clk)
+	gameLog := services.NewGameLog(cfg.GameLogPath())
+	auditLog, err := services.NewAuditLog(cfg.AuditLogPath(), clk)
+	if err != nil {
+		eventStore.Close()
+		return nil, err
+	}
+	var archive *services.GameArchive
+	if cfg.ArchiveOnReset {
+		archive = services.NewGameArchive(cfg.ArchiveDir(), clk)
+	}
+	playbackService := services.NewPlaybackService(scoreboardService, timerService, websocketService, clk, cfg.Display)
+	controlService := services.NewControlService(scoreboardService, websocketService, timerService, shotClockService, gameLog, auditLog, archive, playbackService, clk, cfg.Display)
+	playbackService.OnEnd(func() { controlService.SyncState() })
+
+	// Pick up where the last graceful shutdown left off, replaying the saved
+	// game's events when they are there
+	if saved, err := services.LoadState(cfg.StatePath()); err == nil {
+		if err := scoreboardService.ResumeGame(saved.GameID); err == nil {
+			slog.Info("Resumed game", "gameId", saved.GameID, "savedAt", saved.SavedAt.Format(time.RFC3339), "state", scoreboardService.GetState())
+		} else {
+			if !errors.Is(err, services.ErrGameNotFound) {
+				slog.Warn("Could not resume the saved game", "gameId", saved.GameID, "error", err)
+			}
+			scoreboardService.RestoreState(saved.State)
+			slog.Info("Restored saved state", "savedAt", saved.SavedAt.Format(time.RFC3339), "state", saved.State)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		slog.Warn("Ignoring saved state", "error", err)
+	}
+	if _, err := auditLog.RecordConfig(cfg.Redacted(), scoreboardService.GetState()); err != nil {
+		slog.Error("Failed to audit configuration", "error", err)
+	}
+
+	gauges := newGauges(cfg.CourtID, scoreboardService, websocketService)
+	scoreboardHandler := handlers.NewScoreboardHandler(scoreboardService, websocketService, timerService, controlService, gameLog, auditLog)
+	healthHandler := handlers.NewHealthHandler(websocketService, timerService, shotClockService, gameLog, auditLog)
+	playbackHandler := handlers.NewPlaybackHandler(playbackService, scoreboardService)
+	overlayHandler := handlers.NewOverlayHandler(scoreboardService, controlService, playbackService)
+
+	return &Server{
+		Config:     cfg,
+		EventStore: eventStore,
+		Scoreboard: scoreboardService,
+		WebSocket:  websocketService,
+		Timer:      timerService,
+		ShotClock:  shotClockService,
+		GameLog:    gameLog,
+		AuditLog:   auditLog,
+		Control:    controlService,
+		Playback:   playbackService,
+		Metrics:    gauges,
+		Router:     NewRouter(cfg, gauges, scoreboardHandler, healthHandler, playbackHandler, overlayHandler),
+		Clock:      clk,
+	}, nil
+}
+
+// newGauges registers the clock and hub gauges, labelled with the court this
+// backend serves. They live in a registry of their own, since each server
+// has its own services.
+func newGauges(court string, scoreboardService *services.ScoreboardService, websocketService *services.WebSocketService) *metrics.Registry {
+	gauges := &metrics.Registry{}
+	gauges.NewGaugeFunc("scoreboard_websocket_clients", "Connected WebSocket clients.",
+		func() float64 { return float64(websocketService.GetClientCount()) }, "court", court)
+	gauges.NewGaugeFunc("scoreboard_game_clock_tenths", "Current game clock in tenths of a second.",
+		func() float64 { return float64(scoreboardService.GetTimerTenths()) }, "court", court)
+	gauges.NewGaugeFunc("scoreboard_shot_clock_tenths", "Current shot clock in tenths of a second.",
+		func() float64 { return float64(scoreboardService.GetShotClockTenths()) }, "court", court)
+	gauges.NewGaugeFunc("scoreboard_shot_clock_running", "1 while the shot clock is running.",
+		func() float64 {
+			if scoreboardService.IsShotClockRunning() {
+				return 1
+			}
+			return 0
+		}, "court", court)
+	return gauges
+}
+
+// NewRouter builds the Gin router with every route the backend serves.
+// /metrics serves metrics.Default followed by gauges.
+func NewRouter(cfg config.Config, gauges *metrics.Registry, scoreboardHandler *handlers.ScoreboardHandler, healthHandler *handlers.HealthHandler, playbackHandler *handlers.PlaybackHandler, overlayHandler *handlers.OverlayHandler) *gin.Engine {
+	router := gin.New()
+	router.Use(gin.Recovery(), logging.Middleware(), metrics.GinMiddleware())
+
+	// CORS middleware
+	corsConfig := cors.DefaultConfig()
+	if cfg.AllowsAllOrigins() {
+		corsConfig.AllowAllOrigins = true
+	} else {
+		corsConfig.AllowOrigins = cfg.AllowedOrigins
+	}
+	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
+	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", logging.RequestIDHeader, handlers.OperatorHeader}
+	corsConfig.ExposeHeaders = []string{logging.RequestIDHeader}
+	router.Use(cors.New(corsConfig))
+
+	// API routes
+	api := router.Group("/api")
+	{
+		api.GET("/state", scoreboardHandler.GetState)
+		api.POST("/timer/reset", scoreboardHandler.ResetTimer)
+		api.POST("/timer/set", scoreboardHandler.SetTimer)
+		api.POST("/clock/adjust", scoreboardHandler.AdjustClocks)
+		api.POST("/scoreA/increment", scoreboardHandler.IncrementScoreA)
+		api.POST("/scoreA/decrement", scoreboardHandler.DecrementScoreA)
+		api.POST("/scoreB/increment", scoreboardHandler.IncrementScoreB)
+		api.POST("/scoreB/decrement", scoreboardHandler.DecrementScoreB)
+		api.POST("/shotclock/set", scoreboardHandler.SetShotClock)
+		api.POST("/shotclock/reset", scoreboardHandler.ResetShotClock)
+		api.POST("/shotclock/start", scoreboardHandler.StartShotClock)
+		api.POST("/shotclock/stop", scoreboardHandler.StopShotClock)
+		api.POST("/game/reset", scoreboardHandler.ResetGame)
+		api.GET("/game/archives", scoreboardHandler.ListArchives)
+		api.POST("/game/archives/:id/restore", scoreboardHandler.RestoreArchive)
+		api.GET("/games", scoreboardHandler.ListGames)
+		api.GET("/games/:id/state", scoreboardHandler.GetGameState)
+		api.POST("/foulA/increment", scoreboardHandler.IncrementFoulA)
+		api.POST("/foulA/decrement", scoreboardHandler.DecrementFoulA)
+		api.POST("/foulB/increment", scoreboardHandler.IncrementFoulB)
+		api.POST("/foulB/decrement", scoreboardHandler.DecrementFoulB)
+		api.PUT("/score", scoreboardHandler.SetScores)
+		api.PUT("/fouls", scoreboardHandler.SetFouls)
+		api.GET("/log", scoreboardHandler.GetLog)
+		api.GET("/audit", scoreboardHandler.GetAudit)
+		api.GET("/review", scoreboardHandler.GetReview)
+		api.POST("/review/start", scoreboardHandler.StartReview)
+		api.GET("/review/events", scoreboardHandler.GetReviewEvents)
+		api.POST("/review/rewind", scoreboardHandler.RewindReview)
+		api.POST("/review/end", scoreboardHandler.EndReview)
+		api.GET("/playback", playbackHandler.GetPlayback)
+		api.POST("/playback/start", playbackHandler.StartPlayback)
+		api.POST("/playback/step", playbackHandler.StepPlayback)
+		api.POST("/playback/stop", playbackHandler.StopPlayback)
+		api.GET("/overlay/data", overlayHandler.GetOverlayData)
+		api.POST("/state/sync", scoreboardHandler.TriggerStateSync)
+		api.GET("/schema", scoreboardHandler.GetSchema)
+	}
+
+	router.GET("/ws/state", func(c *gin.Context) {
+		connectionHeader := c.Request.Header.Get("Connection")
+		upgradeHeader := c.Request.Header.Get("Upgrade")
+		if strings.Contains(strings.ToLower(connectionHeader), "upgrade") && strings.ToLower(upgradeHeader) == "websocket" {
+			scoreboardHandler.HandleWebSocket(c)
+			return
+		}
+		c.JSON(400, gin.H{"error": "WebSocket upgrade required"})
+	})
+
+	router.GET("/overlay", overlayHandler.GetOverlay)
+	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	router.GET("/metrics", gin.WrapH(metrics.Handler(metrics.Default, gauges)))
+	router.GET("/health", func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "ok"})
+	})
+	router.GET("/healthz/live", healthHandler.Live)
+	router.GET("/healthz/ready", healthHandler.Ready)
+	return router
+}
+
+// StopClocks ends any playback and stops the clocks, so the displays keep
+// showing the state that will be saved
+func (s *Server) StopClocks() {
+	s.Playback.Stop()
+	s.Control.StopClocks(models.Actor{Source: models.SourceSystem})
+}
+
+// SaveState stops the clocks and any playback again, in case a request that
+// was draining started them, and saves the game for the next start, then
+// closes the event store. Operator input should have stopped by now.
+func (s *Server) SaveState() {
+	s.StopClocks()
+	if err := s.Scoreboard.CheckReplay(); err != nil {
+		slog.Error("Game events do not replay to the live state", "gameId", s.Scoreboard.GameID(), "error", err)
+	}
+	if err := services.SaveState(s.Config.StatePath(), s.Scoreboard.GameID(), s.Scoreboard.GetState(), s.Clock); err != nil {
+		slog.Error("Failed to save state", "path", s.Config.StatePath(), "error", err)
+	} else {
+		slog.Info("State saved", "path", s.Config.StatePath())
+	}
+	s.EventStore.Close()
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"scoreboard-backend/internal/clock"
+	"scoreboard-backend/internal/config"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newServer(t *testing.T, court string) *Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	cfg := config.Default()
+	cfg.DataDir = t.TempDir()
+	cfg.CourtID = court
+	srv, err := New(cfg, clock.NewFake(time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		srv.SaveState()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		srv.WebSocket.Shutdown(ctx)
+		srv.EventStore.Close()
+	})
+	return srv
+}
+
+func TestMetricsServeEachServersGauges(t *testing.T) {
+	// A second server in the same process must not add its gauges to the first's
+	newServer(t, "court-2")
+	srv := newServer(t, "court-1")
+
+	rec := httptest.NewRecorder()
+	srv.Router.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	got := string(body)
+	for _, want := range []string{
+		"# TYPE scoreboard_websocket_clients gauge\n" + `scoreboard_websocket_clients{court="court-1"} 0` + "\n",
+		`scoreboard_game_clock_tenths{court="court-1"} 6000` + "\n",
+		`scoreboard_shot_clock_tenths{court="court-1"} 120` + "\n",
+		`scoreboard_shot_clock_running{court="court-1"} 0` + "\n",
+		"# TYPE scoreboard_http_requests_total counter\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("/metrics is missing %q", want)
+		}
+	}
+	if strings.Contains(got, "court-2") || strings.Count(got, "# TYPE scoreboard_websocket_clients ") != 1 {
+		t.Errorf("/metrics has another server's gauges:\n%s", got)
+	}
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"scoreboard-backend/internal/clock"
+	"scoreboard-backend/internal/models"
+	"sort"
+	"strings"
+	"time"
+)
+
+var ErrArchiveNotFound = errors.New("archive not found")
+
+// archiveIDPattern keeps IDs from naming files outside the archive directory
+var archiveIDPattern = regexp.MustCompile(`^[0-9A-Za-z.-]+$`)
+
+// Archive is a game saved just before it was reset
+type Archive struct {
+	ID         string                 `json:"id"`
+	ArchivedAt time.Time              `json:"archivedAt"`
+	Actor      models.Actor           `json:"actor"`            // Who reset the game
+	Reason     string                 `json:"reason,omitempty"` // Reason given for the reset
+	GameID     string                 `json:"gameId,omitempty"` // Game whose event log led to State
+	State      models.ScoreboardState `json:"state"`
+	Log        []string               `json:"log"` // Game log lines at the time of the reset
+}
+
+// ArchiveSummary is an archive without its game log, for listings
+type ArchiveSummary struct {
+	ID         string                 `json:"id"`
+	ArchivedAt time.Time              `json:"archivedAt"`
+	Reason     string                 `json:"reason,omitempty"`
+	State      models.ScoreboardState `json:"state"`
+	LogEntries int                    `json:"logEntries"`
+}
+
+// GameArchive stores reset games as one JSON file each, named by ID
+type GameArchive struct {
+	dir   string
+	clock clock.Clock
+}
+
+func NewGameArchive(dir string, clk clock.Clock) *GameArchive {
+	return &GameArchive{dir: dir, clock: clk}
+}
+
+// Save writes the archive, assigning it a time-based ID, and returns the ID
+func (a *GameArchive) Save(archive Archive) (string, error) {
+	if err := os.MkdirAll(a.dir, 0755); err != nil {
+		return "", err
+	}
+	archive.ArchivedAt = a.clock.Now().UTC()
+	archive.ID = archive.ArchivedAt.Format("20060102T150405.000Z")
+	data, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	f, err := os.OpenFile(a.path(archive.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		return "", err
+	}
+	return archive.ID, f.Sync()
+}
+
+// Load reads one archive. It returns ErrArchiveNotFound for unknown IDs.
+func (a *GameArchive) Load(id string) (Archive, error) {
+	if !archiveIDPattern.MatchString(id) {
+		return Archive{}, ErrArchiveNotFound
+	}
+	data, err := os.ReadFile(a.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return Archive{}, ErrArchiveNotFound
+	}
+	if err != nil {
+		return Archive{}, err
+	}
+	var archive Archive
+	if err := json.Unmarshal(data, &archive); err != nil {
+		return Archive{}, fmt.Errorf("archive %s: %w", id, err)
+	}
+	return archive, nil
+}
+
+// List returns every archive, newest first
+func (a *GameArchive) List() ([]ArchiveSummary, error) {
+	files, err := os.ReadDir(a.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []ArchiveSummary{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	summaries := []ArchiveSummary{}
+	for _, f := range files {
+		id, ok := strings.CutSuffix(f.Name(), ".json")
+		if !ok || f.IsDir() {
+			continue
+		}
+		archive, err := a.Load(id)
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, ArchiveSummary{
+			ID:         archive.ID,
+			ArchivedAt: archive.ArchivedAt,
+			Reason:     archive.Reason,
+			State:      archive.State,
+			LogEntries: len(archive.Log),
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].ArchivedAt.After(summaries[j].ArchivedAt)
+	})
+	return summaries, nil
+}
+
+func (a *GameArchive) path(id string) string {
+	return filepath.Join(a.dir, id+".json")
+}
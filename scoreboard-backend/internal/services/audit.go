@@ -0,0 +1,174 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"scoreboard-backend/internal/clock"
+	"scoreboard-backend/internal/models"
+	"sync"
+)
+
+// AuditLog is the append-only record of administrative and corrective
+// actions: resets, manual clock sets, score overrides and configuration
+// changes. Unlike the game log it is never cleared. Entries are stored one
+// JSON object per line.
+type AuditLog struct {
+	path    string
+	clock   clock.Clock
+	mutex   sync.Mutex
+	nextSeq int64
+}
+
+// AuditQuery filters AuditLog.Entries. Zero values match everything.
+type AuditQuery struct {
+	Action   string // Only entries with this action
+	AfterSeq int64  // Only entries with a higher sequence number
+	Limit    int    // At most this many, keeping the newest
+}
+
+// NewAuditLog opens the audit log at path, continuing the sequence of any
+// entries already in it. Entries are timed on clk, like the game's events.
+func NewAuditLog(path string, clk clock.Clock) (*AuditLog, error) {
+	l := &AuditLog{path: path, clock: clk, nextSeq: 1}
+	entries, err := l.read()
+	if err != nil {
+		return nil, err
+	}
+	if n := len(entries); n > 0 {
+		l.nextSeq = entries[n-1].Seq + 1
+	}
+	return l, nil
+}
+
+// Record appends an entry for action and returns it with its sequence
+// number and time filled in. The reason is taken from the actor.
+func (l *AuditLog) Record(action string, actor models.Actor, before, after models.ScoreboardState, details interface{}) (models.AuditEntry, error) {
+	entry := models.AuditEntry{
+		Time:   l.clock.Now().UTC(),
+		Action: action,
+		Actor:  actor,
+		Reason: actor.Reason,
+		Before: before,
+		After:  after,
+	}
+	entry.Actor.Reason = ""
+	if details != nil {
+		data, err := json.Marshal(details)
+		if err != nil {
+			return entry, err
+		}
+		entry.Details = data
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	entry.Seq = l.nextSeq
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return entry, err
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return entry, err
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return entry, err
+	}
+	if err := f.Sync(); err != nil {
+		return entry, err
+	}
+	l.nextSeq++
+	return entry, nil
+}
+
+// RecordConfig adds a config_change entry when config differs from the one
+// recorded last, so restarts with an unchanged configuration leave no trace.
+func (l *AuditLog) RecordConfig(config interface{}, state models.ScoreboardState) (bool, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return false, err
+	}
+	last, err := l.Entries(AuditQuery{Action: models.AuditConfigChange, Limit: 1})
+	if err != nil {
+		return false, err
+	}
+	if len(last) == 1 && bytes.Equal(compactJSON(last[0].Details), data) {
+		return false, nil
+	}
+	_, err = l.Record(models.AuditConfigChange, models.Actor{Source: models.SourceSystem}, state, state, json.RawMessage(data))
+	return err == nil, err
+}
+
+// Entries returns the entries matching q, oldest first.
+func (l *AuditLog) Entries(q AuditQuery) ([]models.AuditEntry, error) {
+	l.mutex.Lock()
+	entries, err := l.read()
+	l.mutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	matched := []models.AuditEntry{}
+	for _, e := range entries {
+		if (q.Action == "" || e.Action == q.Action) && e.Seq > q.AfterSeq {
+			matched = append(matched, e)
+		}
+	}
+	if q.Limit > 0 && len(matched) > q.Limit {
+		matched = matched[len(matched)-q.Limit:]
+	}
+	return matched, nil
+}
+
+// CheckWritable verifies the audit log can be appended to
+func (l *AuditLog) CheckWritable() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// read parses every entry in the file. Callers hold the mutex, except
+// NewAuditLog which runs before the log is shared.
+func (l *AuditLog) read() ([]models.AuditEntry, error) {
+	f, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var entries []models.AuditEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var e models.AuditEntry
+		if err := json.Unmarshal(line, &e); err != nil {
+			// A line cut short by a crash must not hide the rest of the log
+			slog.Warn("Skipping unreadable audit entry", "path", l.path, "error", err)
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+func compactJSON(data []byte) []byte {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return data
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"scoreboard-backend/internal/format"
+	"scoreboard-backend/internal/models"
+	"strconv"
+)
+
+var (
+	ErrInvalidClock        = errors.New("invalid clock value, expected mm:ss.t, mm:ss or ss.t")
+	ErrNoClockAdjustment   = errors.New("no clock adjustment given")
+	ErrClockAdjustConflict = errors.New("a clock cannot be given both a delta and a value")
+)
+
+var clockPattern = regexp.MustCompile(`^(?:(\d+):)?(\d+)(?:\.(\d))?$`)
+
+// ParseClockTenths parses "mm:ss.t", "mm:ss", "ss.t" or "ss" into tenths
+func ParseClockTenths(value string) (int, error) {
+	m := clockPattern.FindStringSubmatch(value)
+	if m == nil {
+		return 0, ErrInvalidClock
+	}
+	seconds, err := strconv.Atoi(m[2])
+	if err != nil {
+		return 0, ErrInvalidClock
+	}
+	tenths := seconds * 10
+	if m[1] != "" {
+		minutes, err := strconv.Atoi(m[1])
+		if err != nil || seconds >= 60 {
+			return 0, ErrInvalidClock
+		}
+		tenths += minutes * 60 * 10
+	}
+	if m[3] != "" {
+		tenths += int(m[3][0] - '0')
+	}
+	return tenths, nil
+}
+
+// ClockAdjustment corrects either clock by a signed delta or to an exact
+// value, both in tenths. Nil fields leave that clock alone.
+type ClockAdjustment struct {
+	GameClockDelta *int
+	GameClock      *int
+	ShotClockDelta *int
+	ShotClock      *int
+}
+
+// ClockAdjustResult is the state after an adjustment, and whether a value
+// had to be clamped to the rules profile
+type ClockAdjustResult struct {
+	State   models.ScoreboardState `json:"state"`
+	Clamped bool                   `json:"clamped"`
+}
+
+// AdjustClocks applies a clock correction, e.g. putting 0.7s back on the game
+// clock after video review. Values are clamped to the rules profile rather
+// than rejected. Running clocks keep running.
+func (c *ControlService) AdjustClocks(adj ClockAdjustment, actor models.Actor) (ClockAdjustResult, error) {
+	c.endPlayback(actor)
+	if adj.GameClockDelta == nil && adj.GameClock == nil && adj.ShotClockDelta == nil && adj.ShotClock == nil {
+		return ClockAdjustResult{}, ErrNoClockAdjustment
+	}
+	if (adj.GameClockDelta != nil && adj.GameClock != nil) || (adj.ShotClockDelta != nil && adj.ShotClock != nil) {
+		return ClockAdjustResult{}, ErrClockAdjustConflict
+	}
+	before, after, clamped := c.scoreboardService.CorrectClocks(adj)
+	var changes []Change
+	if adj.GameClockDelta != nil || adj.GameClock != nil {
+		changes = append(changes, Change{Field: "GameClock", Before: format.Precise(before.State.TimerTenths), After: format.Precise(after.State.TimerTenths)})
+	}
+	if adj.ShotClockDelta != nil || adj.ShotClock != nil {
+		changes = append(changes, Change{Field: "ShotClock", Before: format.PreciseShotClock(before.State.ShotClockTenths), After: format.PreciseShotClock(after.State.ShotClockTenths)})
+	}
+	c.correct(models.AuditClockCorrect, before.State, after.State, changes, actor)
+	return ClockAdjustResult{State: c.SyncState(), Clamped: clamped}, nil
+}
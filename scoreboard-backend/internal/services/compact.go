@@ -0,0 +1,62 @@
+package services
+
+import (
+	"encoding/binary"
+	"scoreboard-backend/internal/models"
+)
+
+// Compact frame layout, big-endian, an 8-byte header:
+//
+//	byte 0    frame kind (CompactTimerUpdate or CompactShotClockUpdate)
+//	byte 1    flags (CompactFlagRunning, CompactFlagHasTimer, CompactFlagHasShotClock)
+//	bytes 2-5 timerTenths (uint32)
+//	bytes 6-7 shotClockTenths (uint16)
+//
+// followed by the formatted clocks, each a length byte and ASCII text:
+// formattedTimer when CompactFlagHasTimer is set, then formattedShotclock
+// when CompactFlagHasShotClock is.
+//
+// Fields whose flag is not set are zero and must be ignored by the client.
+const (
+	CompactHeaderSize = 8
+
+	CompactTimerUpdate     byte = 0x01
+	CompactShotClockUpdate byte = 0x02
+
+	CompactFlagRunning      byte = 1 << 0
+	CompactFlagHasTimer     byte = 1 << 1
+	CompactFlagHasShotClock byte = 1 << 2
+)
+
+// encodeCompact returns the binary frame for high-frequency clock messages.
+// ok is false for message types that are always sent as JSON.
+func encodeCompact(message models.WebSocketMessage) (frame []byte, ok bool) {
+	frame = make([]byte, CompactHeaderSize)
+	switch data := message.Data.(type) {
+	case models.TimerUpdateData:
+		frame[0] = CompactTimerUpdate
+		frame[1] = CompactFlagHasTimer
+		binary.BigEndian.PutUint32(frame[2:6], uint32(data.TimerTenths))
+		frame = appendCompactString(frame, data.FormattedTimer)
+	case models.ShotClockUpdateData:
+		frame[0] = CompactShotClockUpdate
+		frame[1] = CompactFlagHasTimer | CompactFlagHasShotClock
+		if data.IsShotClockRunning {
+			frame[1] |= CompactFlagRunning
+		}
+		binary.BigEndian.PutUint32(frame[2:6], uint32(data.TimerTenths))
+		binary.BigEndian.PutUint16(frame[6:8], uint16(data.ShotClockTenths))
+		frame = appendCompactString(frame, data.FormattedTimer)
+		frame = appendCompactString(frame, data.FormattedShotclock)
+	default:
+		return nil, false
+	}
+	return frame, true
+}
+
+// appendCompactString appends s after its length byte. Formatted clocks are
+// a few characters, well under the 255 a length byte allows.
+func appendCompactString(frame []byte, s string) []byte {
+	frame = append(frame, byte(len(s)))
+	return append(frame, s...)
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"bytes"
+	"scoreboard-backend/internal/models"
+	"testing"
+)
+
+func TestEncodeCompact(t *testing.T) {
+	tests := []struct {
+		name string
+		data models.Payload
+		want []byte
+	}{
+		{"timer update", models.TimerUpdateData{TimerTenths: 5999, FormattedTimer: "09:59"}, []byte{
+			0x01, 0x02, 0x00, 0x00, 0x17, 0x6f, 0x00, 0x00,
+			5, '0', '9', ':', '5', '9',
+		}},
+		{"shot clock running", models.ShotClockUpdateData{ShotClockTenths: 119, IsShotClockRunning: true, TimerTenths: 599,
+			FormattedClocks: models.FormattedClocks{FormattedTimer: "59.9", FormattedShotclock: "11.9"}}, []byte{
+			0x02, 0x07, 0x00, 0x00, 0x02, 0x57, 0x00, 0x77,
+			4, '5', '9', '.', '9',
+			4, '1', '1', '.', '9',
+		}},
+		{"shot clock stopped", models.ShotClockUpdateData{ShotClockTenths: 0, TimerTenths: 6000,
+			FormattedClocks: models.FormattedClocks{FormattedTimer: "10:00", FormattedShotclock: "00.0"}}, []byte{
+			0x02, 0x06, 0x00, 0x00, 0x17, 0x70, 0x00, 0x00,
+			5, '1', '0', ':', '0', '0',
+			4, '0', '0', '.', '0',
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := encodeCompact(models.NewMessage(tt.data))
+			if !ok || !bytes.Equal(got, tt.want) {
+				t.Errorf("encodeCompact = % x, %v, want % x", got, ok, tt.want)
+			}
+		})
+	}
+	if _, ok := encodeCompact(models.NewMessage(models.ScoreUpdateData{Team: models.TeamA, ScoreA: 2})); ok {
+		t.Error("score_update encoded as a compact frame")
+	}
+}
@@ -0,0 +1,389 @@
+package services
+
+import (
+	"errors"
+	"log/slog"
+	"scoreboard-backend/internal/clock"
+	"scoreboard-backend/internal/format"
+	"scoreboard-backend/internal/models"
+	"sync"
+	"time"
+)
+
+var (
+	ErrShotClockRunning = errors.New("shot clock is already running")
+	ErrShotClockZero    = errors.New("cannot start shot clock when value is 0")
+	ErrInvalidTeam      = errors.New("team must be A or B")
+)
+
+// ControlService applies operator actions: it mutates the scoreboard,
+// broadcasts the result and records it in the game log. The REST handlers
+// and the hardware remote listener both go through it so every input path
+// behaves the same. Administrative changes are also written to the audit log.
+type ControlService struct {
+	scoreboardService Scoreboard
+	websocketService  Broadcaster
+	timerService      GameClock
+	shotClock         ShotClock
+	gameLog           *GameLog
+	auditLog          *AuditLog
+	archive           *GameArchive    // nil when resets are not archived
+	playback          PlaybackStopper // nil without playbacks
+	clock             clock.Clock     // expires reset tokens and times reviews
+	display           format.Rules    // formats the clocks in every broadcast
+	resetTokens       map[string]time.Time
+	resetMutex        sync.Mutex
+	review            *Review // nil during live play
+	reviewMutex       sync.Mutex
+}
+
+func NewControlService(
+	scoreboardService Scoreboard,
+	websocketService Broadcaster,
+	timerService GameClock,
+	shotClock ShotClock,
+	gameLog *GameLog,
+	auditLog *AuditLog,
+	archive *GameArchive,
+	playback PlaybackStopper,
+	clk clock.Clock,
+	display format.Rules,
+) *ControlService {
+	c := &ControlService{
+		scoreboardService: scoreboardService,
+		websocketService:  websocketService,
+		timerService:      timerService,
+		shotClock:         shotClock,
+		gameLog:           gameLog,
+		auditLog:          auditLog,
+		archive:           archive,
+		playback:          playback,
+		clock:             clk,
+		display:           display,
+		resetTokens:       make(map[string]time.Time),
+	}
+	shotClock.OnTick(func(int) { c.BroadcastShotClock() })
+	shotClock.OnExpire(c.shotClockExpired)
+	return c
+}
+
+// AdjustScore adds delta (which may be negative) to a team's score and
+// returns the new score. Raises are checked against the rules profile.
+func (c *ControlService) AdjustScore(team string, delta int, actor models.Actor) (uint, error) {
+	c.endPlayback(actor)
+	if team != models.TeamA && team != models.TeamB {
+		return 0, ErrInvalidTeam
+	}
+	before, score, err := c.scoreboardService.AdjustScore(team, delta)
+	if err != nil {
+		return before, err
+	}
+	c.record("Score"+team, before, score, actor)
+	c.BroadcastScore(team)
+	return score, nil
+}
+
+// SetScore overwrites a team's score, checked like AdjustScore. It is
+// audited as a score override.
+func (c *ControlService) SetScore(team string, score uint, actor models.Actor) error {
+	c.endPlayback(actor)
+	if team != models.TeamA && team != models.TeamB {
+		return ErrInvalidTeam
+	}
+	before, after, err := c.scoreboardService.SetScore(team, score)
+	if err != nil {
+		return err
+	}
+	c.record("Score"+team, teamScore(before.State, team), score, actor)
+	c.audit(models.AuditScoreOverride, actor, before.State, after.State, map[string]interface{}{"team": team, "score": score})
+	c.BroadcastScore(team)
+	return nil
+}
+
+// CorrectScores sets either or both scores in one correction. A nil score is
+// left as it is. The result is checked against the rules profile, and
+// clients get a single state_sync instead of one update per point.
+func (c *ControlService) CorrectScores(scoreA, scoreB *uint, actor models.Actor) (models.ScoreboardState, error) {
+	c.endPlayback(actor)
+	before, after, err := c.scoreboardService.CorrectScores(scoreA, scoreB)
+	if err != nil {
+		return before.State, err
+	}
+	c.correct(models.AuditScoreCorrect, before.State, after.State, []Change{
+		{Field: "ScoreA", Before: before.State.ScoreA, After: after.State.ScoreA},
+		{Field: "ScoreB", Before: before.State.ScoreB, After: after.State.ScoreB},
+	}, actor)
+	return c.SyncState(), nil
+}
+
+// CorrectFouls sets either or both foul counts in one correction, like CorrectScores
+func (c *ControlService) CorrectFouls(foulA, foulB *uint, actor models.Actor) (models.ScoreboardState, error) {
+	c.endPlayback(actor)
+	before, after, err := c.scoreboardService.CorrectFouls(foulA, foulB)
+	if err != nil {
+		return before.State, err
+	}
+	c.correct(models.AuditFoulCorrect, before.State, after.State, []Change{
+		{Field: "FoulA", Before: before.State.FoulA, After: after.State.FoulA},
+		{Field: "FoulB", Before: before.State.FoulB, After: after.State.FoulB},
+	}, actor)
+	return c.SyncState(), nil
+}
+
+// AdjustFoul adds delta (which may be negative) to a team's foul count and
+// returns the new count. Raises are checked against the rules profile.
+func (c *ControlService) AdjustFoul(team string, delta int, actor models.Actor) (uint, error) {
+	c.endPlayback(actor)
+	if team != models.TeamA && team != models.TeamB {
+		return 0, ErrInvalidTeam
+	}
+	before, fouls, err := c.scoreboardService.AdjustFoul(team, delta)
+	if err != nil {
+		return before, err
+	}
+	c.record("Foul"+team, before, fouls, actor)
+	c.BroadcastFouls(team)
+	return fouls, nil
+}
+
+// StartClocks starts the shot clock and the game clock together
+func (c *ControlService) StartClocks(actor models.Actor) error {
+	c.endPlayback(actor)
+	if c.inReview() {
+		return ErrReviewActive
+	}
+	if err := c.shotClock.Start(); err != nil {
+		if errors.Is(err, ErrShotClockZero) {
+			c.BroadcastShotClock()
+		}
+		return err
+	}
+	c.timerService.StartTimer() // Start main timer as well
+	c.logChange("ClocksRunning", false, true, actor)
+	c.BroadcastShotClock()
+	return nil
+}
+
+// StopClocks stops the shot clock and the game clock together
+func (c *ControlService) StopClocks(actor models.Actor) {
+	c.endPlayback(actor)
+	wasRunning := c.shotClock.Stop()
+	c.timerService.StopTimer() // Stop main timer as well
+	if wasRunning {
+		c.logChange("ClocksRunning", true, false, actor)
+	}
+	c.BroadcastShotClock()
+}
+
+// shotClockExpired stops the game clock with the shot clock, which has
+// already stopped itself at zero
+func (c *ControlService) shotClockExpired() {
+	c.timerService.StopTimer()
+	c.logChange("ClocksRunning", true, false, models.Actor{Source: models.SourceSystem, Route: "shot clock expired"})
+	c.BroadcastShotClock()
+}
+
+// StartTimer starts the game clock on its own, leaving the shot clock alone
+func (c *ControlService) StartTimer(actor models.Actor) error {
+	c.endPlayback(actor)
+	if c.inReview() {
+		return ErrReviewActive
+	}
+	if c.timerService.IsRunning() {
+		return nil
+	}
+	c.timerService.StartTimer()
+	c.logChange("TimerRunning", false, true, actor)
+	return nil
+}
+
+// StopTimer stops the game clock on its own, leaving the shot clock alone
+func (c *ControlService) StopTimer(actor models.Actor) {
+	c.endPlayback(actor)
+	if !c.timerService.IsRunning() {
+		return
+	}
+	c.timerService.StopTimer()
+	c.logChange("TimerRunning", true, false, actor)
+}
+
+// ResetShotClock puts the shot clock back to the profile's value. A reset from
+// zero restarts the clocks, since play continues after the violation.
+func (c *ControlService) ResetShotClock(actor models.Actor) {
+	c.endPlayback(actor)
+	before, after := c.scoreboardService.ResetShotClock()
+	c.logChange("ShotClockTenths", before.State.ShotClockTenths, after.State.ShotClockTenths, actor)
+	if before.State.ShotClockTenths == 0 && c.StartClocks(actor) == nil {
+		return
+	}
+	c.BroadcastShotClock()
+}
+
+// SetShotClock sets the shot clock to an exact value in tenths, clamped to
+// the rules profile, and returns the value set
+func (c *ControlService) SetShotClock(tenths int, actor models.Actor) int {
+	c.endPlayback(actor)
+	tenths, _ = c.scoreboardService.Rules().ClampShotClock(tenths)
+	before, after := c.scoreboardService.SetShotClockTenths(tenths)
+	c.logChange("ShotClockTenths", before.State.ShotClockTenths, tenths, actor)
+	c.audit(models.AuditShotClockSet, actor, before.State, after.State, nil)
+	c.BroadcastShotClock()
+	return tenths
+}
+
+// SetTimer sets the game clock to an exact value in tenths, clamped to the
+// rules profile, and returns the value set
+func (c *ControlService) SetTimer(tenths int, actor models.Actor) int {
+	c.endPlayback(actor)
+	tenths, _ = c.scoreboardService.Rules().ClampGameClock(tenths)
+	before, after := c.scoreboardService.SetTimerTenths(tenths)
+	c.logChange("TimerTenths", before.State.TimerTenths, tenths, actor)
+	c.audit(models.AuditTimerSet, actor, before.State, after.State, nil)
+	c.websocketService.BroadcastMessage(models.NewMessage(models.TimerUpdateData{
+		TimerTenths:    tenths,
+		FormattedTimer: c.display.GameClock(tenths),
+	}))
+	return tenths
+}
+
+// ResetTimer stops the game clock and sets it back to the profile's starting value
+func (c *ControlService) ResetTimer(actor models.Actor) error {
+	c.endPlayback(actor)
+	before, after, err := c.timerService.ResetTimer()
+	if err != nil {
+		return err
+	}
+	c.logChange("TimerTenths", before.State.TimerTenths, after.State.TimerTenths, actor)
+	c.audit(models.AuditTimerReset, actor, before.State, after.State, nil)
+	c.websocketService.BroadcastMessage(models.NewMessage(models.TimerUpdateData{
+		TimerTenths:    after.State.TimerTenths,
+		FormattedTimer: c.display.GameClock(after.State.TimerTenths),
+	}))
+	return nil
+}
+
+// SyncState broadcasts the full state to every client
+func (c *ControlService) SyncState() models.ScoreboardState {
+	data := c.StateSync()
+	c.websocketService.BroadcastMessage(models.NewMessage(data))
+	return data.ScoreboardState
+}
+
+// StateSync is the full state message, with its version and the review in
+// progress if any
+func (c *ControlService) StateSync() models.StateSyncData {
+	snapshot := c.scoreboardService.Snapshot()
+	data := models.StateSyncData{
+		ScoreboardState: snapshot.State,
+		FormattedClocks: c.FormatClocks(snapshot.State),
+		Version:         snapshot.Version,
+	}
+	c.reviewMutex.Lock()
+	if c.review != nil {
+		review := c.reviewUpdate()
+		data.Review = &review
+	}
+	c.reviewMutex.Unlock()
+	return data
+}
+
+// BroadcastScore sends both scores, naming the team that changed
+func (c *ControlService) BroadcastScore(team string) {
+	state := c.scoreboardService.GetState()
+	c.websocketService.BroadcastMessage(models.NewMessage(models.ScoreUpdateData{
+		Team:   team,
+		ScoreA: state.ScoreA,
+		ScoreB: state.ScoreB,
+	}))
+}
+
+// BroadcastFouls sends both foul counts, naming the team that changed
+func (c *ControlService) BroadcastFouls(team string) {
+	state := c.scoreboardService.GetState()
+	c.websocketService.BroadcastMessage(models.NewMessage(models.FoulUpdateData{
+		Team:  team,
+		FoulA: state.FoulA,
+		FoulB: state.FoulB,
+	}))
+}
+
+// BroadcastShotClock sends the current shot clock, its running state and the game clock
+func (c *ControlService) BroadcastShotClock() {
+	state := c.scoreboardService.GetState()
+	c.websocketService.BroadcastMessage(models.NewMessage(models.ShotClockUpdateData{
+		ShotClockTenths:    state.ShotClockTenths,
+		IsShotClockRunning: state.IsShotClockRunning,
+		TimerTenths:        state.TimerTenths,
+		FormattedClocks:    c.FormatClocks(state),
+	}))
+}
+
+// FormatClocks formats both clocks by the display rules
+func (c *ControlService) FormatClocks(state models.ScoreboardState) models.FormattedClocks {
+	return c.display.Clocks(state.TimerTenths, state.ShotClockTenths)
+}
+
+// DisplayRules are the rules broadcasts format the clocks by
+func (c *ControlService) DisplayRules() format.Rules {
+	return c.display
+}
+
+// endPlayback stops a playback in progress before a live change, so the
+// displays go back to the live game instead of showing both
+func (c *ControlService) endPlayback(actor models.Actor) {
+	if c.playback == nil {
+		return
+	}
+	if status, err := c.playback.Stop(); err == nil {
+		slog.Info("Playback stopped for a live change", "source", status.Source, "position", status.Position, "actor", actor)
+	}
+}
+
+// record logs a score or foul change and appends it to the game log
+func (c *ControlService) record(field string, before, after interface{}, actor models.Actor) {
+	c.gameLog.Record(c.scoreboardService.GetTimerTenths(), field, after, actor)
+	c.logChange(field, before, after, actor)
+}
+
+// correct writes one game log line, one structured log entry and one audit
+// entry for a correction, however many fields it touched. The game clock
+// context is the clock when the correction was made, before any change to it.
+func (c *ControlService) correct(action string, before, after models.ScoreboardState, changes []Change, actor models.Actor) {
+	timer := before.TimerTenths
+	c.gameLog.RecordCorrection(timer, changes, actor)
+	attrs := make([]any, 0, len(changes))
+	for _, change := range changes {
+		attrs = append(attrs, slog.Group(change.Field, "before", change.Before, "after", change.After))
+	}
+	slog.Info("correction",
+		"action", action,
+		slog.Group("changes", attrs...),
+		"gameClock", format.LogClock(timer),
+		"actor", actor,
+	)
+	c.audit(action, actor, before, after, nil)
+}
+
+// audit records an administrative action with the states just before and
+// just after it. A failed write is logged; the action has already been
+// applied and is not rolled back.
+func (c *ControlService) audit(action string, actor models.Actor, before, after models.ScoreboardState, details interface{}) {
+	if c.auditLog == nil {
+		return
+	}
+	if _, err := c.auditLog.Record(action, actor, before, after, details); err != nil {
+		slog.Error("Failed to write audit entry", "action", action, "actor", actor, "error", err)
+	}
+}
+
+// logChange writes one structured "state changed" entry, the record used to
+// reconstruct who changed what during a game.
+func (c *ControlService) logChange(field string, before, after interface{}, actor models.Actor) {
+	slog.Info("state changed",
+		"field", field,
+		"before", before,
+		"after", after,
+		"gameClock", format.LogClock(c.scoreboardService.GetTimerTenths()),
+		"actor", actor,
+	)
+}
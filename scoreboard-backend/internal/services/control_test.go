@@ -0,0 +1,243 @@
+package services
+
+import (
+	"errors"
+	"path/filepath"
+	"scoreboard-backend/internal/clock"
+	"scoreboard-backend/internal/format"
+	"scoreboard-backend/internal/models"
+	"scoreboard-backend/internal/rules"
+	"testing"
+	"time"
+)
+
+var testStart = time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+
+// testControl wires the services the way server.New does, on a fake clock
+// and a temporary data directory
+type testControl struct {
+	*ControlService
+	scoreboard *ScoreboardService
+	hub        *WebSocketService
+	timer      *TimerService
+	shot       *ShotClockService
+	playback   *PlaybackService
+	clock      *clock.Fake
+	dir        string
+}
+
+func newTestControl(t *testing.T) *testControl {
+	t.Helper()
+	return newTestControlWithRules(t, rules.Default())
+}
+
+func newTestControlWithRules(t *testing.T, profile rules.Profile) *testControl {
+	t.Helper()
+	dir := t.TempDir()
+	fake := clock.NewFake(testStart)
+	store, err := NewEventStore(filepath.Join(dir, "games"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sb := NewScoreboardServiceWithRules(profile, store, fake)
+	ws := NewWebSocketService()
+	timer := NewTimerService(sb, ws, fake)
+	shotClock := NewShotClockService(sb, fake)
+	audit, err := NewAuditLog(filepath.Join(dir, "audit.ndjson"), fake)
+	if err != nil {
+		t.Fatal(err)
+	}
+	playback := NewPlaybackService(sb, timer, ws, fake, format.DefaultRules())
+	control := NewControlService(sb, ws, timer, shotClock, NewGameLog(filepath.Join(dir, "game.log")), audit,
+		NewGameArchive(filepath.Join(dir, "archive"), fake), playback, fake, format.DefaultRules())
+	playback.OnEnd(func() { control.SyncState() })
+	t.Cleanup(func() {
+		playback.Stop()
+		control.StopClocks(models.Actor{Source: models.SourceSystem})
+		shutdownHub(t, ws)
+		store.Close()
+	})
+	return &testControl{ControlService: control, scoreboard: sb, hub: ws, timer: timer, shot: shotClock, playback: playback, clock: fake, dir: dir}
+}
+
+var testActor = models.Actor{Source: models.SourceREST, ClientIP: "127.0.0.1"}
+
+// listener receives broadcasts the way a display does
+type listener struct {
+	t      *testing.T
+	client *models.Client
+}
+
+func (c *testControl) listen(t *testing.T) listener {
+	t.Helper()
+	client := c.hub.NewClient("test", models.EncodingJSON)
+	c.hub.RegisterClient(client)
+	waitFor(t, "the listener to register", func() bool { return c.hub.GetClientCount() > 0 })
+	return listener{t, client}
+}
+
+// next waits for the next broadcast
+func (l listener) next() models.Payload {
+	l.t.Helper()
+	select {
+	case message, ok := <-l.client.Send:
+		if !ok {
+			l.t.Fatal("listener disconnected")
+		}
+		return message.Data
+	case <-time.After(5 * time.Second):
+		l.t.Fatal("timed out waiting for a broadcast")
+		return nil
+	}
+}
+
+// none fails when a broadcast arrives within a moment
+func (l listener) none() {
+	l.t.Helper()
+	select {
+	case message := <-l.client.Send:
+		l.t.Errorf("unexpected broadcast %s %+v", message.Type, message.Data)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestResetKeepsTokenWhenRefused(t *testing.T) {
+	c := newTestControl(t)
+	c.AdjustScore(models.TeamA, 3, testActor)
+	confirm, err := c.PrepareReset(testActor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.StartReview("check", testActor); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ResetGame(confirm.Token, testActor); !errors.Is(err, ErrReviewActive) {
+		t.Fatalf("reset during a review: got %v, want ErrReviewActive", err)
+	}
+	if _, err := c.EndReview("no change", false, testActor); err != nil {
+		t.Fatal(err)
+	}
+
+	// The refused reset left the token usable, once
+	if _, err := c.ResetGame(confirm.Token, testActor); err != nil {
+		t.Fatalf("reset after the review: %v", err)
+	}
+	if got := c.scoreboard.GetState().ScoreA; got != 0 {
+		t.Errorf("score %d after reset", got)
+	}
+	if _, err := c.ResetGame(confirm.Token, testActor); !errors.Is(err, ErrResetTokenInvalid) {
+		t.Errorf("second use of a token: got %v, want ErrResetTokenInvalid", err)
+	}
+}
+
+func TestResetTokenExpires(t *testing.T) {
+	c := newTestControl(t)
+	confirm, err := c.PrepareReset(testActor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.clock.Advance(ResetTokenTTL + time.Second)
+	if _, err := c.ResetGame(confirm.Token, testActor); !errors.Is(err, ErrResetTokenInvalid) {
+		t.Errorf("expired token: got %v, want ErrResetTokenInvalid", err)
+	}
+}
+
+func TestArchiveUsesTheClock(t *testing.T) {
+	c := newTestControl(t)
+	c.AdjustScore(models.TeamB, 2, testActor)
+	c.clock.Advance(90 * time.Second)
+	confirm, err := c.PrepareReset(testActor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := c.ResetGame(confirm.Token, testActor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := testStart.Add(90 * time.Second)
+	if id != want.Format("20060102T150405.000Z") {
+		t.Errorf("archive ID %s, want one from the fake clock's %s", id, want)
+	}
+	archives, err := c.Archives()
+	if err != nil || len(archives) != 1 {
+		t.Fatalf("archives %v, %v", archives, err)
+	}
+	if !archives[0].ArchivedAt.Equal(want) || archives[0].State.ScoreB != 2 {
+		t.Errorf("archive %+v, want archived at %s with score B 2", archives[0], want)
+	}
+}
+
+func TestScoreCapEnforced(t *testing.T) {
+	c := newTestControl(t)
+	if _, err := c.AdjustScore(models.TeamA, 99, testActor); !errors.Is(err, rules.ErrLimit) {
+		t.Errorf("A+99: got %v, want rules.ErrLimit", err)
+	}
+	if _, err := c.AdjustScore(models.TeamA, 20, testActor); err != nil {
+		t.Fatal(err)
+	}
+	// A two-pointer from 20 may end the game at 22
+	if score, err := c.AdjustScore(models.TeamA, 2, testActor); err != nil || score != 22 {
+		t.Errorf("two-pointer from 20: %d, %v", score, err)
+	}
+	if _, err := c.AdjustScore(models.TeamA, 1, testActor); !errors.Is(err, rules.ErrLimit) {
+		t.Errorf("A+1 at 22: got %v, want rules.ErrLimit", err)
+	}
+	// Only one team can reach the cap
+	if err := c.SetScore(models.TeamB, 21, testActor); !errors.Is(err, rules.ErrLimit) {
+		t.Errorf("score_update B=21 with A at 22: got %v, want rules.ErrLimit", err)
+	}
+	if err := c.SetScore(models.TeamB, 20, testActor); err != nil {
+		t.Errorf("score_update B=20: %v", err)
+	}
+	// Lowering is always allowed
+	if score, err := c.AdjustScore(models.TeamA, -1, testActor); err != nil || score != 21 {
+		t.Errorf("A-1 from 22: %d, %v", score, err)
+	}
+	if state := c.scoreboard.GetState(); state.ScoreA != 21 || state.ScoreB != 20 {
+		t.Errorf("state %d-%d, want 21-20", state.ScoreA, state.ScoreB)
+	}
+}
+
+func TestFoulMaxEnforced(t *testing.T) {
+	c := newTestControl(t)
+	max := int(rules.Default().MaxFouls)
+	if _, err := c.AdjustFoul(models.TeamB, max+1, testActor); !errors.Is(err, rules.ErrLimit) {
+		t.Errorf("FB+%d: got %v, want rules.ErrLimit", max+1, err)
+	}
+	if fouls, err := c.AdjustFoul(models.TeamB, max, testActor); err != nil || fouls != uint(max) {
+		t.Fatalf("FB+%d: %d, %v", max, fouls, err)
+	}
+	if _, err := c.AdjustFoul(models.TeamB, 1, testActor); !errors.Is(err, rules.ErrLimit) {
+		t.Errorf("a foul past the maximum: got %v, want rules.ErrLimit", err)
+	}
+	if fouls, err := c.AdjustFoul(models.TeamB, -1, testActor); err != nil || fouls != uint(max-1) {
+		t.Errorf("FB-: %d, %v", fouls, err)
+	}
+}
+
+func TestClockSetsClamped(t *testing.T) {
+	c := newTestControl(t)
+	profile := rules.Default()
+	tests := []struct {
+		name string
+		set  func(int, models.Actor) int
+		get  func() int
+		in   int
+		want int
+	}{
+		{"game clock above the profile", c.SetTimer, c.scoreboard.GetTimerTenths, 99 * 600, profile.GameClockTenths},
+		{"game clock below zero", c.SetTimer, c.scoreboard.GetTimerTenths, -5, 0},
+		{"game clock in range", c.SetTimer, c.scoreboard.GetTimerTenths, 4321, 4321},
+		{"shot clock above the profile", c.SetShotClock, c.scoreboard.GetShotClockTenths, 999, profile.ShotClockTenths},
+		{"shot clock below zero", c.SetShotClock, c.scoreboard.GetShotClockTenths, -1, 0},
+		{"shot clock in range", c.SetShotClock, c.scoreboard.GetShotClockTenths, 75, 75},
+	}
+	for _, tt := range tests {
+		if got := tt.set(tt.in, testActor); got != tt.want {
+			t.Errorf("%s: returned %d, want %d", tt.name, got, tt.want)
+		}
+		if got := tt.get(); got != tt.want {
+			t.Errorf("%s: clock shows %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"scoreboard-backend/internal/format"
+	"scoreboard-backend/internal/models"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrGameNotFound    = errors.New("game not found")
+	ErrClockNotReached = errors.New("the game clock never showed that time")
+	ErrStoreClosed     = errors.New("event store closed")
+	ErrCorruptLog      = errors.New("unreadable event in game log")
+)
+
+// gameIDPattern matches IDs from newGameID, which double as file names
+var gameIDPattern = regexp.MustCompile(`^\d{8}T\d{6}\.\d{3}Z$`)
+
+// newGameID names a game after the moment it started
+func newGameID(started time.Time) string {
+	return started.UTC().Format("20060102T150405.000Z")
+}
+
+// EventStore keeps one NDJSON event log per game in a directory. Events are
+// appended as they happen, so a game can be replayed after a crash.
+type EventStore struct {
+	dir    string
+	mutex  sync.Mutex
+	file   *os.File // open log of gameID
+	gameID string
+	closed bool
+}
+
+// NewEventStore creates dir if needed
+func NewEventStore(dir string) (*EventStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &EventStore{dir: dir}, nil
+}
+
+func (s *EventStore) path(gameID string) string {
+	return filepath.Join(s.dir, gameID+".ndjson")
+}
+
+// Append writes events to the end of a game's log
+func (s *EventStore) Append(gameID string, events []models.Event) error {
+	var data []byte
+	for _, e := range events {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return ErrStoreClosed
+	}
+	if s.gameID != gameID || s.file == nil {
+		if s.file != nil {
+			s.file.Close()
+			s.file = nil
+		}
+		f, err := os.OpenFile(s.path(gameID), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		s.file, s.gameID = f, gameID
+	}
+	_, err := s.file.Write(data)
+	return err
+}
+
+// Load reads a game's events in order. A last line cut short by a crash is
+// skipped; an unreadable line anywhere else fails with ErrCorruptLog.
+func (s *EventStore) Load(gameID string) ([]models.Event, error) {
+	if !gameIDPattern.MatchString(gameID) {
+		return nil, ErrGameNotFound
+	}
+	return ReadEvents(s.path(gameID))
+}
+
+// ReadEvents reads an NDJSON event log, such as one copied out of the games
+// directory. Only a last line without its newline may be unreadable, since
+// that is what a crash during a write leaves behind; it is skipped and logged.
+func ReadEvents(path string) ([]models.Event, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrGameNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var events []models.Event
+	lines := bytes.Split(data, []byte("\n"))
+	for n, line := range lines {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var e models.Event
+		if err := json.Unmarshal(line, &e); err != nil {
+			if n == len(lines)-1 {
+				slog.Warn("Skipping event cut short", "path", path, "line", n+1, "error", err)
+				break
+			}
+			return nil, fmt.Errorf("%w: %s line %d: %v", ErrCorruptLog, path, n+1, err)
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+// ParseEvents reads events from a JSON array or from NDJSON, one event per
+// line. Unlike ReadEvents it fails on the first unreadable event, since the
+// data comes from someone who can fix it.
+func ParseEvents(data []byte) ([]models.Event, error) {
+	data = bytes.TrimSpace(data)
+	var events []models.Event
+	if bytes.HasPrefix(data, []byte("[")) {
+		if err := json.Unmarshal(data, &events); err != nil {
+			return nil, fmt.Errorf("event array: %w", err)
+		}
+	} else {
+		for n, line := range bytes.Split(data, []byte("\n")) {
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			var e models.Event
+			if err := json.Unmarshal(line, &e); err != nil {
+				return nil, fmt.Errorf("line %d: %w", n+1, err)
+			}
+			events = append(events, e)
+		}
+	}
+	for i, e := range events {
+		if e.Type == "" {
+			return nil, fmt.Errorf("event %d has no type", i+1)
+		}
+	}
+	return events, nil
+}
+
+// List returns the IDs of every stored game, newest first
+func (s *EventStore) List() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	ids := []string{}
+	for _, entry := range entries {
+		id := strings.TrimSuffix(entry.Name(), ".ndjson")
+		if gameIDPattern.MatchString(id) && id != entry.Name() {
+			ids = append(ids, id)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(ids)))
+	return ids, nil
+}
+
+// Close closes the open game log. Later appends fail with ErrStoreClosed.
+func (s *EventStore) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.closed = true
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// Replay folds events from an empty state
+func Replay(events []models.Event) models.ScoreboardState {
+	var state models.ScoreboardState
+	for _, e := range events {
+		state = e.Apply(state)
+	}
+	return state
+}
+
+// StateAt replays events up to the moment the game clock showed tenths: every
+// event before the first one that takes the clock below it. Events while the
+// clock stood at that time, such as a score during a stoppage, are included.
+func StateAt(events []models.Event, tenths int) (models.ScoreboardState, error) {
+	n, err := eventsAt(events, tenths)
+	return Replay(events[:n]), err
+}
+
+// eventsAt counts the leading events StateAt replays
+func eventsAt(events []models.Event, tenths int) (int, error) {
+	var state models.ScoreboardState
+	for i, e := range events {
+		next := e.Apply(state)
+		if next.TimerTenths < tenths {
+			if i == 0 {
+				return 1, fmt.Errorf("%w: the game starts at %s", ErrClockNotReached, format.Precise(next.TimerTenths))
+			}
+			if state.TimerTenths >= tenths {
+				return i, nil
+			}
+		}
+		state = next
+	}
+	if len(events) == 0 || state.TimerTenths > tenths {
+		return len(events), fmt.Errorf("%w: the clock is at %s", ErrClockNotReached, format.Precise(state.TimerTenths))
+	}
+	return len(events), nil
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"errors"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"scoreboard-backend/internal/clock"
+	"scoreboard-backend/internal/models"
+	"scoreboard-backend/internal/rules"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventStoreAppendAfterClose(t *testing.T) {
+	store, err := NewEventStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	const game = "20240601T100000.000Z"
+	event := models.Event{Seq: 1, Type: models.EventScoreAdjusted}
+	if err := store.Append(game, []models.Event{event}); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+	event.Seq = 2
+	if err := store.Append(game, []models.Event{event}); !errors.Is(err, ErrStoreClosed) {
+		t.Errorf("Append after Close: got %v, want ErrStoreClosed", err)
+	}
+	events, err := store.Load(game)
+	if err != nil || len(events) != 1 {
+		t.Errorf("Load: %d events, %v; want only the one appended before Close", len(events), err)
+	}
+}
+
+func TestReadEventsCorruptLines(t *testing.T) {
+	good := `{"seq":1,"type":"game_started","state":{"timerTenths":6000,"shotClockTenths":120}}` + "\n" +
+		`{"seq":2,"type":"score_adjusted","team":"A","value":2}` + "\n"
+	tests := []struct {
+		name, data string
+		events     int
+		corrupt    string // the line ReadEvents must report, if any
+	}{
+		{"clean", good, 2, ""},
+		{"last line cut short", good + `{"seq":3,"type":"sco`, 2, ""},
+		{"blank lines", "\n" + good + "\n\n", 2, ""},
+		{"corrupt line in the middle", good + "garbage\n" + `{"seq":4,"type":"timer_ticked"}` + "\n", 0, "line 3"},
+		{"corrupt last line with its newline", good + `{"seq":3,"type":"sco` + "\n", 0, "line 3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "game.ndjson")
+			if err := os.WriteFile(path, []byte(tt.data), 0644); err != nil {
+				t.Fatal(err)
+			}
+			events, err := ReadEvents(path)
+			if tt.corrupt != "" {
+				if !errors.Is(err, ErrCorruptLog) || !strings.Contains(err.Error(), tt.corrupt) {
+					t.Errorf("got %v, want ErrCorruptLog at %s", err, tt.corrupt)
+				}
+				return
+			}
+			if err != nil || len(events) != tt.events {
+				t.Errorf("got %d events, %v; want %d", len(events), err, tt.events)
+			}
+		})
+	}
+}
+
+// TestReplayMatchesLiveState applies random command sequences and checks
+// after each command that the events fold to the live state, including after
+// a round trip through the store
+func TestReplayMatchesLiveState(t *testing.T) {
+	profile := rules.Default()
+	team := func(r *rand.Rand) string { return []string{models.TeamA, models.TeamB}[r.Intn(2)] }
+	commands := []func(s *ScoreboardService, r *rand.Rand){
+		func(s *ScoreboardService, r *rand.Rand) { s.AdjustScore(team(r), r.Intn(7)-2) },
+		func(s *ScoreboardService, r *rand.Rand) { s.SetScore(team(r), uint(r.Intn(int(profile.ScoreCap)+3))) },
+		func(s *ScoreboardService, r *rand.Rand) {
+			a, b := uint(r.Intn(25)), uint(r.Intn(25))
+			s.CorrectScores(&a, &b)
+		},
+		func(s *ScoreboardService, r *rand.Rand) { s.AdjustFoul(team(r), r.Intn(3)-1) },
+		func(s *ScoreboardService, r *rand.Rand) {
+			f := uint(r.Intn(12))
+			s.CorrectFouls(&f, nil)
+		},
+		func(s *ScoreboardService, r *rand.Rand) {
+			for n := r.Intn(40); n > 0; n-- {
+				s.DecrementTimerTenths()
+				s.DecrementShotClockTenths()
+			}
+		},
+		func(s *ScoreboardService, r *rand.Rand) { s.SetShotClockRunning(r.Intn(2) == 0) },
+		func(s *ScoreboardService, r *rand.Rand) { s.SetTimerTenths(r.Intn(profile.GameClockTenths + 1)) },
+		func(s *ScoreboardService, r *rand.Rand) { s.SetShotClockTenths(r.Intn(profile.ShotClockTenths + 1)) },
+		func(s *ScoreboardService, r *rand.Rand) {
+			game, shot := r.Intn(200)-100, r.Intn(60)-30
+			s.CorrectClocks(ClockAdjustment{GameClockDelta: &game, ShotClockDelta: &shot})
+		},
+		func(s *ScoreboardService, r *rand.Rand) { s.ResetShotClock() },
+		func(s *ScoreboardService, r *rand.Rand) { s.ResetTimerToDefault() },
+		func(s *ScoreboardService, r *rand.Rand) {
+			s.RestoreState(models.ScoreboardState{TimerTenths: r.Intn(6000), ScoreA: uint(r.Intn(20)), FoulB: uint(r.Intn(5)), IsShotClockRunning: true})
+		},
+	}
+
+	for seed := int64(1); seed <= 50; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		store, err := NewEventStore(t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := NewScoreboardServiceWithRules(profile, store, clock.NewFake(time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)))
+		for step := 0; step < 200; step++ {
+			commands[r.Intn(len(commands))](s, r)
+			if err := s.CheckReplay(); err != nil {
+				t.Fatalf("seed %d, step %d: %v", seed, step, err)
+			}
+		}
+		stored, err := store.Load(s.GameID())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := Replay(stored), s.GetState(); got != want {
+			t.Errorf("seed %d: stored events replay to %+v, live state is %+v", seed, got, want)
+		}
+		store.Close()
+	}
+}
+
+func TestStateAtBoundaries(t *testing.T) {
+	s := NewScoreboardServiceWithRules(rules.Default(), nil, clock.NewFake(time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)))
+	s.SetShotClockRunning(true)
+	tick := func(n int) {
+		for ; n > 0; n-- {
+			s.DecrementTimerTenths()
+			s.DecrementShotClockTenths()
+		}
+	}
+	tick(20)
+	s.AdjustScore(models.TeamA, 2) // at 09:58.0
+	tick(10)
+	s.SetShotClockRunning(false) // at 09:57.0
+	events := s.Events()
+
+	tests := []struct {
+		name       string
+		tenths     int
+		err        error
+		timer      int
+		shot       int
+		scoreA     uint
+		running    bool
+		replayedTo int // events StateAt replays
+	}{
+		{"before the first event", 6001, ErrClockNotReached, 6000, 120, 0, false, 1},
+		{"the first event", 6000, nil, 6000, 120, 0, true, 2},
+		{"a tenth before the score", 5981, nil, 5981, 101, 0, true, 40},
+		{"exactly at the score", 5980, nil, 5980, 100, 2, true, 43},
+		{"a tenth after the score", 5979, nil, 5979, 99, 2, true, 45},
+		{"exactly at the last event", 5970, nil, 5970, 90, 2, false, len(events)},
+		{"after the last event", 5969, ErrClockNotReached, 5970, 90, 2, false, len(events)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, err := StateAt(events, tt.tenths)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("error %v, want %v", err, tt.err)
+			}
+			if state.TimerTenths != tt.timer || state.ShotClockTenths != tt.shot || state.ScoreA != tt.scoreA || state.IsShotClockRunning != tt.running {
+				t.Errorf("state %+v, want clocks %d and %d, score %d, running %v", state, tt.timer, tt.shot, tt.scoreA, tt.running)
+			}
+			if n, _ := eventsAt(events, tt.tenths); n != tt.replayedTo {
+				t.Errorf("replays %d events, want %d", n, tt.replayedTo)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"scoreboard-backend/internal/format"
+	"scoreboard-backend/internal/models"
+	"strings"
+	"sync"
+)
+
+// GameLog is the persistent, human-readable log of score and foul changes
+// served by GET /api/log. Each line is prefixed with the game clock.
+type GameLog struct {
+	path  string
+	mutex sync.Mutex
+}
+
+func NewGameLog(path string) *GameLog {
+	return &GameLog{path: path}
+}
+
+// Record appends "<clock> | <field> changed to <value>" to the log. Changes
+// made by anything other than the REST API are tagged with their actor.
+func (l *GameLog) Record(timerTenths int, field string, value interface{}, actor models.Actor) {
+	entry := fmt.Sprintf("%s | %s changed to %v", format.LogClock(timerTenths), field, value)
+	if actor.ID != "" {
+		entry += fmt.Sprintf(" [%s:%s]", actor.Source, actor.ID)
+	}
+	l.append(entry)
+}
+
+func (l *GameLog) append(entry string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		slog.Error("Failed to open game log", "path", l.path, "error", err)
+		return
+	}
+	defer f.Close()
+	f.WriteString(entry + "\n")
+}
+
+// Change is one field of a correction, with its value before and after
+type Change struct {
+	Field  string
+	Before interface{}
+	After  interface{}
+}
+
+// RecordCorrection appends a single line for a correction that changed
+// several fields at once:
+// "<clock> | Correction: ScoreA 3 -> 5, ScoreB 2 -> 2 (reason) [source:id]".
+func (l *GameLog) RecordCorrection(timerTenths int, changes []Change, actor models.Actor) {
+	parts := make([]string, len(changes))
+	for i, c := range changes {
+		parts[i] = fmt.Sprintf("%s %v -> %v", c.Field, c.Before, c.After)
+	}
+	entry := fmt.Sprintf("%s | Correction: %s", format.LogClock(timerTenths), strings.Join(parts, ", "))
+	if actor.Reason != "" {
+		entry += fmt.Sprintf(" (%s)", actor.Reason)
+	}
+	if actor.ID != "" {
+		entry += fmt.Sprintf(" [%s:%s]", actor.Source, actor.ID)
+	}
+	l.append(entry)
+}
+
+// RecordNote appends a free-form line such as "<clock> | Review started (reason)"
+func (l *GameLog) RecordNote(timerTenths int, note string, actor models.Actor) {
+	entry := fmt.Sprintf("%s | %s", format.LogClock(timerTenths), note)
+	if actor.ID != "" {
+		entry += fmt.Sprintf(" [%s:%s]", actor.Source, actor.ID)
+	}
+	l.append(entry)
+}
+
+// Clear truncates the log, used when a new game starts.
+func (l *GameLog) Clear() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	os.WriteFile(l.path, []byte{}, 0644)
+}
+
+// Replace overwrites the log with lines, used when an archived game is restored.
+func (l *GameLog) Replace(lines []string) error {
+	var data strings.Builder
+	for _, line := range lines {
+		data.WriteString(line + "\n")
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return os.WriteFile(l.path, []byte(data.String()), 0644)
+}
+
+// CheckWritable verifies the log file can be appended to and its directory
+// accepts new files, without changing the log's contents.
+func (l *GameLog) CheckWritable() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	f.Close()
+	probe, err := os.CreateTemp(filepath.Dir(l.path), ".healthcheck-*")
+	if err != nil {
+		return err
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
+
+// Lines returns the non-empty log lines in order.
+func (l *GameLog) Lines() ([]string, error) {
+	l.mutex.Lock()
+	data, err := os.ReadFile(l.path)
+	l.mutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	lines := []string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
@@ -0,0 +1,301 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"scoreboard-backend/internal/clock"
+	"scoreboard-backend/internal/format"
+	"scoreboard-backend/internal/models"
+	"sync"
+	"time"
+)
+
+var (
+	ErrPlaybackActive      = errors.New("a playback is already running")
+	ErrNoPlayback          = errors.New("no playback is running")
+	ErrPlaybackSpeed       = errors.New("playback speed must be 1, 2 or 10")
+	ErrPlaybackEmpty       = errors.New("no events to play back")
+	ErrPlaybackNotStepping = errors.New("the playback is not in step mode")
+	ErrPlaybackLive        = errors.New("stop the clocks before starting a playback")
+)
+
+// PlaybackStopper ends a playback so the live game can have the displays
+// back. ControlService depends on this rather than on PlaybackService.
+type PlaybackStopper interface {
+	Stop() (Playback, error)
+}
+
+// PlaybackSpeeds are the speeds a timed playback can run at
+var PlaybackSpeeds = []int{1, 2, 10}
+
+// maxPlaybackGap caps the wait between two events, so a break in a recorded
+// game, or a restart it was resumed after, does not stall the playback
+const maxPlaybackGap = 30 * time.Second
+
+// Playback is an archived game being played back to the displays
+type Playback struct {
+	Source    string                 `json:"source"`          // Game ID, or "file" for uploaded events
+	Speed     int                    `json:"speed,omitempty"` // 0 when stepping
+	Step      bool                   `json:"step"`
+	Position  int                    `json:"position"` // Events played so far
+	Events    int                    `json:"events"`
+	StartedAt time.Time              `json:"startedAt"`
+	State     models.ScoreboardState `json:"state"` // What the displays show
+}
+
+type playbackRun struct {
+	status Playback
+	events []models.Event
+	cancel context.CancelFunc // nil when stepping
+}
+
+// PlaybackService broadcasts an archived game's events as if they were
+// happening live, either timed from the events or one step at a time. The
+// live game is left alone until someone changes it: ControlService stops the
+// playback first, so the displays never mix the two.
+type PlaybackService struct {
+	scoreboardService *ScoreboardService
+	timerService      *TimerService
+	websocketService  *WebSocketService
+	clock             clock.Clock
+	display           format.Rules
+	mutex             sync.Mutex
+	current           *playbackRun // nil when idle
+	onEnd             func()
+}
+
+func NewPlaybackService(scoreboardService *ScoreboardService, timerService *TimerService, websocketService *WebSocketService, clk clock.Clock, display format.Rules) *PlaybackService {
+	return &PlaybackService{
+		scoreboardService: scoreboardService,
+		timerService:      timerService,
+		websocketService:  websocketService,
+		clock:             clk,
+		display:           display,
+		onEnd:             func() {},
+	}
+}
+
+// OnEnd is called once a playback finishes or is stopped, to put the live
+// game back on the displays
+func (p *PlaybackService) OnEnd(fn func()) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.onEnd = fn
+}
+
+// Status returns the playback in progress, or nil
+func (p *PlaybackService) Status() *Playback {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.current == nil {
+		return nil
+	}
+	status := p.current.status
+	return &status
+}
+
+// Start broadcasts a state_sync with the state after the first event, then
+// plays the rest at speed, or waits for Step when step is set
+func (p *PlaybackService) Start(source string, events []models.Event, speed int, step bool) (Playback, error) {
+	if !step && !validPlaybackSpeed(speed) {
+		return Playback{}, ErrPlaybackSpeed
+	}
+	if len(events) == 0 {
+		return Playback{}, ErrPlaybackEmpty
+	}
+	// The shot clock can run on its own after StopTimer, and its ticks would
+	// go out in the middle of the playback
+	if p.timerService.IsRunning() || p.scoreboardService.IsShotClockRunning() {
+		return Playback{}, ErrPlaybackLive
+	}
+	p.mutex.Lock()
+	if p.current != nil {
+		p.mutex.Unlock()
+		return Playback{}, ErrPlaybackActive
+	}
+	run := &playbackRun{
+		status: Playback{Source: source, Step: step, Events: len(events), StartedAt: p.clock.Now().UTC()},
+		events: events,
+	}
+	if !step {
+		run.status.Speed = speed
+	}
+	p.current = run
+	run.status.State = events[0].Apply(models.ScoreboardState{})
+	run.status.Position = 1
+	p.websocketService.BroadcastMessage(models.NewMessage(p.stateSync(run.status.State)))
+	slog.Info("Playback started", "source", source, "events", len(events), "speed", speed, "step", step)
+	if len(events) == 1 {
+		return p.finish(run), nil
+	}
+	if !step {
+		ctx, cancel := context.WithCancel(context.Background())
+		run.cancel = cancel
+		go p.run(ctx, run)
+	}
+	status := run.status
+	p.mutex.Unlock()
+	return status, nil
+}
+
+// Step plays the next event of a step-by-step playback that is not a clock
+// tick, so each step is a basket, a foul, a clock starting or stopping or a
+// correction. The ticks before it are played too and broadcast as one
+// shot clock update with both clocks.
+func (p *PlaybackService) Step() (Playback, error) {
+	p.mutex.Lock()
+	run := p.current
+	if run == nil {
+		p.mutex.Unlock()
+		return Playback{}, ErrNoPlayback
+	}
+	if !run.status.Step {
+		p.mutex.Unlock()
+		return Playback{}, ErrPlaybackNotStepping
+	}
+	ticked := false
+	for run.status.Position < len(run.events) && isTick(run.events[run.status.Position]) {
+		p.apply(run)
+		ticked = true
+	}
+	if ticked {
+		p.websocketService.BroadcastMessage(models.NewMessage(p.clocksUpdate(run.status.State)))
+	}
+	if run.status.Position < len(run.events) {
+		p.advance(run)
+	}
+	if run.status.Position == len(run.events) {
+		return p.finish(run), nil
+	}
+	status := run.status
+	p.mutex.Unlock()
+	return status, nil
+}
+
+// Stop ends the playback where it is
+func (p *PlaybackService) Stop() (Playback, error) {
+	p.mutex.Lock()
+	run := p.current
+	if run == nil {
+		p.mutex.Unlock()
+		return Playback{}, ErrNoPlayback
+	}
+	if run.cancel != nil {
+		run.cancel()
+	}
+	return p.finish(run), nil
+}
+
+// run waits out the gap before each event, scaled by the speed
+func (p *PlaybackService) run(ctx context.Context, run *playbackRun) {
+	for {
+		p.mutex.Lock()
+		if ctx.Err() != nil {
+			p.mutex.Unlock()
+			return
+		}
+		if run.status.Position == len(run.events) {
+			p.finish(run)
+			return
+		}
+		gap := run.events[run.status.Position].Time.Sub(run.events[run.status.Position-1].Time)
+		p.mutex.Unlock()
+
+		if gap > maxPlaybackGap {
+			gap = maxPlaybackGap
+		}
+		if gap > 0 {
+			select {
+			case <-p.clock.After(gap / time.Duration(run.status.Speed)):
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		p.mutex.Lock()
+		if ctx.Err() == nil {
+			p.advance(run)
+		}
+		p.mutex.Unlock()
+	}
+}
+
+// advance applies and broadcasts the next event. Call with the mutex held.
+func (p *PlaybackService) advance(run *playbackRun) {
+	e := p.apply(run)
+	p.websocketService.BroadcastMessage(models.NewMessage(p.playbackMessage(e, run.status.State)))
+}
+
+// apply plays the next event without broadcasting it. Call with the mutex held.
+func (p *PlaybackService) apply(run *playbackRun) models.Event {
+	e := run.events[run.status.Position]
+	run.status.State = e.Apply(run.status.State)
+	run.status.Position++
+	return e
+}
+
+func isTick(e models.Event) bool {
+	return e.Type == models.EventTimerTicked || e.Type == models.EventShotClockTicked
+}
+
+// finish ends run, which must be current, with the mutex held and unlocks it
+// before handing the displays back to the live game
+func (p *PlaybackService) finish(run *playbackRun) Playback {
+	p.current = nil
+	onEnd := p.onEnd
+	status := run.status
+	p.mutex.Unlock()
+	slog.Info("Playback ended", "source", status.Source, "position", status.Position, "events", status.Events)
+	onEnd()
+	return status
+}
+
+// playbackMessage is the broadcast the live game sends for the command that
+// recorded e. A game clock tick, which the live game only shows with the next
+// shot clock tick, gets a timer_update, so a game clock running on its own
+// moves on the displays too.
+func (p *PlaybackService) playbackMessage(e models.Event, state models.ScoreboardState) models.Payload {
+	switch e.Type {
+	case models.EventScoreAdjusted, models.EventScoreSet:
+		return models.ScoreUpdateData{Team: e.Team, ScoreA: state.ScoreA, ScoreB: state.ScoreB}
+	case models.EventFoulAdjusted, models.EventFoulSet:
+		return models.FoulUpdateData{Team: e.Team, FoulA: state.FoulA, FoulB: state.FoulB}
+	case models.EventTimerSet, models.EventTimerReset, models.EventTimerTicked:
+		return models.TimerUpdateData{TimerTenths: state.TimerTenths, FormattedTimer: p.display.GameClock(state.TimerTenths)}
+	case models.EventShotClockSet, models.EventShotClockReset, models.EventShotClockTicked,
+		models.EventShotClockStarted, models.EventShotClockStopped:
+		return p.clocksUpdate(state)
+	default:
+		return p.stateSync(state)
+	}
+}
+
+// clocksUpdate is a shot clock update, which carries both clocks
+func (p *PlaybackService) clocksUpdate(state models.ScoreboardState) models.ShotClockUpdateData {
+	return models.ShotClockUpdateData{
+		ShotClockTenths:    state.ShotClockTenths,
+		IsShotClockRunning: state.IsShotClockRunning,
+		TimerTenths:        state.TimerTenths,
+		FormattedClocks:    p.display.Clocks(state.TimerTenths, state.ShotClockTenths),
+	}
+}
+
+// stateSync carries the live version, so a display that drops stale syncs
+// takes the playback's and then the live game's when the playback ends
+func (p *PlaybackService) stateSync(state models.ScoreboardState) models.StateSyncData {
+	return models.StateSyncData{
+		ScoreboardState: state,
+		FormattedClocks: p.display.Clocks(state.TimerTenths, state.ShotClockTenths),
+		Version:         p.scoreboardService.Snapshot().Version,
+	}
+}
+
+func validPlaybackSpeed(speed int) bool {
+	for _, s := range PlaybackSpeeds {
+		if speed == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"errors"
+	"scoreboard-backend/internal/clock"
+	"scoreboard-backend/internal/format"
+	"scoreboard-backend/internal/models"
+	"scoreboard-backend/internal/rules"
+	"testing"
+	"time"
+)
+
+// recordGame records a short game: the clocks run two seconds, team A scores,
+// they run another second and stop, then team B fouls
+func recordGame() []models.Event {
+	fake := clock.NewFake(testStart)
+	s := NewScoreboardServiceWithRules(rules.Default(), nil, fake)
+	tick := func(n int) {
+		for ; n > 0; n-- {
+			fake.Advance(100 * time.Millisecond)
+			s.DecrementTimerTenths()
+			s.DecrementShotClockTenths()
+		}
+	}
+	s.SetShotClockRunning(true)
+	tick(20)
+	s.AdjustScore(models.TeamA, 2)
+	tick(10)
+	s.SetShotClockRunning(false)
+	s.AdjustFoul(models.TeamB, 1)
+	return s.Events()
+}
+
+func clocksUpdate(timer, shot int, running bool) models.ShotClockUpdateData {
+	return models.ShotClockUpdateData{
+		ShotClockTenths:    shot,
+		IsShotClockRunning: running,
+		TimerTenths:        timer,
+		FormattedClocks:    testDisplay.Clocks(timer, shot),
+	}
+}
+
+var testDisplay = format.DefaultRules()
+
+func TestPlaybackStepsBetweenEvents(t *testing.T) {
+	c := newTestControl(t)
+	events := recordGame()
+	l := c.listen(t)
+	if _, err := c.playback.Start("file", events, 0, true); err != nil {
+		t.Fatal(err)
+	}
+	if sync, ok := l.next().(models.StateSyncData); !ok || sync.ScoreboardState != events[0].Apply(models.ScoreboardState{}) {
+		t.Fatalf("playback did not start with the game's first state: %+v", sync)
+	}
+
+	steps := []struct {
+		position   int
+		broadcasts []models.Payload
+	}{
+		{2, []models.Payload{clocksUpdate(6000, 120, true)}},
+		// 40 ticks in one update, then the basket
+		{43, []models.Payload{clocksUpdate(5980, 100, true), models.ScoreUpdateData{Team: models.TeamA, ScoreA: 2}}},
+		{64, []models.Payload{clocksUpdate(5970, 90, true), clocksUpdate(5970, 90, false)}},
+	}
+	for i, step := range steps {
+		status, err := c.playback.Step()
+		if err != nil {
+			t.Fatalf("step %d: %v", i+1, err)
+		}
+		if status.Position != step.position {
+			t.Errorf("step %d: at event %d, want %d", i+1, status.Position, step.position)
+		}
+		for _, want := range step.broadcasts {
+			if got := l.next(); got != want {
+				t.Errorf("step %d: broadcast %+v, want %+v", i+1, got, want)
+			}
+		}
+		l.none()
+	}
+
+	// The foul is the last event, after which the live game is back
+	status, err := c.playback.Step()
+	if err != nil || status.Position != len(events) {
+		t.Fatalf("last step: %+v, %v", status, err)
+	}
+	if got, want := l.next(), (models.FoulUpdateData{Team: models.TeamB, FoulB: 1}); got != want {
+		t.Errorf("broadcast %+v, want %+v", got, want)
+	}
+	if sync, ok := l.next().(models.StateSyncData); !ok || sync.ScoreboardState != c.scoreboard.GetState() {
+		t.Errorf("live game not put back: %+v", sync)
+	}
+	if c.playback.Status() != nil {
+		t.Error("playback still running after its last event")
+	}
+}
+
+func TestPlaybackBroadcastsGameClockTicks(t *testing.T) {
+	c := newTestControl(t)
+	fake := clock.NewFake(testStart)
+	recorded := NewScoreboardServiceWithRules(rules.Default(), nil, fake)
+	for i := 0; i < 3; i++ {
+		fake.Advance(100 * time.Millisecond)
+		recorded.DecrementTimerTenths() // The game clock alone
+	}
+
+	l := c.listen(t)
+	if _, err := c.playback.Start("file", recorded.Events(), 1, false); err != nil {
+		t.Fatal(err)
+	}
+	l.next() // state_sync
+	for tenths := 5999; tenths >= 5997; tenths-- {
+		waitFor(t, "the playback to wait for the next event", func() bool { return c.clock.Tickers() == 1 })
+		c.clock.Advance(100 * time.Millisecond)
+		if got, want := l.next(), (models.TimerUpdateData{TimerTenths: tenths, FormattedTimer: testDisplay.GameClock(tenths)}); got != want {
+			t.Errorf("broadcast %+v, want %+v", got, want)
+		}
+	}
+	if _, ok := l.next().(models.StateSyncData); !ok {
+		t.Error("live game not put back")
+	}
+}
+
+func TestLiveChangeStopsPlayback(t *testing.T) {
+	c := newTestControl(t)
+	events := recordGame()
+	if _, err := c.playback.Start("file", events, 0, true); err != nil {
+		t.Fatal(err)
+	}
+	c.playback.Step()
+	l := c.listen(t)
+
+	// The live game takes the displays back before the change goes out
+	if _, err := c.AdjustScore(models.TeamA, 1, testActor); err != nil {
+		t.Fatal(err)
+	}
+	if c.playback.Status() != nil {
+		t.Fatal("playback still running after a live change")
+	}
+	if sync, ok := l.next().(models.StateSyncData); !ok || sync.ScoreboardState.ScoreA != 0 || sync.IsShotClockRunning {
+		t.Errorf("first broadcast %+v, want the live state", sync)
+	}
+	if got, want := l.next(), (models.ScoreUpdateData{Team: models.TeamA, ScoreA: 1}); got != want {
+		t.Errorf("broadcast %+v, want %+v", got, want)
+	}
+
+	// Starting the clocks stops it too
+	if _, err := c.playback.Start("file", events, 0, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.StartClocks(testActor); err != nil {
+		t.Fatal(err)
+	}
+	if c.playback.Status() != nil || !c.timer.IsRunning() {
+		t.Errorf("StartClocks during a playback: playback %+v, clocks running %v", c.playback.Status(), c.timer.IsRunning())
+	}
+}
+
+func TestPlaybackRefusedWhileAClockRuns(t *testing.T) {
+	c := newTestControl(t)
+	if err := c.StartClocks(testActor); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.playback.Start("file", recordGame(), 0, true); !errors.Is(err, ErrPlaybackLive) {
+		t.Errorf("with both clocks running: got %v, want ErrPlaybackLive", err)
+	}
+
+	// The shot clock runs on alone, and its ticks would reach the playback
+	c.StopTimer(testActor)
+	if !c.scoreboard.IsShotClockRunning() {
+		t.Fatal("shot clock stopped with the game clock")
+	}
+	if _, err := c.playback.Start("file", recordGame(), 0, true); !errors.Is(err, ErrPlaybackLive) {
+		t.Errorf("with the shot clock running: got %v, want ErrPlaybackLive", err)
+	}
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"scoreboard-backend/internal/format"
+	"scoreboard-backend/internal/models"
+	"time"
+)
+
+// ResetTokenTTL is how long a reset confirmation token stays valid
+const ResetTokenTTL = 60 * time.Second
+
+var ErrResetTokenInvalid = errors.New("reset confirmation token is invalid or expired")
+
+// ResetConfirmation is returned by the first phase of a game reset
+type ResetConfirmation struct {
+	Token     string       `json:"token"`
+	ExpiresAt time.Time    `json:"expiresAt"`
+	Summary   ResetSummary `json:"summary"`
+}
+
+// ResetSummary describes what a reset would discard
+type ResetSummary struct {
+	State          models.ScoreboardState `json:"state"`
+	GameLogEntries int                    `json:"gameLogEntries"`
+	Archived       bool                   `json:"archived"`    // The game will be archived and can be restored
+	Description    string                 `json:"description"` // One line for a confirmation dialog
+}
+
+// PrepareReset issues a single-use token that ResetGame must be called with,
+// together with a summary of what the reset will discard.
+func (c *ControlService) PrepareReset(actor models.Actor) (ResetConfirmation, error) {
+	if c.inReview() {
+		return ResetConfirmation{}, ErrReviewActive
+	}
+	state := c.scoreboardService.GetState()
+	lines, err := c.gameLog.Lines()
+	if err != nil {
+		lines = nil // a missing log loses nothing
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ResetConfirmation{}, err
+	}
+	token := hex.EncodeToString(b)
+	now := c.clock.Now()
+	expires := now.Add(ResetTokenTTL)
+
+	c.resetMutex.Lock()
+	for t, exp := range c.resetTokens {
+		if now.After(exp) {
+			delete(c.resetTokens, t)
+		}
+	}
+	c.resetTokens[token] = expires
+	c.resetMutex.Unlock()
+
+	description := fmt.Sprintf("Score %d-%d, fouls %d-%d, %s on the game clock and %d log entries will be cleared.",
+		state.ScoreA, state.ScoreB, state.FoulA, state.FoulB, format.LogClock(state.TimerTenths), len(lines))
+	if c.archive != nil {
+		description += " The game will be archived and can be restored."
+	}
+	return ResetConfirmation{
+		Token:     token,
+		ExpiresAt: expires.UTC(),
+		Summary: ResetSummary{
+			State:          state,
+			GameLogEntries: len(lines),
+			Archived:       c.archive != nil,
+			Description:    description,
+		},
+	}, nil
+}
+
+// ResetGame redeems a token from PrepareReset, archives the game when
+// archiving is enabled, then stops the clocks and clears scores, fouls and
+// the game log. It returns the archive ID, empty when nothing was archived.
+// The token is only used up by a reset that happens, so after a refusal or a
+// failed archive the operator can try again with it.
+func (c *ControlService) ResetGame(token string, actor models.Actor) (string, error) {
+	c.endPlayback(actor)
+	// Held throughout, so two calls with one token can't both reset
+	c.resetMutex.Lock()
+	defer c.resetMutex.Unlock()
+	expires, ok := c.resetTokens[token]
+	if !ok || c.clock.Now().After(expires) {
+		delete(c.resetTokens, token)
+		return "", ErrResetTokenInvalid
+	}
+	if c.inReview() {
+		return "", ErrReviewActive
+	}
+
+	before := c.scoreboardService.GetState()
+	archiveID := ""
+	if c.archive != nil {
+		lines, _ := c.gameLog.Lines()
+		id, err := c.archive.Save(Archive{
+			Actor:  actor,
+			Reason: actor.Reason,
+			GameID: c.scoreboardService.GameID(),
+			State:  before,
+			Log:    lines,
+		})
+		if err != nil {
+			return "", fmt.Errorf("archive game before reset: %w", err)
+		}
+		archiveID = id
+	}
+	delete(c.resetTokens, token)
+
+	c.shotClock.Stop()
+	c.timerService.StopTimer()
+	_, after := c.scoreboardService.ResetAll()
+	c.gameLog.Clear()
+	c.logChange("Game", before, after.State, actor)
+	var details interface{}
+	if archiveID != "" {
+		details = map[string]string{"archiveId": archiveID}
+	}
+	c.audit(models.AuditGameReset, actor, before, after.State, details)
+	c.websocketService.BroadcastMessage(models.NewMessage(models.GameResetData{ScoreboardState: after.State, FormattedClocks: c.FormatClocks(after.State)}))
+	return archiveID, nil
+}
+
+// Archives lists archived games, newest first
+func (c *ControlService) Archives() ([]ArchiveSummary, error) {
+	if c.archive == nil {
+		return []ArchiveSummary{}, nil
+	}
+	return c.archive.List()
+}
+
+// RestoreArchive brings back a game saved by ResetGame: its state, with the
+// clocks stopped, and its game log. The current game is replaced. When the
+// archived game's events are stored, it becomes the current game again and
+// its event log carries on.
+func (c *ControlService) RestoreArchive(id string, actor models.Actor) (models.ScoreboardState, error) {
+	c.endPlayback(actor)
+	if c.archive == nil {
+		return models.ScoreboardState{}, ErrArchiveNotFound
+	}
+	if c.inReview() {
+		return models.ScoreboardState{}, ErrReviewActive
+	}
+	archive, err := c.archive.Load(id)
+	if err != nil {
+		return models.ScoreboardState{}, err
+	}
+
+	before := c.scoreboardService.GetState()
+	c.shotClock.Stop()
+	c.timerService.StopTimer()
+	if archive.GameID != "" {
+		if err := c.scoreboardService.ResumeGame(archive.GameID); err != nil {
+			slog.Warn("Archived game has no event log, restoring its state into the current game", "gameId", archive.GameID, "error", err)
+		}
+	}
+	_, after := c.scoreboardService.RestoreState(archive.State)
+	if err := c.gameLog.Replace(archive.Log); err != nil {
+		return models.ScoreboardState{}, fmt.Errorf("restore game log: %w", err)
+	}
+	c.logChange("Game", before, after.State, actor)
+	c.audit(models.AuditGameRestore, actor, before, after.State, map[string]string{"archiveId": id})
+	c.SyncState()
+	return after.State, nil
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"scoreboard-backend/internal/format"
+	"scoreboard-backend/internal/models"
+	"time"
+)
+
+var (
+	ErrReviewActive  = errors.New("a review is in progress")
+	ErrNoReview      = errors.New("no review is in progress")
+	ErrEventNotFound = errors.New("no such game event")
+	ErrReviewReason  = errors.New("a review needs a reason")
+	ErrReviewOutcome = errors.New("a review needs an outcome")
+)
+
+// Review is an instant replay review in progress. The clocks stay frozen
+// until it ends.
+type Review struct {
+	Reason    string                 `json:"reason"`
+	StartedAt time.Time              `json:"startedAt"`
+	Actor     models.Actor           `json:"actor"`
+	Before    models.ScoreboardState `json:"before"`              // State when the review started
+	RewoundTo uint64                 `json:"rewoundTo,omitempty"` // Seq of the game event rewound to, if any
+}
+
+// ReviewEvent is one game event with the state it left the game in
+type ReviewEvent struct {
+	Event uint64                 `json:"event"` // The event's seq, which RewindReview takes
+	Time  time.Time              `json:"time"`
+	Type  string                 `json:"type"`
+	Team  string                 `json:"team,omitempty"`
+	Value int                    `json:"value,omitempty"`
+	State models.ScoreboardState `json:"state"`
+}
+
+// Rewind is the point a review rewinds to: a game event by seq, or the
+// moment the game clock showed GameClock tenths, as StateAt finds it
+type Rewind struct {
+	Event     uint64
+	GameClock *int
+}
+
+// Review returns the review in progress, or nil
+func (c *ControlService) Review() *Review {
+	c.reviewMutex.Lock()
+	defer c.reviewMutex.Unlock()
+	if c.review == nil {
+		return nil
+	}
+	review := *c.review
+	return &review
+}
+
+// StartReview stops both clocks and puts the game into review. Clocks cannot
+// be started again until EndReview.
+func (c *ControlService) StartReview(reason string, actor models.Actor) (Review, error) {
+	c.endPlayback(actor)
+	if reason == "" {
+		return Review{}, ErrReviewReason
+	}
+	c.reviewMutex.Lock()
+	defer c.reviewMutex.Unlock()
+	if c.review != nil {
+		return Review{}, ErrReviewActive
+	}
+	c.StopClocks(actor)
+	before := c.scoreboardService.GetState()
+	c.review = &Review{Reason: reason, StartedAt: c.clock.Now().UTC(), Actor: actor, Before: before}
+	c.gameLog.RecordNote(before.TimerTenths, fmt.Sprintf("Review started (%s)", reason), actor)
+	c.logChange("Review", models.ReviewStatusLive, models.ReviewStatusReview, actor)
+	c.audit(models.AuditReviewStart, actor, before, before, map[string]interface{}{"reason": reason})
+	c.websocketService.BroadcastMessage(models.NewMessage(c.reviewUpdate()))
+	return *c.review, nil
+}
+
+// ReviewEvents replays the current game's events, returning the state after
+// each one. Clock ticks are left out; a rewind to a moment between two
+// listed events takes a game clock time instead.
+func (c *ControlService) ReviewEvents() []ReviewEvent {
+	var list []ReviewEvent
+	var state models.ScoreboardState
+	for _, e := range c.scoreboardService.Events() {
+		state = e.Apply(state)
+		if e.Type == models.EventTimerTicked || e.Type == models.EventShotClockTicked {
+			continue
+		}
+		list = append(list, ReviewEvent{Event: e.Seq, Time: e.Time, Type: e.Type, Team: e.Team, Value: e.Value, State: state})
+	}
+	return list
+}
+
+// RewindReview reverts the clocks, scores and fouls to what they were just
+// after a game event, or when the game clock showed a time. Both come from
+// the game's event log, so the clocks are exact to the tenth. It is only
+// allowed during a review, and is recorded as a correction.
+func (c *ControlService) RewindReview(to Rewind, actor models.Actor) (models.ScoreboardState, error) {
+	c.endPlayback(actor)
+	c.reviewMutex.Lock()
+	if c.review == nil {
+		c.reviewMutex.Unlock()
+		return models.ScoreboardState{}, ErrNoReview
+	}
+	events := c.scoreboardService.Events()
+	n, err := rewindEvents(events, to)
+	if err != nil {
+		c.reviewMutex.Unlock()
+		return models.ScoreboardState{}, err
+	}
+	target := Replay(events[:n])
+	restoreBefore, restoreAfter := c.scoreboardService.RestoreState(target)
+	before, after := restoreBefore.State, restoreAfter.State
+
+	changes := []Change{
+		{Field: "GameClock", Before: format.Precise(before.TimerTenths), After: format.Precise(target.TimerTenths)},
+		{Field: "ShotClock", Before: format.PreciseShotClock(before.ShotClockTenths), After: format.PreciseShotClock(target.ShotClockTenths)},
+	}
+	for _, f := range []struct {
+		field         string
+		before, after uint
+	}{
+		{"ScoreA", before.ScoreA, target.ScoreA},
+		{"ScoreB", before.ScoreB, target.ScoreB},
+		{"FoulA", before.FoulA, target.FoulA},
+		{"FoulB", before.FoulB, target.FoulB},
+	} {
+		if f.before != f.after {
+			changes = append(changes, Change{Field: f.field, Before: f.before, After: f.after})
+		}
+	}
+	seq := events[n-1].Seq
+	reason := fmt.Sprintf("rewind to event %d", seq)
+	if to.GameClock != nil {
+		reason = fmt.Sprintf("rewind to %s", format.Precise(*to.GameClock))
+	}
+	if actor.Reason != "" {
+		reason += ": " + actor.Reason
+	}
+	actor.Reason = reason
+	c.correct(models.AuditReviewRewind, before, after, changes, actor)
+	c.review.RewoundTo = seq
+	c.reviewMutex.Unlock()
+	return c.SyncState(), nil
+}
+
+// rewindEvents counts the leading events that lead to the rewind point
+func rewindEvents(events []models.Event, to Rewind) (int, error) {
+	if to.GameClock != nil {
+		n, err := eventsAt(events, *to.GameClock)
+		if err != nil {
+			return 0, err
+		}
+		return n, nil
+	}
+	for i, e := range events {
+		if e.Seq == to.Event {
+			return i + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %d", ErrEventNotFound, to.Event)
+}
+
+// EndReview records the outcome and returns the game to live play. With
+// resumeClocks the clocks restart straight away; otherwise they stay stopped
+// until the operator starts them.
+func (c *ControlService) EndReview(outcome string, resumeClocks bool, actor models.Actor) (Review, error) {
+	c.endPlayback(actor)
+	if outcome == "" {
+		return Review{}, ErrReviewOutcome
+	}
+	c.reviewMutex.Lock()
+	review := c.review
+	if review == nil {
+		c.reviewMutex.Unlock()
+		return Review{}, ErrNoReview
+	}
+	c.review = nil
+	c.reviewMutex.Unlock()
+
+	state := c.scoreboardService.GetState()
+	c.gameLog.RecordNote(state.TimerTenths, fmt.Sprintf("Review ended: %s", outcome), actor)
+	c.logChange("Review", models.ReviewStatusReview, models.ReviewStatusLive, actor)
+	c.audit(models.AuditReviewEnd, actor, review.Before, state, map[string]interface{}{
+		"reason":    review.Reason,
+		"outcome":   outcome,
+		"startedAt": review.StartedAt,
+		"rewoundTo": review.RewoundTo,
+	})
+	c.websocketService.BroadcastMessage(models.NewMessage(models.ReviewUpdateData{
+		Status:    models.ReviewStatusLive,
+		Reason:    review.Reason,
+		Outcome:   outcome,
+		RewoundTo: review.RewoundTo,
+	}))
+	if resumeClocks {
+		return *review, c.StartClocks(actor)
+	}
+	return *review, nil
+}
+
+// inReview reports whether a review is in progress
+func (c *ControlService) inReview() bool {
+	c.reviewMutex.Lock()
+	defer c.reviewMutex.Unlock()
+	return c.review != nil
+}
+
+// reviewUpdate describes the review in progress; callers hold reviewMutex
+func (c *ControlService) reviewUpdate() models.ReviewUpdateData {
+	if c.review == nil {
+		return models.ReviewUpdateData{Status: models.ReviewStatusLive}
+	}
+	return models.ReviewUpdateData{
+		Status:    models.ReviewStatusReview,
+		Reason:    c.review.Reason,
+		RewoundTo: c.review.RewoundTo,
+	}
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"errors"
+	"scoreboard-backend/internal/models"
+	"testing"
+	"time"
+)
+
+// play runs the clocks for d a tenth at a time, waiting for both clocks to
+// apply each tick, so the event log has them in step as on a real court
+func (c *testControl) play(t *testing.T, d time.Duration) {
+	t.Helper()
+	for n := int(d / (100 * time.Millisecond)); n > 0; n-- {
+		timer, shot := c.scoreboard.GetTimerTenths()-1, c.scoreboard.GetShotClockTenths()-1
+		c.clock.Advance(100 * time.Millisecond)
+		waitFor(t, "the clocks to catch up", func() bool {
+			return c.scoreboard.GetTimerTenths() <= timer && c.scoreboard.GetShotClockTenths() <= shot
+		})
+	}
+}
+
+func TestRewindToTheMiddleOfAPossession(t *testing.T) {
+	c := newTestControl(t)
+	if err := c.StartClocks(testActor); err != nil {
+		t.Fatal(err)
+	}
+	c.play(t, 3700*time.Millisecond)
+	if _, err := c.AdjustScore(models.TeamA, 2, testActor); err != nil {
+		t.Fatal(err)
+	}
+	c.play(t, 2200*time.Millisecond)
+	if _, err := c.StartReview("shot at the buzzer", testActor); err != nil {
+		t.Fatal(err)
+	}
+	live := c.scoreboard.GetState()
+	if live.TimerTenths != 5941 || live.ShotClockTenths != 61 {
+		t.Fatalf("clocks %d and %d before the review, want 5941 and 61", live.TimerTenths, live.ShotClockTenths)
+	}
+
+	// Ticks are not rewind points, but the score is, with the clocks to the tenth
+	var scored ReviewEvent
+	for _, e := range c.ReviewEvents() {
+		if e.Type == models.EventTimerTicked || e.Type == models.EventShotClockTicked {
+			t.Fatalf("tick %d listed", e.Event)
+		}
+		if e.Type == models.EventScoreAdjusted {
+			scored = e
+		}
+	}
+	if scored.State.TimerTenths != 5963 || scored.State.ShotClockTenths != 83 || scored.State.ScoreA != 2 {
+		t.Fatalf("score event state %+v", scored.State)
+	}
+
+	// Halfway between the tip-off and the basket
+	at := 5985
+	state, err := c.RewindReview(Rewind{GameClock: &at}, testActor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Both clocks tick at the same instant, so the shot clock may have ticked
+	// on to 10.4 before the game clock left 09:58.5
+	if state.TimerTenths != 5985 || (state.ShotClockTenths != 105 && state.ShotClockTenths != 104) || state.ScoreA != 0 || state.IsShotClockRunning {
+		t.Errorf("rewound to 09:58.5: %+v, want 5985 and 105 or 104, no score, clocks stopped", state)
+	}
+	if got := c.Review().RewoundTo; got == 0 || got >= scored.Event {
+		t.Errorf("rewound to event %d, want one before the basket (%d)", got, scored.Event)
+	}
+
+	// Back to just after the basket, with the clocks stopped
+	state, err = c.RewindReview(Rewind{Event: scored.Event}, testActor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := scored.State
+	want.IsShotClockRunning = false
+	if state != want {
+		t.Errorf("rewound to event %d: %+v, want %+v", scored.Event, state, want)
+	}
+	if got := c.scoreboard.GetState(); got != want {
+		t.Errorf("live state %+v after the rewind", got)
+	}
+}
+
+func TestRewindErrors(t *testing.T) {
+	c := newTestControl(t)
+	if _, err := c.RewindReview(Rewind{Event: 1}, testActor); !errors.Is(err, ErrNoReview) {
+		t.Errorf("outside a review: got %v, want ErrNoReview", err)
+	}
+	if _, err := c.StartReview("check", testActor); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.RewindReview(Rewind{Event: 9999}, testActor); !errors.Is(err, ErrEventNotFound) {
+		t.Errorf("unknown event: got %v, want ErrEventNotFound", err)
+	}
+	at := 3000
+	if _, err := c.RewindReview(Rewind{GameClock: &at}, testActor); !errors.Is(err, ErrClockNotReached) {
+		t.Errorf("a time the clock never showed: got %v, want ErrClockNotReached", err)
+	}
+}
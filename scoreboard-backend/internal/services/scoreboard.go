@@ -1,152 +1,276 @@
 package services
 
 import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"scoreboard-backend/internal/clock"
 	"scoreboard-backend/internal/models"
+	"scoreboard-backend/internal/rules"
 	"sync"
-	"sync/atomic"
 )
 
+// ScoreboardService is the game as an event-sourced aggregate. Every change
+// is a command that records one or more events; the state is the fold of the
+// current game's events, so it can always be explained and replayed.
+//
+// One lock guards everything. Commands run under it from reading the state
+// to applying their events, so reads never see half of a change and two
+// commands never act on the same stale state.
 type ScoreboardService struct {
-	state           models.ScoreboardState
-	mutex           sync.RWMutex
-	atomicTimer     int64 // atomic value for TimerTenths
-	atomicShotClock int64 // atomic value for ShotClockTenths
-	atomicFoulA     int64 // atomic value for FoulA
-	atomicFoulB     int64 // atomic value for FoulB
+	rules          rules.Profile
+	clock          clock.Clock // stamps events and names games
+	store          *EventStore // nil keeps events in memory only
+	mutex          sync.RWMutex
+	version        uint64 // bumped by every change, across games
+	gameID         string
+	state          models.ScoreboardState // fold of events
+	events         []models.Event
+	persisted      int  // events[:persisted] are in the store
+	persistFailing bool // the last write to the store failed
 }
 
+// Scoreboard is the game as ControlService changes it. ScoreboardService is
+// the implementation; ControlService depends on this so a test can swap in
+// a fake.
+type Scoreboard interface {
+	Rules() rules.Profile
+	Snapshot() Snapshot
+	GetState() models.ScoreboardState
+	GameID() string
+	Events() []models.Event
+
+	AdjustScore(team string, delta int) (before, after uint, err error)
+	SetScore(team string, score uint) (before, after Snapshot, err error)
+	CorrectScores(scoreA, scoreB *uint) (before, after Snapshot, err error)
+	AdjustFoul(team string, delta int) (before, after uint, err error)
+	CorrectFouls(foulA, foulB *uint) (before, after Snapshot, err error)
+
+	GetTimerTenths() int
+	SetTimerTenths(tenths int) (before, after Snapshot)
+	SetShotClockTenths(tenths int) (before, after Snapshot)
+	ResetShotClock() (before, after Snapshot)
+	CorrectClocks(adj ClockAdjustment) (before, after Snapshot, clamped bool)
+
+	ResetAll() (before, after Snapshot)
+	RestoreState(state models.ScoreboardState) (before, after Snapshot)
+	ResumeGame(gameID string) error
+}
+
+// Snapshot is the state as of one version. Versions only go up, including
+// across a reset, so of two snapshots the one with the higher version is newer.
+type Snapshot struct {
+	Version uint64                 `json:"version"`
+	GameID  string                 `json:"gameId"`
+	State   models.ScoreboardState `json:"state"`
+}
+
+// Command decides from the current state which events to record. It runs
+// under the write lock and must not call back into the service.
+type Command func(state models.ScoreboardState) ([]models.Event, error)
+
 func NewScoreboardService() *ScoreboardService {
-	service := &ScoreboardService{
-		state: models.ScoreboardState{
-			TimerTenths:        10 * 60 * 10, // 10:00 in tenths
-			ScoreA:             0,
-			ScoreB:             0,
-			FoulA:              0,
-			FoulB:              0,
-			ShotClockTenths:    12 * 10, // 12s in tenths
-			IsShotClockRunning: false,
-		},
-	}
-	atomic.StoreInt64(&service.atomicTimer, int64(service.state.TimerTenths))
-	atomic.StoreInt64(&service.atomicShotClock, int64(service.state.ShotClockTenths))
-	atomic.StoreInt64(&service.atomicFoulA, 0)
-	atomic.StoreInt64(&service.atomicFoulB, 0)
+	return NewScoreboardServiceWithRules(rules.Default(), nil, clock.Real{})
+}
+
+// NewScoreboardServiceWithRules starts a game with the clocks of the given
+// profile. Events are written to store when it is not nil, starting with the
+// game's first change, so a game replaced by ResumeGame leaves nothing behind.
+func NewScoreboardServiceWithRules(profile rules.Profile, store *EventStore, clk clock.Clock) *ScoreboardService {
+	service := &ScoreboardService{rules: profile, clock: clk, store: store}
+	service.startGame()
 	return service
 }
 
+// Rules returns the rules profile the game is played under
+func (s *ScoreboardService) Rules() rules.Profile {
+	return s.rules
+}
+
+// Snapshot returns the state with its version and game
+func (s *ScoreboardService) Snapshot() Snapshot {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.snapshot()
+}
+
+// Execute runs a command and records its events. On error nothing is
+// recorded. It returns the snapshots just before and just after the command.
+func (s *ScoreboardService) Execute(cmd Command) (before, after Snapshot, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	before = s.snapshot()
+	events, err := cmd(s.state)
+	if err != nil {
+		return before, before, err
+	}
+	s.record(events...)
+	return before, s.snapshot(), nil
+}
+
+// GameID identifies the current game in the event store
+func (s *ScoreboardService) GameID() string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.gameID
+}
+
+// Events returns a copy of the current game's events
+func (s *ScoreboardService) Events() []models.Event {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return append([]models.Event(nil), s.events...)
+}
+
 func (s *ScoreboardService) GetState() models.ScoreboardState {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
-	s.state.TimerTenths = int(atomic.LoadInt64(&s.atomicTimer))
-	s.state.FoulA = uint(atomic.LoadInt64(&s.atomicFoulA))
-	s.state.FoulB = uint(atomic.LoadInt64(&s.atomicFoulB))
 	return s.state
 }
 
-func (s *ScoreboardService) SetTimerTenths(tenths int) {
-	atomic.StoreInt64(&s.atomicTimer, int64(tenths))
-	s.mutex.Lock()
-	s.state.TimerTenths = tenths
-	s.mutex.Unlock()
+// SetTimerTenths sets the game clock to an exact value
+func (s *ScoreboardService) SetTimerTenths(tenths int) (before, after Snapshot) {
+	return s.recordOne(models.Event{Type: models.EventTimerSet, Value: tenths})
 }
 
 func (s *ScoreboardService) GetTimerTenths() int {
-	return int(atomic.LoadInt64(&s.atomicTimer))
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.state.TimerTenths
 }
 
 func (s *ScoreboardService) DecrementTimerTenths() int {
-	newVal := atomic.AddInt64(&s.atomicTimer, -1)
-	if newVal < 0 {
-		atomic.StoreInt64(&s.atomicTimer, 0)
-		newVal = 0
-	}
 	s.mutex.Lock()
-	s.state.TimerTenths = int(newVal)
-	s.mutex.Unlock()
-	return int(newVal)
+	defer s.mutex.Unlock()
+	if s.state.TimerTenths > 0 {
+		s.record(models.Event{Type: models.EventTimerTicked})
+	}
+	return s.state.TimerTenths
 }
 
-func (s *ScoreboardService) ResetTimerToTenMinutes() {
-	atomic.StoreInt64(&s.atomicTimer, 10*60*10)
-	s.mutex.Lock()
-	s.state.TimerTenths = 10 * 60 * 10
-	s.mutex.Unlock()
+// ResetTimerToDefault sets the game clock to the profile's starting value
+func (s *ScoreboardService) ResetTimerToDefault() (before, after Snapshot) {
+	return s.recordOne(models.Event{Type: models.EventTimerReset, Value: s.rules.GameClockTenths})
 }
 
-func (s *ScoreboardService) IncrementScoreA() uint {
-	s.mutex.Lock()
-	s.state.ScoreA++
-	val := s.state.ScoreA
-	s.mutex.Unlock()
-	return val
+// AdjustScore adds delta to a team's score, never going below zero, and
+// returns the score before and after. A raise the rules profile does not
+// allow changes nothing and returns an error wrapping rules.ErrLimit.
+func (s *ScoreboardService) AdjustScore(team string, delta int) (before, after uint, err error) {
+	b, a, err := s.Execute(func(state models.ScoreboardState) ([]models.Event, error) {
+		next := int(teamScore(state, team)) + delta
+		if next < 0 {
+			next = 0
+		}
+		if err := s.checkScore(state, team, uint(next)); err != nil {
+			return nil, err
+		}
+		return []models.Event{{Type: models.EventScoreAdjusted, Team: team, Value: next}}, nil
+	})
+	return teamScore(b.State, team), teamScore(a.State, team), err
 }
-func (s *ScoreboardService) IncrementScoreB() uint {
-	s.mutex.Lock()
-	s.state.ScoreB++
-	val := s.state.ScoreB
-	s.mutex.Unlock()
-	return val
+
+// SetScore overwrites one team's score, checked like AdjustScore
+func (s *ScoreboardService) SetScore(team string, score uint) (before, after Snapshot, err error) {
+	return s.Execute(func(state models.ScoreboardState) ([]models.Event, error) {
+		if err := s.checkScore(state, team, score); err != nil {
+			return nil, err
+		}
+		return []models.Event{{Type: models.EventScoreSet, Team: team, Value: int(score)}}, nil
+	})
 }
-func (s *ScoreboardService) DecrementScoreA() uint {
-	s.mutex.Lock()
-	if s.state.ScoreA > 0 {
-		s.state.ScoreA--
+
+// checkScore checks a team's new score against the rules profile. Lowering
+// a score is always allowed, so a state the profile rejects can be fixed.
+func (s *ScoreboardService) checkScore(state models.ScoreboardState, team string, score uint) error {
+	if score <= teamScore(state, team) {
+		return nil
 	}
-	val := s.state.ScoreA
-	s.mutex.Unlock()
-	return val
-}
-func (s *ScoreboardService) DecrementScoreB() uint {
-	s.mutex.Lock()
-	if s.state.ScoreB > 0 {
-		s.state.ScoreB--
+	if team == models.TeamB {
+		return s.rules.CheckScores(state.ScoreA, score)
 	}
-	val := s.state.ScoreB
-	s.mutex.Unlock()
-	return val
-}
-func (s *ScoreboardService) SetScoreA(score uint) {
-	s.mutex.Lock()
-	s.state.ScoreA = score
-	s.mutex.Unlock()
+	return s.rules.CheckScores(score, state.ScoreB)
 }
-func (s *ScoreboardService) SetScoreB(score uint) {
-	s.mutex.Lock()
-	s.state.ScoreB = score
-	s.mutex.Unlock()
+
+// CorrectScores sets either or both scores, leaving a nil one as it is, after
+// checking the result against the rules profile
+func (s *ScoreboardService) CorrectScores(scoreA, scoreB *uint) (before, after Snapshot, err error) {
+	return s.Execute(func(state models.ScoreboardState) ([]models.Event, error) {
+		a, b := state.ScoreA, state.ScoreB
+		if scoreA != nil {
+			a = *scoreA
+		}
+		if scoreB != nil {
+			b = *scoreB
+		}
+		if err := s.rules.CheckScores(a, b); err != nil {
+			return nil, err
+		}
+		return []models.Event{
+			{Type: models.EventScoreSet, Team: models.TeamA, Value: int(a)},
+			{Type: models.EventScoreSet, Team: models.TeamB, Value: int(b)},
+		}, nil
+	})
 }
 
-// Shot clock atomic methods
-func (s *ScoreboardService) SetShotClockTenths(tenths int) {
-	atomic.StoreInt64(&s.atomicShotClock, int64(tenths))
-	s.mutex.Lock()
-	s.state.ShotClockTenths = tenths
-	s.mutex.Unlock()
+// SetShotClockTenths sets the shot clock to an exact value
+func (s *ScoreboardService) SetShotClockTenths(tenths int) (before, after Snapshot) {
+	return s.recordOne(models.Event{Type: models.EventShotClockSet, Value: tenths})
 }
 func (s *ScoreboardService) GetShotClockTenths() int {
-	return int(atomic.LoadInt64(&s.atomicShotClock))
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.state.ShotClockTenths
 }
 func (s *ScoreboardService) DecrementShotClockTenths() int {
-	newVal := atomic.AddInt64(&s.atomicShotClock, -1)
-	if newVal < 0 {
-		atomic.StoreInt64(&s.atomicShotClock, 0)
-		newVal = 0
-	}
 	s.mutex.Lock()
-	s.state.ShotClockTenths = int(newVal)
-	s.mutex.Unlock()
-	return int(newVal)
+	defer s.mutex.Unlock()
+	if s.state.ShotClockTenths > 0 {
+		s.record(models.Event{Type: models.EventShotClockTicked})
+	}
+	return s.state.ShotClockTenths
 }
-func (s *ScoreboardService) ResetShotClock() {
-	atomic.StoreInt64(&s.atomicShotClock, 12*10)
-	s.mutex.Lock()
-	s.state.ShotClockTenths = 12 * 10
-	s.mutex.Unlock()
+
+// CorrectClocks applies a clock adjustment, clamped to the rules profile,
+// and reports whether any value had to be clamped. It is safe while the
+// clocks are ticking: no tick is lost between reading and writing.
+func (s *ScoreboardService) CorrectClocks(adj ClockAdjustment) (before, after Snapshot, clamped bool) {
+	before, after, _ = s.Execute(func(state models.ScoreboardState) ([]models.Event, error) {
+		var events []models.Event
+		if adj.GameClockDelta != nil || adj.GameClock != nil {
+			tenths, wasClamped := s.rules.ClampGameClock(adjustedValue(state.TimerTenths, adj.GameClockDelta, adj.GameClock))
+			clamped = clamped || wasClamped
+			events = append(events, models.Event{Type: models.EventTimerAdjusted, Value: tenths})
+		}
+		if adj.ShotClockDelta != nil || adj.ShotClock != nil {
+			tenths, wasClamped := s.rules.ClampShotClock(adjustedValue(state.ShotClockTenths, adj.ShotClockDelta, adj.ShotClock))
+			clamped = clamped || wasClamped
+			events = append(events, models.Event{Type: models.EventShotClockAdjusted, Value: tenths})
+		}
+		return events, nil
+	})
+	return before, after, clamped
+}
+
+func (s *ScoreboardService) ResetShotClock() (before, after Snapshot) {
+	return s.recordOne(models.Event{Type: models.EventShotClockReset, Value: s.rules.ShotClockTenths})
 }
-func (s *ScoreboardService) SetShotClockRunning(running bool) {
+
+// SetShotClockRunning starts or stops the shot clock and reports whether it
+// was running. Nothing is recorded when it is already in that state.
+func (s *ScoreboardService) SetShotClockRunning(running bool) (wasRunning bool) {
 	s.mutex.Lock()
-	s.state.IsShotClockRunning = running
-	s.mutex.Unlock()
+	defer s.mutex.Unlock()
+	wasRunning = s.state.IsShotClockRunning
+	if wasRunning == running {
+		return wasRunning
+	}
+	if running {
+		s.record(models.Event{Type: models.EventShotClockStarted})
+	} else {
+		s.record(models.Event{Type: models.EventShotClockStopped})
+	}
+	return wasRunning
 }
 func (s *ScoreboardService) IsShotClockRunning() bool {
 	s.mutex.RLock()
@@ -154,73 +278,213 @@ func (s *ScoreboardService) IsShotClockRunning() bool {
 	return s.state.IsShotClockRunning
 }
 
-func (s *ScoreboardService) IncrementFoulA() uint {
-	newVal := atomic.AddInt64(&s.atomicFoulA, 1)
-	s.mutex.Lock()
-	s.state.FoulA = uint(newVal)
-	s.mutex.Unlock()
-	return uint(newVal)
-}
-func (s *ScoreboardService) DecrementFoulA() uint {
-	var newVal int64
-	for {
-		old := atomic.LoadInt64(&s.atomicFoulA)
-		if old > 0 {
-			if atomic.CompareAndSwapInt64(&s.atomicFoulA, old, old-1) {
-				newVal = old - 1
-				break
+// AdjustFoul adds delta to a team's foul count, never going below zero, and
+// returns the count before and after. A raise above the profile's maximum
+// changes nothing and returns an error wrapping rules.ErrLimit.
+func (s *ScoreboardService) AdjustFoul(team string, delta int) (before, after uint, err error) {
+	b, a, err := s.Execute(func(state models.ScoreboardState) ([]models.Event, error) {
+		next := int(teamFouls(state, team)) + delta
+		if next < 0 {
+			next = 0
+		}
+		if uint(next) > teamFouls(state, team) {
+			if err := s.rules.CheckFouls(uint(next), 0); err != nil {
+				return nil, err
 			}
-		} else {
-			newVal = 0
-			break
 		}
-	}
+		return []models.Event{{Type: models.EventFoulAdjusted, Team: team, Value: next}}, nil
+	})
+	return teamFouls(b.State, team), teamFouls(a.State, team), err
+}
+
+// CorrectFouls sets either or both foul counts like CorrectScores
+func (s *ScoreboardService) CorrectFouls(foulA, foulB *uint) (before, after Snapshot, err error) {
+	return s.Execute(func(state models.ScoreboardState) ([]models.Event, error) {
+		a, b := state.FoulA, state.FoulB
+		if foulA != nil {
+			a = *foulA
+		}
+		if foulB != nil {
+			b = *foulB
+		}
+		if err := s.rules.CheckFouls(a, b); err != nil {
+			return nil, err
+		}
+		return []models.Event{
+			{Type: models.EventFoulSet, Team: models.TeamA, Value: int(a)},
+			{Type: models.EventFoulSet, Team: models.TeamB, Value: int(b)},
+		}, nil
+	})
+}
+
+// ResetAll ends the current game and starts a new one with a new ID
+func (s *ScoreboardService) ResetAll() (before, after Snapshot) {
 	s.mutex.Lock()
-	s.state.FoulA = uint(newVal)
-	s.mutex.Unlock()
-	return uint(newVal)
+	defer s.mutex.Unlock()
+	before = s.snapshot()
+	s.startGame()
+	s.persist()
+	return before, s.snapshot()
+}
+
+// RestoreState replaces the whole state, e.g. with one saved at shutdown.
+// Clocks always come back stopped.
+func (s *ScoreboardService) RestoreState(state models.ScoreboardState) (before, after Snapshot) {
+	return s.recordOne(models.Event{Type: models.EventStateRestored, State: &state})
 }
-func (s *ScoreboardService) IncrementFoulB() uint {
-	newVal := atomic.AddInt64(&s.atomicFoulB, 1)
+
+// ResumeGame makes a stored game current again, with its state rebuilt from
+// its events. Clocks come back stopped.
+func (s *ScoreboardService) ResumeGame(gameID string) error {
+	if s.store == nil {
+		return ErrGameNotFound
+	}
+	events, err := s.store.Load(gameID)
+	if err != nil {
+		return err
+	}
+	if len(events) == 0 {
+		return ErrGameNotFound
+	}
 	s.mutex.Lock()
-	s.state.FoulB = uint(newVal)
-	s.mutex.Unlock()
-	return uint(newVal)
-}
-func (s *ScoreboardService) DecrementFoulB() uint {
-	var newVal int64
-	for {
-		old := atomic.LoadInt64(&s.atomicFoulB)
-		if old > 0 {
-			if atomic.CompareAndSwapInt64(&s.atomicFoulB, old, old-1) {
-				newVal = old - 1
-				break
-			}
-		} else {
-			newVal = 0
-			break
+	defer s.mutex.Unlock()
+	s.version++
+	s.gameID = gameID
+	s.events = events
+	s.persisted = len(events)
+	s.state = Replay(events)
+	if s.state.IsShotClockRunning {
+		s.record(models.Event{Type: models.EventShotClockStopped})
+	}
+	return nil
+}
+
+// GameEvents returns the events of the current game or of a stored one
+func (s *ScoreboardService) GameEvents(gameID string) ([]models.Event, error) {
+	if gameID == s.GameID() {
+		return s.Events(), nil
+	}
+	if s.store == nil {
+		return nil, ErrGameNotFound
+	}
+	return s.store.Load(gameID)
+}
+
+// Games lists stored game IDs, newest first, always including the current game
+func (s *ScoreboardService) Games() ([]string, error) {
+	current := s.GameID()
+	ids := []string{current}
+	if s.store == nil {
+		return ids, nil
+	}
+	stored, err := s.store.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range stored {
+		if id != current {
+			ids = append(ids, id)
 		}
 	}
-	s.mutex.Lock()
-	s.state.FoulB = uint(newVal)
-	s.mutex.Unlock()
-	return uint(newVal)
+	return ids, nil
 }
 
-func (s *ScoreboardService) ResetAll() {
-	atomic.StoreInt64(&s.atomicTimer, 10*60*10)
-	atomic.StoreInt64(&s.atomicShotClock, 12*10)
-	atomic.StoreInt64(&s.atomicFoulA, 0)
-	atomic.StoreInt64(&s.atomicFoulB, 0)
-	s.mutex.Lock()
-	s.state = models.ScoreboardState{
-		TimerTenths:        10 * 60 * 10,
-		ScoreA:             0,
-		ScoreB:             0,
-		FoulA:              0,
-		FoulB:              0,
-		ShotClockTenths:    12 * 10,
-		IsShotClockRunning: false,
-	}
-	s.mutex.Unlock()
+// CheckReplay reports an error when replaying the current game's events does
+// not give the live state, which would mean a change bypassed the event log.
+func (s *ScoreboardService) CheckReplay() error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if replayed := Replay(s.events); !reflect.DeepEqual(replayed, s.state) {
+		return fmt.Errorf("replay of %d events gives %+v, live state is %+v", len(s.events), replayed, s.state)
+	}
+	return nil
+}
+
+// recordOne records a single event that does not depend on the state
+func (s *ScoreboardService) recordOne(e models.Event) (before, after Snapshot) {
+	before, after, _ = s.Execute(func(models.ScoreboardState) ([]models.Event, error) {
+		return []models.Event{e}, nil
+	})
+	return before, after
+}
+
+// snapshot copies the state; callers hold the mutex
+func (s *ScoreboardService) snapshot() Snapshot {
+	return Snapshot{Version: s.version, GameID: s.gameID, State: s.state}
+}
+
+// startGame begins a new game with the profile's clocks, without writing it
+// to the store yet; callers hold the mutex
+func (s *ScoreboardService) startGame() {
+	start := models.ScoreboardState{
+		TimerTenths:     s.rules.GameClockTenths,
+		ShotClockTenths: s.rules.ShotClockTenths,
+	}
+	now := s.clock.Now().UTC()
+	started := models.Event{Seq: 1, Time: now, Type: models.EventGameStarted, State: &start}
+	s.version++
+	s.gameID = newGameID(now)
+	s.events = []models.Event{started}
+	s.persisted = 0
+	s.state = started.Apply(models.ScoreboardState{})
+}
+
+// record applies events to the state and appends them to the game's log;
+// callers hold the mutex
+func (s *ScoreboardService) record(events ...models.Event) {
+	if len(events) == 0 {
+		return
+	}
+	now := s.clock.Now().UTC()
+	for _, e := range events {
+		e.Seq = uint64(len(s.events) + 1)
+		e.Time = now
+		s.state = e.Apply(s.state)
+		s.events = append(s.events, e)
+	}
+	s.version++
+	s.persist()
+}
+
+// persist writes events not yet in the store. A failed write is retried with
+// the next event, and logged once until it succeeds again.
+func (s *ScoreboardService) persist() {
+	if s.store == nil {
+		s.persisted = len(s.events)
+		return
+	}
+	if err := s.store.Append(s.gameID, s.events[s.persisted:]); err != nil {
+		if !s.persistFailing {
+			slog.Error("Failed to write game events", "gameId", s.gameID, "error", err)
+		}
+		s.persistFailing = true
+		return
+	}
+	if s.persistFailing {
+		slog.Info("Writing game events again", "gameId", s.gameID)
+	}
+	s.persistFailing = false
+	s.persisted = len(s.events)
+}
+
+func teamScore(state models.ScoreboardState, team string) uint {
+	if team == models.TeamB {
+		return state.ScoreB
+	}
+	return state.ScoreA
+}
+
+func teamFouls(state models.ScoreboardState, team string) uint {
+	if team == models.TeamB {
+		return state.FoulB
+	}
+	return state.FoulA
+}
+
+// adjustedValue applies either a delta or an absolute value to current
+func adjustedValue(current int, delta, value *int) int {
+	if value != nil {
+		return *value
+	}
+	return current + *delta
 }
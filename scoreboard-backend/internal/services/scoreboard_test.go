@@ -0,0 +1,137 @@
+package services
+
+import (
+	"scoreboard-backend/internal/models"
+	"scoreboard-backend/internal/rules"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestConcurrentScoreFoulAndClockOperations hammers the scoreboard from many
+// goroutines while both clocks tick. Run it with -race.
+func TestConcurrentScoreFoulAndClockOperations(t *testing.T) {
+	profile, err := rules.Lookup("practice") // No score cap
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newTestControlWithRules(t, profile)
+	const (
+		scorers, scores = 8, 50
+		foulers, fouls  = 4, 25
+		ticks           = 100
+	)
+	if err := c.StartClocks(testActor); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < scorers; i++ {
+		wg.Add(1)
+		go func(team string) {
+			defer wg.Done()
+			for j := 0; j < scores; j++ {
+				if _, err := c.AdjustScore(team, 1, testActor); err != nil {
+					t.Error(err)
+				}
+			}
+		}([]string{models.TeamA, models.TeamB}[i%2])
+	}
+	for i := 0; i < foulers; i++ {
+		wg.Add(1)
+		go func(team string) {
+			defer wg.Done()
+			for j := 0; j < fouls; j++ {
+				if _, err := c.AdjustFoul(team, 1, testActor); err != nil {
+					t.Error(err)
+				}
+			}
+		}([]string{models.TeamA, models.TeamB}[i%2])
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < ticks; i++ {
+			c.clock.Advance(100 * time.Millisecond)
+		}
+	}()
+
+	// Every snapshot a reader sees is newer than the last and never undoes a change
+	done := make(chan struct{})
+	readerDone := make(chan struct{})
+	go func() {
+		defer close(readerDone)
+		last := c.scoreboard.Snapshot()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			snap := c.scoreboard.Snapshot()
+			if snap.Version < last.Version {
+				t.Errorf("version went from %d back to %d", last.Version, snap.Version)
+			}
+			s, l := snap.State, last.State
+			if s.ScoreA < l.ScoreA || s.ScoreB < l.ScoreB || s.FoulA < l.FoulA || s.FoulB < l.FoulB ||
+				s.TimerTenths > l.TimerTenths || s.ShotClockTenths > l.ShotClockTenths {
+				t.Errorf("state went from %+v back to %+v", l, s)
+			}
+			if snap.Version == last.Version && s != l {
+				t.Errorf("two states at version %d: %+v and %+v", snap.Version, l, s)
+			}
+			last = snap
+		}
+	}()
+
+	wg.Wait()
+	waitFor(t, "the last ticks", func() bool {
+		s := c.scoreboard.GetState()
+		return s.TimerTenths == profile.GameClockTenths-ticks && s.ShotClockTenths == profile.ShotClockTenths-ticks
+	})
+	c.StopClocks(testActor)
+	close(done)
+	<-readerDone
+
+	state := c.scoreboard.GetState()
+	want := models.ScoreboardState{
+		TimerTenths:     profile.GameClockTenths - ticks,
+		ShotClockTenths: profile.ShotClockTenths - ticks,
+		ScoreA:          scorers / 2 * scores,
+		ScoreB:          scorers / 2 * scores,
+		FoulA:           foulers / 2 * fouls,
+		FoulB:           foulers / 2 * fouls,
+	}
+	if state != want {
+		t.Errorf("final state %+v, want %+v", state, want)
+	}
+
+	counts := map[string]int{}
+	events := c.scoreboard.Events()
+	for i, e := range events {
+		if e.Seq != uint64(i+1) {
+			t.Fatalf("event %d has seq %d", i+1, e.Seq)
+		}
+		counts[e.Type]++
+	}
+	wantCounts := map[string]int{
+		models.EventGameStarted:      1,
+		models.EventScoreAdjusted:    scorers * scores,
+		models.EventFoulAdjusted:     foulers * fouls,
+		models.EventTimerTicked:      ticks,
+		models.EventShotClockTicked:  ticks,
+		models.EventShotClockStarted: 1,
+		models.EventShotClockStopped: 1,
+	}
+	for typ, n := range wantCounts {
+		if counts[typ] != n {
+			t.Errorf("%d %s events, want %d", counts[typ], typ, n)
+		}
+	}
+	if len(counts) != len(wantCounts) {
+		t.Errorf("unexpected event types: %v", counts)
+	}
+	if err := c.scoreboard.CheckReplay(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"scoreboard-backend/internal/clock"
+	"scoreboard-backend/internal/metrics"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ShotClock runs the shot clock down. ControlService and the health checks
+// depend on this rather than on ShotClockService, so either can be driven by
+// a fake in tests.
+type ShotClock interface {
+	// Start fails with ErrShotClockRunning or ErrShotClockZero
+	Start() error
+	// Stop reports whether the shot clock was running
+	Stop() bool
+	IsRunning() bool
+	CheckAlive(maxAge time.Duration) error
+	// OnTick is called with the remaining tenths after every tick but the last
+	OnTick(fn func(remaining int))
+	// OnExpire is called once the shot clock reaches zero and has stopped
+	OnExpire(fn func())
+}
+
+// ShotClockService owns the one goroutine and ticker that run the shot clock
+// down, the same way TimerService runs the game clock. Each start gets its
+// own context; Stop cancels it under the same lock a tick decrements under,
+// so no tick lands after Stop returns and two runs never overlap.
+type ShotClockService struct {
+	scoreboardService *ScoreboardService
+	clock             clock.Clock
+	mutex             sync.Mutex
+	cancel            context.CancelFunc // nil when stopped
+	onTick            func(remaining int)
+	onExpire          func()
+	lastTick          int64 // atomic unix nanos of the last tick, or of the start
+}
+
+func NewShotClockService(scoreboardService *ScoreboardService, clk clock.Clock) *ShotClockService {
+	return &ShotClockService{
+		scoreboardService: scoreboardService,
+		clock:             clk,
+		onTick:            func(int) {},
+		onExpire:          func() {},
+	}
+}
+
+func (s *ShotClockService) OnTick(fn func(remaining int)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.onTick = fn
+}
+
+func (s *ShotClockService) OnExpire(fn func()) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.onExpire = fn
+}
+
+func (s *ShotClockService) Start() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.cancel != nil {
+		return ErrShotClockRunning
+	}
+	if s.scoreboardService.GetShotClockTenths() == 0 {
+		return ErrShotClockZero
+	}
+	s.scoreboardService.SetShotClockRunning(true)
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	atomic.StoreInt64(&s.lastTick, s.clock.Now().UnixNano())
+	go s.run(ctx, s.clock.NewTicker(100*time.Millisecond)) // 1/10s
+	return nil
+}
+
+func (s *ShotClockService) Stop() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.cancel == nil {
+		// Also clears a running flag left by a restored state
+		s.scoreboardService.SetShotClockRunning(false)
+		return false
+	}
+	s.cancel()
+	s.cancel = nil
+	s.scoreboardService.SetShotClockRunning(false)
+	return true
+}
+
+func (s *ShotClockService) IsRunning() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.cancel != nil
+}
+
+// CheckAlive reports an error when the shot clock should be running but its
+// goroutine has not ticked within maxAge.
+func (s *ShotClockService) CheckAlive(maxAge time.Duration) error {
+	if !s.IsRunning() {
+		return nil
+	}
+	since := s.clock.Since(time.Unix(0, atomic.LoadInt64(&s.lastTick)))
+	if since > maxAge {
+		return fmt.Errorf("shot clock is running but last ticked %s ago", since.Round(time.Millisecond))
+	}
+	return nil
+}
+
+func (s *ShotClockService) run(ctx context.Context, ticker clock.Ticker) {
+	defer ticker.Stop()
+	tracker := metrics.NewTickTracker("shot", 100*time.Millisecond, s.clock.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C():
+			tracker.Tick(now)
+			remaining, expired, ok := s.tick(ctx, now)
+			if !ok {
+				return
+			}
+			onTick, onExpire := s.callbacks()
+			if expired {
+				onExpire()
+				return
+			}
+			onTick(remaining)
+		}
+	}
+}
+
+// tick takes a tenth off the shot clock unless the run was stopped in the
+// meantime. ok is false when the run is over.
+func (s *ShotClockService) tick(ctx context.Context, now time.Time) (remaining int, expired, ok bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if ctx.Err() != nil {
+		return 0, false, false
+	}
+	if !s.scoreboardService.IsShotClockRunning() {
+		// The state was replaced, e.g. by a reset, with the clock stopped
+		s.cancel()
+		s.cancel = nil
+		return 0, false, false
+	}
+	atomic.StoreInt64(&s.lastTick, now.UnixNano())
+	remaining = s.scoreboardService.DecrementShotClockTenths()
+	if remaining == 0 {
+		s.cancel()
+		s.cancel = nil
+		s.scoreboardService.SetShotClockRunning(false)
+		return 0, true, true
+	}
+	return remaining, false, true
+}
+
+// callbacks returns the registered callbacks, which run outside the lock so
+// they may call Stop or Start
+func (s *ShotClockService) callbacks() (func(int), func()) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.onTick, s.onExpire
+}
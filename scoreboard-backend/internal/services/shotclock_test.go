@@ -0,0 +1,193 @@
+package services
+
+import (
+	"errors"
+	"scoreboard-backend/internal/models"
+	"sync"
+	"testing"
+	"time"
+)
+
+// shotClockUpdate waits for the next broadcast and checks it is a shot clock
+// update with the given clock
+func shotClockUpdate(t *testing.T, l listener, tenths int, running bool) models.ShotClockUpdateData {
+	t.Helper()
+	update, ok := l.next().(models.ShotClockUpdateData)
+	if !ok {
+		t.Fatalf("broadcast is not a shot clock update")
+	}
+	if update.ShotClockTenths != tenths || update.IsShotClockRunning != running {
+		t.Fatalf("shot clock update %d, running %v; want %d, %v", update.ShotClockTenths, update.IsShotClockRunning, tenths, running)
+	}
+	return update
+}
+
+func TestShotClockTicksAndExpires(t *testing.T) {
+	c := newTestControl(t)
+	l := c.listen(t)
+	if err := c.StartClocks(testActor); err != nil {
+		t.Fatal(err)
+	}
+	start := shotClockUpdate(t, l, 120, true)
+	if want := c.FormatClocks(models.ScoreboardState{TimerTenths: 6000, ShotClockTenths: 120}); start.FormattedClocks != want {
+		t.Errorf("start broadcast %+v, want %+v", start.FormattedClocks, want)
+	}
+
+	for i := 1; i < 120; i++ {
+		if n := c.clock.Advance(100 * time.Millisecond); n != 2 {
+			t.Fatalf("tick %d: %d tickers received it, want 2", i, n)
+		}
+		update := shotClockUpdate(t, l, 120-i, true)
+		if want := c.display.ShotClock(120 - i); update.FormattedShotclock != want {
+			t.Fatalf("tick %d: shot clock shows %q, want %q", i, update.FormattedShotclock, want)
+		}
+		waitFor(t, "the game clock tick", func() bool { return c.scoreboard.GetTimerTenths() == 6000-i })
+		if got := c.scoreboard.GetShotClockTenths(); got != 120-i {
+			t.Fatalf("tick %d: shot clock %d", i, got)
+		}
+	}
+
+	// The last tick stops both clocks and is broadcast once, not running
+	c.clock.Advance(100 * time.Millisecond)
+	shotClockUpdate(t, l, 0, false)
+	waitFor(t, "the game clock to stop", func() bool { return !c.timer.IsRunning() })
+	state := c.scoreboard.GetState()
+	// The game clock ticks on its own goroutine, so its tick at the same
+	// moment may land before or after the expiry stops it
+	if state.ShotClockTenths != 0 || state.IsShotClockRunning || state.TimerTenths < 5880 || state.TimerTenths > 5881 {
+		t.Errorf("state after expiry %+v", state)
+	}
+	waitFor(t, "both tickers to stop", func() bool { return c.clock.Tickers() == 0 })
+	c.clock.Advance(time.Second)
+	l.none()
+	if got := c.scoreboard.GetState(); got != state {
+		t.Errorf("clocks moved after expiry: %+v, was %+v", got, state)
+	}
+
+	// A shot clock at zero cannot start, and displays are told why
+	if err := c.StartClocks(testActor); !errors.Is(err, ErrShotClockZero) {
+		t.Errorf("start at zero: got %v, want ErrShotClockZero", err)
+	}
+	shotClockUpdate(t, l, 0, false)
+	if c.timer.IsRunning() {
+		t.Error("game clock started with the shot clock at zero")
+	}
+}
+
+func TestShotClockStartAndStop(t *testing.T) {
+	c := newTestControl(t)
+	if err := c.shot.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.shot.Start(); !errors.Is(err, ErrShotClockRunning) {
+		t.Errorf("second start: got %v, want ErrShotClockRunning", err)
+	}
+	if n := c.clock.Advance(300 * time.Millisecond); n != 3 {
+		t.Errorf("%d ticks received, want 3 from one run", n)
+	}
+	waitFor(t, "three ticks", func() bool { return c.scoreboard.GetShotClockTenths() == 117 })
+
+	if !c.shot.Stop() || c.shot.Stop() {
+		t.Error("Stop did not report the clock running exactly once")
+	}
+	c.clock.Advance(time.Second)
+	waitFor(t, "the ticker to stop", func() bool { return c.clock.Tickers() == 0 })
+	if got := c.scoreboard.GetState(); got.ShotClockTenths != 117 || got.IsShotClockRunning || c.timer.IsRunning() {
+		t.Errorf("state after Stop %+v", got)
+	}
+}
+
+// TestShotClockStartStopRace starts and stops the shot clock from several
+// goroutines while it ticks. Every tick must be recorded against a run, and
+// no run may outlive the last Stop.
+func TestShotClockStartStopRace(t *testing.T) {
+	c := newTestControl(t)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if err := c.shot.Start(); err != nil && !errors.Is(err, ErrShotClockRunning) && !errors.Is(err, ErrShotClockZero) {
+					t.Error(err)
+				}
+				c.shot.Stop()
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			c.clock.Advance(100 * time.Millisecond)
+		}
+	}()
+	wg.Wait()
+	c.shot.Stop()
+	waitFor(t, "every ticker to stop", func() bool { return c.clock.Tickers() == 0 })
+
+	ticks, running := 0, false
+	for _, e := range c.scoreboard.Events() {
+		switch e.Type {
+		case models.EventShotClockTicked:
+			if !running {
+				t.Fatalf("event %d: tick while stopped", e.Seq)
+			}
+			ticks++
+		case models.EventShotClockStarted, models.EventShotClockStopped:
+			if started := e.Type == models.EventShotClockStarted; started == running {
+				t.Fatalf("event %d: %s twice in a row", e.Seq, e.Type)
+			}
+			running = !running
+		}
+	}
+	if state := c.scoreboard.GetState(); state.ShotClockTenths != 120-ticks || state.IsShotClockRunning || running {
+		t.Errorf("state %+v after %d ticks", state, ticks)
+	}
+}
+
+func TestShotClockResetFromZeroRestartsClocks(t *testing.T) {
+	c := newTestControl(t)
+	c.SetShotClock(2, testActor)
+	if err := c.StartClocks(testActor); err != nil {
+		t.Fatal(err)
+	}
+	c.clock.Advance(200 * time.Millisecond)
+	waitFor(t, "the shot clock to expire", func() bool { return !c.timer.IsRunning() && c.scoreboard.GetShotClockTenths() == 0 })
+	waitFor(t, "both tickers to stop", func() bool { return c.clock.Tickers() == 0 })
+	l := c.listen(t)
+
+	// Play goes on after the violation
+	c.ResetShotClock(testActor)
+	shotClockUpdate(t, l, 120, true)
+	if !c.shot.IsRunning() || !c.timer.IsRunning() {
+		t.Fatal("reset from zero did not restart both clocks")
+	}
+	timer := c.scoreboard.GetTimerTenths()
+	c.clock.Advance(100 * time.Millisecond)
+	shotClockUpdate(t, l, 119, true)
+	waitFor(t, "the game clock tick", func() bool { return c.scoreboard.GetTimerTenths() == timer-1 })
+
+	// A reset while the shot clock is not at zero leaves the clocks as they are
+	c.StopClocks(testActor)
+	shotClockUpdate(t, l, 119, false)
+	c.ResetShotClock(testActor)
+	shotClockUpdate(t, l, 120, false)
+	if c.shot.IsRunning() || c.timer.IsRunning() {
+		t.Error("reset from 11.9 started the clocks")
+	}
+}
+
+func TestShotClockResetFromZeroDuringReview(t *testing.T) {
+	c := newTestControl(t)
+	c.SetShotClock(0, testActor)
+	if _, err := c.StartReview("check", testActor); err != nil {
+		t.Fatal(err)
+	}
+	l := c.listen(t)
+	c.ResetShotClock(testActor)
+	shotClockUpdate(t, l, 120, false)
+	if c.shot.IsRunning() || c.timer.IsRunning() {
+		t.Error("reset from zero started the clocks during a review")
+	}
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"scoreboard-backend/internal/clock"
+	"scoreboard-backend/internal/models"
+	"time"
+)
+
+// SavedState is the on-disk form of the scoreboard written at shutdown
+type SavedState struct {
+	SavedAt time.Time              `json:"savedAt"`
+	GameID  string                 `json:"gameId,omitempty"` // Game whose events led to State
+	State   models.ScoreboardState `json:"state"`
+}
+
+// SaveState writes the state to path through a temporary file and a rename,
+// so a crash mid-write never leaves a truncated file behind. SavedAt is read
+// from clk.
+func SaveState(path, gameID string, state models.ScoreboardState, clk clock.Clock) error {
+	data, err := json.MarshalIndent(SavedState{SavedAt: clk.Now().UTC(), GameID: gameID, State: state}, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadState reads a state written by SaveState. It returns an error wrapping
+// os.ErrNotExist when nothing was saved.
+func LoadState(path string) (SavedState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return SavedState{}, err
+	}
+	var saved SavedState
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return SavedState{}, err
+	}
+	return saved, nil
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"path/filepath"
+	"scoreboard-backend/internal/clock"
+	"scoreboard-backend/internal/models"
+	"testing"
+	"time"
+)
+
+func TestSaveStateRoundTrip(t *testing.T) {
+	fake := clock.NewFake(testStart)
+	fake.Advance(90 * time.Second)
+	path := filepath.Join(t.TempDir(), "state.json")
+	state := models.ScoreboardState{TimerTenths: 4312, ScoreA: 7, ScoreB: 5, FoulB: 2, ShotClockTenths: 83}
+	if err := SaveState(path, "game-1", state, fake); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := SavedState{SavedAt: testStart.Add(90 * time.Second).UTC(), GameID: "game-1", State: state}
+	if !saved.SavedAt.Equal(want.SavedAt) || saved.GameID != want.GameID || saved.State != want.State {
+		t.Errorf("loaded %+v, want %+v", saved, want)
+	}
+}
+
+func TestAuditEntriesUseTheClock(t *testing.T) {
+	c := newTestControl(t)
+	c.clock.Advance(3 * time.Second)
+	c.SetTimer(3000, testActor)
+	entries, err := c.auditLog.Entries(AuditQuery{Action: models.AuditTimerSet})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := c.scoreboard.Events()
+	if len(entries) != 1 || !entries[0].Time.Equal(events[len(events)-1].Time) || !entries[0].Time.Equal(c.clock.Now()) {
+		t.Errorf("audit entries %+v, want one at %s, the time of the event", entries, c.clock.Now())
+	}
+}
@@ -1,82 +1,131 @@
 package services
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"log/slog"
+	"scoreboard-backend/internal/clock"
+	"scoreboard-backend/internal/format"
+	"scoreboard-backend/internal/metrics"
+	"sync"
 	"sync/atomic"
 	"time"
 )
 
+// GameClock runs the game clock down. ControlService depends on this rather
+// than on TimerService, like it does on ShotClock.
+type GameClock interface {
+	StartTimer() error
+	StopTimer() error
+	IsRunning() bool
+	// ResetTimer stops the game clock and puts it back to the profile's value
+	ResetTimer() (before, after Snapshot, err error)
+}
+
+// TimerService owns the goroutine and ticker that run the game clock down.
+// Like ShotClockService, each start gets its own context and a tick only
+// decrements under the lock Stop cancels under.
 type TimerService struct {
 	scoreboardService *ScoreboardService
 	websocketService  *WebSocketService
-	ticker            *time.Ticker
-	stopChan          chan bool
-	isRunning         int32 // atomic flag
+	clock             clock.Clock
+	mutex             sync.Mutex
+	cancel            context.CancelFunc // nil when stopped
+	lastTick          int64              // atomic unix nanos of the last tick, or of the start
 }
 
-func NewTimerService(scoreboardService *ScoreboardService, websocketService *WebSocketService) *TimerService {
+func NewTimerService(scoreboardService *ScoreboardService, websocketService *WebSocketService, clk clock.Clock) *TimerService {
 	return &TimerService{
 		scoreboardService: scoreboardService,
 		websocketService:  websocketService,
-		stopChan:          make(chan bool),
+		clock:             clk,
 	}
 }
 
 func (t *TimerService) StartTimer() error {
-	if !atomic.CompareAndSwapInt32(&t.isRunning, 0, 1) {
-		log.Println("Timer is already running")
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.cancel != nil {
+		slog.Debug("Timer is already running")
 		return nil
 	}
-
-	// Always create a new stopChan for each timer start
-	t.stopChan = make(chan bool)
-	t.ticker = time.NewTicker(100 * time.Millisecond) // 1/10s
-	go func(stopChan chan bool) {
-		lastValue := t.scoreboardService.GetTimerTenths()
-		for {
-			select {
-			case <-t.ticker.C:
-				remaining := t.scoreboardService.DecrementTimerTenths()
-				if remaining != lastValue {
-					lastValue = remaining
-					if remaining == 0 {
-						t.StopTimer()
-						return
-					}
-				}
-			case <-stopChan:
-				t.ticker.Stop()
-				return
-			}
-		}
-	}(t.stopChan)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+	atomic.StoreInt64(&t.lastTick, t.clock.Now().UnixNano())
+	go t.run(ctx, t.clock.NewTicker(100*time.Millisecond)) // 1/10s
 	return nil
 }
 
 func (t *TimerService) StopTimer() error {
-	if !atomic.CompareAndSwapInt32(&t.isRunning, 1, 0) {
-		log.Println("Timer is not running")
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.cancel == nil {
+		slog.Debug("Timer is not running")
 		return nil
 	}
-	if t.ticker != nil {
-		// Only close stopChan if not already closed
-		select {
-		case <-t.stopChan:
-			// already closed
-		default:
-			close(t.stopChan)
-		}
-	}
+	t.cancel()
+	t.cancel = nil
 	return nil
 }
 
-func (t *TimerService) ResetTimer() error {
+// ResetTimer stops the game clock and puts it back to the profile's value,
+// returning the snapshots around the reset
+func (t *TimerService) ResetTimer() (before, after Snapshot, err error) {
 	t.StopTimer()
-	t.scoreboardService.ResetTimerToTenMinutes()
-	log.Println("Timer reset to 10:00")
-	return nil
+	before, after = t.scoreboardService.ResetTimerToDefault()
+	slog.Debug("Timer reset", "gameClock", format.LogClock(after.State.TimerTenths))
+	return before, after, nil
 }
 
 func (t *TimerService) IsRunning() bool {
-	return atomic.LoadInt32(&t.isRunning) == 1
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.cancel != nil
+}
+
+// CheckAlive reports an error when the timer should be running but its
+// goroutine has not ticked within maxAge.
+func (t *TimerService) CheckAlive(maxAge time.Duration) error {
+	if !t.IsRunning() {
+		return nil
+	}
+	since := t.clock.Since(time.Unix(0, atomic.LoadInt64(&t.lastTick)))
+	if since > maxAge {
+		return fmt.Errorf("game clock is running but last ticked %s ago", since.Round(time.Millisecond))
+	}
+	return nil
+}
+
+func (t *TimerService) run(ctx context.Context, ticker clock.Ticker) {
+	defer ticker.Stop()
+	tracker := metrics.NewTickTracker("game", 100*time.Millisecond, t.clock.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C():
+			tracker.Tick(now)
+			if !t.tick(ctx, now) {
+				return
+			}
+		}
+	}
+}
+
+// tick takes a tenth off the game clock unless the run was stopped in the
+// meantime, and reports whether the run goes on
+func (t *TimerService) tick(ctx context.Context, now time.Time) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if ctx.Err() != nil {
+		return false
+	}
+	atomic.StoreInt64(&t.lastTick, now.UnixNano())
+	if t.scoreboardService.DecrementTimerTenths() == 0 {
+		slog.Info("Game clock expired")
+		t.cancel()
+		t.cancel = nil
+		return false
+	}
+	return true
 }
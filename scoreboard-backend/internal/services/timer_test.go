@@ -0,0 +1,136 @@
+package services
+
+import (
+	"scoreboard-backend/internal/models"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTimerTicksAndExpires(t *testing.T) {
+	c := newTestControl(t)
+	c.SetTimer(3, testActor)
+	if err := c.timer.StartTimer(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 3; i++ {
+		if n := c.clock.Advance(100 * time.Millisecond); n != 1 {
+			t.Fatalf("tick %d: received %d times", i, n)
+		}
+		waitFor(t, "the tick", func() bool { return c.scoreboard.GetTimerTenths() == 3-i })
+	}
+	waitFor(t, "the game clock to stop at zero", func() bool { return !c.timer.IsRunning() && c.clock.Tickers() == 0 })
+	if n := c.clock.Advance(time.Second); n != 0 || c.scoreboard.GetTimerTenths() != 0 {
+		t.Errorf("%d ticks after expiry, game clock %d", n, c.scoreboard.GetTimerTenths())
+	}
+
+	ticks := 0
+	for _, e := range c.scoreboard.Events() {
+		if e.Type == models.EventTimerTicked {
+			ticks++
+		}
+	}
+	if ticks != 3 {
+		t.Errorf("%d tick events, want 3", ticks)
+	}
+}
+
+func TestTimerStopAndRestart(t *testing.T) {
+	c := newTestControl(t)
+	c.timer.StartTimer()
+	c.timer.StartTimer() // Already running: no second run
+	if n := c.clock.Advance(500 * time.Millisecond); n != 5 {
+		t.Errorf("%d ticks received, want 5 from one run", n)
+	}
+	waitFor(t, "five ticks", func() bool { return c.scoreboard.GetTimerTenths() == 5995 })
+
+	c.timer.StopTimer()
+	c.timer.StopTimer()
+	waitFor(t, "the ticker to stop", func() bool { return c.clock.Tickers() == 0 })
+	c.clock.Advance(time.Second)
+	if got := c.scoreboard.GetTimerTenths(); got != 5995 {
+		t.Errorf("game clock %d after Stop, want 5995", got)
+	}
+
+	c.timer.StartTimer()
+	c.clock.Advance(200 * time.Millisecond)
+	waitFor(t, "the clock to carry on", func() bool { return c.scoreboard.GetTimerTenths() == 5993 })
+}
+
+// TestTimerStartStopRace starts and stops the game clock from several
+// goroutines while it ticks; no tick may be lost or land after the last Stop
+func TestTimerStartStopRace(t *testing.T) {
+	c := newTestControl(t)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.timer.StartTimer()
+				c.timer.StopTimer()
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			c.clock.Advance(100 * time.Millisecond)
+		}
+	}()
+	wg.Wait()
+	c.timer.StopTimer()
+	waitFor(t, "every ticker to stop", func() bool { return c.clock.Tickers() == 0 })
+
+	ticks := 0
+	for _, e := range c.scoreboard.Events() {
+		if e.Type == models.EventTimerTicked {
+			ticks++
+		}
+	}
+	before := c.scoreboard.GetTimerTenths()
+	if before != 6000-ticks {
+		t.Errorf("game clock %d after %d ticks", before, ticks)
+	}
+	c.clock.Advance(time.Second)
+	if got := c.scoreboard.GetTimerTenths(); got != before {
+		t.Errorf("game clock moved from %d to %d after the last Stop", before, got)
+	}
+}
+
+func TestClocksStartAndStopTogether(t *testing.T) {
+	c := newTestControl(t)
+	if err := c.StartClocks(testActor); err != nil {
+		t.Fatal(err)
+	}
+	if !c.shot.IsRunning() || !c.timer.IsRunning() {
+		t.Fatal("StartClocks did not start both clocks")
+	}
+	c.clock.Advance(time.Second)
+	waitFor(t, "both clocks to tick", func() bool {
+		s := c.scoreboard.GetState()
+		return s.TimerTenths == 5990 && s.ShotClockTenths == 110
+	})
+
+	c.StopClocks(testActor)
+	if c.shot.IsRunning() || c.timer.IsRunning() {
+		t.Fatal("StopClocks did not stop both clocks")
+	}
+	waitFor(t, "both tickers to stop", func() bool { return c.clock.Tickers() == 0 })
+	c.clock.Advance(time.Second)
+	if s := c.scoreboard.GetState(); s.TimerTenths != 5990 || s.ShotClockTenths != 110 {
+		t.Errorf("clocks moved while stopped: %+v", s)
+	}
+
+	// The game clock also runs on its own, leaving the shot clock alone
+	if err := c.StartTimer(testActor); err != nil {
+		t.Fatal(err)
+	}
+	c.clock.Advance(500 * time.Millisecond)
+	waitFor(t, "the game clock to tick", func() bool { return c.scoreboard.GetTimerTenths() == 5985 })
+	if s := c.scoreboard.GetState(); s.ShotClockTenths != 110 || s.IsShotClockRunning {
+		t.Errorf("shot clock moved with the game clock alone: %+v", s)
+	}
+	c.StopTimer(testActor)
+}
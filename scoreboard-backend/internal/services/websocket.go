@@ -1,35 +1,110 @@
 package services
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"scoreboard-backend/internal/metrics"
 	"scoreboard-backend/internal/models"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
 
+// Subprotocols a client may offer in Sec-WebSocket-Protocol to pick an encoding
+const (
+	JSONSubprotocol    = "scoreboard.json.v1"
+	CompactSubprotocol = "scoreboard.compact.v1"
+)
+
+// SlowClientPolicy decides what the hub does when a client's send queue is full.
+type SlowClientPolicy int
+
+const (
+	// SlowClientDisconnect drops the client; its pumps exit and the display reconnects.
+	SlowClientDisconnect SlowClientPolicy = iota
+	// SlowClientDropMessage keeps the client and skips the message for it only.
+	SlowClientDropMessage
+)
+
+// WebSocketConfig holds the hub's keepalive, deadline and queueing settings.
+type WebSocketConfig struct {
+	SendQueueSize    int              // Buffered messages per client
+	WriteWait        time.Duration    // Deadline for a single write
+	PongWait         time.Duration    // Time allowed between pongs before the client is considered dead
+	PingPeriod       time.Duration    // Interval between pings, must be less than PongWait
+	MaxMessageSize   int64            // Largest inbound message accepted
+	SlowClientPolicy SlowClientPolicy // What to do when a client's queue is full
+	AllowedOrigins   []string         // Origins allowed to connect; empty or "*" allows all
+}
+
+// DefaultWebSocketConfig returns the settings used by NewWebSocketService.
+func DefaultWebSocketConfig() WebSocketConfig {
+	return WebSocketConfig{
+		SendQueueSize:    256,
+		WriteWait:        10 * time.Second,
+		PongWait:         60 * time.Second,
+		PingPeriod:       54 * time.Second,
+		MaxMessageSize:   4096,
+		SlowClientPolicy: SlowClientDisconnect,
+	}
+}
+
+// closeWait is how long a connection waits for the client to answer our
+// close frame before the socket is closed anyway.
+const closeWait = time.Second
+
+// outbound is a broadcast waiting for the hub, stamped for latency metrics
+type outbound struct {
+	message  models.WebSocketMessage
+	queuedAt time.Time
+}
+
+// Broadcaster sends a message to every connected display
+type Broadcaster interface {
+	BroadcastMessage(message models.WebSocketMessage)
+}
+
 type WebSocketService struct {
+	config     WebSocketConfig
 	clients    map[string]*models.Client
 	register   chan *models.Client
 	unregister chan *models.Client
-	broadcast  chan models.WebSocketMessage
+	broadcast  chan outbound
+	probe      chan chan struct{}
+	shutdown   chan chan struct{}
 	mutex      sync.RWMutex
 	upgrader   websocket.Upgrader
+	nextID     uint64         // atomic counter for client IDs
+	closing    int32          // atomic flag, set once Shutdown starts
+	closed     bool           // owned by run: no more clients are accepted
+	pumps      sync.WaitGroup // running write pumps
+	pumpsMutex sync.Mutex     // Held to set closing and to add a pump, so no pump starts after Shutdown waits
 }
 
 func NewWebSocketService() *WebSocketService {
+	return NewWebSocketServiceWithConfig(DefaultWebSocketConfig())
+}
+
+func NewWebSocketServiceWithConfig(config WebSocketConfig) *WebSocketService {
 	upgrader := websocket.Upgrader{
-		CheckOrigin: func(r *http.Request) bool {
-			return true // Allow all origins in development
-		},
+		CheckOrigin:  originChecker(config.AllowedOrigins),
+		Subprotocols: []string{CompactSubprotocol, JSONSubprotocol},
 	}
 
 	service := &WebSocketService{
+		config:     config,
 		clients:    make(map[string]*models.Client),
 		register:   make(chan *models.Client),
 		unregister: make(chan *models.Client),
-		broadcast:  make(chan models.WebSocketMessage),
+		broadcast:  make(chan outbound),
+		probe:      make(chan chan struct{}),
+		shutdown:   make(chan chan struct{}),
 		upgrader:   upgrader,
 	}
 
@@ -37,35 +112,113 @@ func NewWebSocketService() *WebSocketService {
 	return service
 }
 
+// originChecker allows requests without an Origin header (displays and
+// hardware clients) and browser requests from the allowed origins.
+func originChecker(allowed []string) func(r *http.Request) bool {
+	for _, origin := range allowed {
+		if origin == "*" {
+			allowed = nil
+			break
+		}
+	}
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if len(allowed) == 0 || origin == "" {
+			return true
+		}
+		for _, o := range allowed {
+			if strings.EqualFold(o, origin) {
+				return true
+			}
+		}
+		slog.Warn("Rejected WebSocket connection", "origin", origin, "clientIp", r.RemoteAddr)
+		return false
+	}
+}
+
+// run owns the clients map. It is the only goroutine that adds or removes
+// clients and the only one that closes a client's Send channel, so removal
+// is idempotent no matter how many paths ask for it.
 func (ws *WebSocketService) run() {
 	for {
 		select {
 		case client := <-ws.register:
+			if ws.closed {
+				// The write pump sends the close frame straight away
+				close(client.Send)
+				continue
+			}
 			ws.mutex.Lock()
 			ws.clients[client.ID] = client
+			total := len(ws.clients)
 			ws.mutex.Unlock()
-			log.Printf("Client %s connected. Total clients: %d", client.ID, len(ws.clients))
+			slog.Info("Client connected", "client", client.ID, "clients", total)
 
 		case client := <-ws.unregister:
-			ws.mutex.Lock()
-			if _, ok := ws.clients[client.ID]; ok {
-				delete(ws.clients, client.ID)
-				close(client.Send)
+			if ws.removeClient(client) {
+				slog.Info("Client disconnected", "client", client.ID, "clients", ws.GetClientCount())
 			}
-			ws.mutex.Unlock()
-			log.Printf("Client %s disconnected. Total clients: %d", client.ID, len(ws.clients))
 
-		case message := <-ws.broadcast:
+		case reply := <-ws.probe:
+			close(reply)
+
+		case reply := <-ws.shutdown:
+			ws.closed = true
+			ws.mutex.RLock()
+			clients := make([]*models.Client, 0, len(ws.clients))
+			for _, client := range ws.clients {
+				clients = append(clients, client)
+			}
+			ws.mutex.RUnlock()
+			for _, client := range clients {
+				ws.removeClient(client)
+			}
+			slog.Info("Closing WebSocket clients for shutdown", "clients", len(clients))
+			close(reply)
+
+		case out := <-ws.broadcast:
+			message := out.message
+			var slow []*models.Client
 			ws.mutex.RLock()
-			for clientID, client := range ws.clients {
+			for _, client := range ws.clients {
 				select {
 				case client.Send <- message:
 				default:
-					delete(ws.clients, clientID)
-					close(client.Send)
+					slow = append(slow, client)
 				}
 			}
 			ws.mutex.RUnlock()
+			for _, client := range slow {
+				ws.handleSlowClient(client, message)
+			}
+			metrics.BroadcastsTotal.Inc(message.Type)
+			metrics.BroadcastDuration.Observe(time.Since(out.queuedAt).Seconds())
+		}
+	}
+}
+
+// removeClient deletes the client and closes its Send channel. It reports
+// whether the client was still registered.
+func (ws *WebSocketService) removeClient(client *models.Client) bool {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+	if current, ok := ws.clients[client.ID]; !ok || current != client {
+		return false
+	}
+	delete(ws.clients, client.ID)
+	close(client.Send)
+	return true
+}
+
+func (ws *WebSocketService) handleSlowClient(client *models.Client, message models.WebSocketMessage) {
+	switch ws.config.SlowClientPolicy {
+	case SlowClientDropMessage:
+		metrics.SlowClientsTotal.Inc("drop_message")
+		slog.Warn("Client is slow, dropped message", "client", client.ID, "type", message.Type)
+	default:
+		if ws.removeClient(client) {
+			metrics.SlowClientsTotal.Inc("disconnect")
+			slog.Warn("Client is slow, disconnecting", "client", client.ID, "clients", ws.GetClientCount())
 		}
 	}
 }
@@ -74,20 +227,204 @@ func (ws *WebSocketService) UpgradeConnection(w http.ResponseWriter, r *http.Req
 	return ws.upgrader.Upgrade(w, r, nil)
 }
 
+// ClientEncoding picks the frame encoding for an upgraded connection. A
+// negotiated subprotocol wins over the ?encoding= query parameter; JSON is
+// the default.
+func ClientEncoding(conn *websocket.Conn, r *http.Request) string {
+	switch conn.Subprotocol() {
+	case CompactSubprotocol:
+		return models.EncodingCompact
+	case JSONSubprotocol:
+		return models.EncodingJSON
+	}
+	if r.URL.Query().Get("encoding") == models.EncodingCompact {
+		return models.EncodingCompact
+	}
+	return models.EncodingJSON
+}
+
+// NewClient creates a client with a unique ID and a send queue sized from the config.
+func (ws *WebSocketService) NewClient(remoteAddr string, encoding string) *models.Client {
+	id := atomic.AddUint64(&ws.nextID, 1)
+	return &models.Client{
+		ID:       fmt.Sprintf("%s#%d", remoteAddr, id),
+		Send:     make(chan models.WebSocketMessage, ws.config.SendQueueSize),
+		Encoding: encoding,
+	}
+}
+
 func (ws *WebSocketService) RegisterClient(client *models.Client) {
 	ws.register <- client
 }
 
+// UnregisterClient removes the client from the hub. Calling it more than once is safe.
 func (ws *WebSocketService) UnregisterClient(client *models.Client) {
 	ws.unregister <- client
 }
 
 func (ws *WebSocketService) BroadcastMessage(message models.WebSocketMessage) {
-	ws.broadcast <- message
+	ws.broadcast <- outbound{message: message, queuedAt: time.Now()}
+}
+
+// Ping sends a heartbeat through the hub loop and waits for it to be
+// handled, proving the loop is still processing its channels.
+func (ws *WebSocketService) Ping(timeout time.Duration) error {
+	reply := make(chan struct{})
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case ws.probe <- reply:
+	case <-timer.C:
+		return errors.New("websocket hub did not accept heartbeat")
+	}
+	select {
+	case <-reply:
+		return nil
+	case <-timer.C:
+		return errors.New("websocket hub did not answer heartbeat")
+	}
+}
+
+// Shutdown stops accepting clients and closes every connection with a
+// going-away close frame, after anything already queued for it has been
+// written. Broadcast the goodbye message before calling it. Shutdown waits
+// for the connections to finish or for ctx to expire.
+func (ws *WebSocketService) Shutdown(ctx context.Context) error {
+	ws.pumpsMutex.Lock()
+	atomic.StoreInt32(&ws.closing, 1)
+	ws.pumpsMutex.Unlock()
+	reply := make(chan struct{})
+	select {
+	case ws.shutdown <- reply:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	<-reply
+
+	done := make(chan struct{})
+	go func() {
+		ws.pumps.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 func (ws *WebSocketService) GetClientCount() int {
 	ws.mutex.RLock()
 	defer ws.mutex.RUnlock()
 	return len(ws.clients)
-}
\ No newline at end of file
+}
+
+// ServeClient registers the client and pumps messages until the connection
+// dies. Anything already queued on client.Send is written before broadcasts.
+// onMessage is called from the read loop for every inbound message.
+func (ws *WebSocketService) ServeClient(conn *websocket.Conn, client *models.Client, onMessage func(models.InboundMessage)) {
+	client.Conn = conn
+	ws.pumpsMutex.Lock()
+	if atomic.LoadInt32(&ws.closing) == 1 {
+		ws.pumpsMutex.Unlock()
+		conn.WriteControl(websocket.CloseMessage, ws.closeFrame(), time.Now().Add(ws.config.WriteWait))
+		conn.Close()
+		return
+	}
+	ws.pumps.Add(1)
+	ws.pumpsMutex.Unlock()
+	// A client that gets here after Shutdown reached the hub is closed by
+	// run as soon as it registers, so its pump still ends
+	readDone := make(chan struct{})
+	ws.RegisterClient(client)
+	go ws.writePump(conn, client, readDone)
+	ws.readPump(conn, client, onMessage)
+	close(readDone)
+}
+
+// readPump reads inbound messages and keeps the read deadline alive on pongs.
+func (ws *WebSocketService) readPump(conn *websocket.Conn, client *models.Client, onMessage func(models.InboundMessage)) {
+	defer ws.UnregisterClient(client)
+
+	conn.SetReadLimit(ws.config.MaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(ws.config.PongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(ws.config.PongWait))
+	})
+
+	for {
+		var message models.InboundMessage
+		err := conn.ReadJSON(&message)
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				slog.Warn("WebSocket read error", "client", client.ID, "error", err)
+			}
+			return
+		}
+		if onMessage != nil {
+			onMessage(message)
+		}
+	}
+}
+
+// writePump writes queued messages and pings the client. Every write has a
+// deadline so a dead display cannot block the pump forever. It owns closing
+// the connection: when the read side ends first the socket is closed at once,
+// when the hub drops the client a close frame is sent and the client gets
+// closeWait to answer it.
+func (ws *WebSocketService) writePump(conn *websocket.Conn, client *models.Client, readDone <-chan struct{}) {
+	ticker := time.NewTicker(ws.config.PingPeriod)
+	defer func() {
+		ticker.Stop()
+		conn.Close()
+		ws.pumps.Done()
+	}()
+
+	for {
+		select {
+		case <-readDone:
+			return
+
+		case message, ok := <-client.Send:
+			conn.SetWriteDeadline(time.Now().Add(ws.config.WriteWait))
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage, ws.closeFrame())
+				select {
+				case <-readDone:
+				case <-time.After(closeWait):
+				}
+				return
+			}
+			if err := writeMessage(conn, client, message); err != nil {
+				slog.Warn("WebSocket write error", "client", client.ID, "error", err)
+				return
+			}
+
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(ws.config.WriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// closeFrame is the payload of the close frame sent when the hub drops a client
+func (ws *WebSocketService) closeFrame() []byte {
+	if atomic.LoadInt32(&ws.closing) == 1 {
+		return websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+	}
+	return websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+}
+
+// writeMessage sends clock updates as binary frames to compact clients and
+// everything else as JSON.
+func writeMessage(conn *websocket.Conn, client *models.Client, message models.WebSocketMessage) error {
+	if client.Encoding == models.EncodingCompact {
+		if frame, ok := encodeCompact(message); ok {
+			return conn.WriteMessage(websocket.BinaryMessage, frame)
+		}
+	}
+	return conn.WriteJSON(message)
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"scoreboard-backend/internal/models"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// hubServer serves ws on a local port the way the handlers do
+func hubServer(t *testing.T, ws *WebSocketService) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := ws.UpgradeConnection(w, r)
+		if err != nil {
+			return
+		}
+		ws.ServeClient(conn, ws.NewClient(r.RemoteAddr, models.EncodingJSON), nil)
+	}))
+	t.Cleanup(srv.Close)
+	return "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+// waitFor polls cond until it holds or a few seconds pass
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func shutdownHub(t *testing.T, ws *WebSocketService) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := ws.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+}
+
+func TestHubChurnWhileBroadcasting(t *testing.T) {
+	ws := NewWebSocketService()
+	url := hubServer(t, ws)
+
+	stop := make(chan struct{})
+	var broadcasts sync.WaitGroup
+	broadcasts.Add(1)
+	go func() {
+		defer broadcasts.Done()
+		for i := uint(0); ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			ws.BroadcastMessage(models.NewMessage(models.ScoreUpdateData{Team: "A", ScoreA: i}))
+			// Slow enough that no reading client fills its queue
+			time.Sleep(2 * time.Millisecond)
+		}
+	}()
+
+	const clients = 300
+	var wg sync.WaitGroup
+	errs := make(chan error, clients)
+	for i := 0; i < clients; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer conn.Close()
+			// Take a few broadcasts, then leave
+			for j := 0; j < 3; j++ {
+				var message map[string]interface{}
+				if err := conn.ReadJSON(&message); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(stop)
+	broadcasts.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("client: %v", err)
+	}
+
+	waitFor(t, "every client to be unregistered", func() bool { return ws.GetClientCount() == 0 })
+	shutdownHub(t, ws)
+}
+
+func TestSlowClientDisconnected(t *testing.T) {
+	config := DefaultWebSocketConfig()
+	config.SendQueueSize = 2
+	ws := NewWebSocketServiceWithConfig(config)
+	slow := ws.NewClient("slow", models.EncodingJSON)
+	ws.RegisterClient(slow)
+	waitFor(t, "the client to register", func() bool { return ws.GetClientCount() == 1 })
+
+	for i := 0; i < 3; i++ {
+		ws.BroadcastMessage(models.NewMessage(models.ScoreUpdateData{Team: "A", ScoreA: uint(i)}))
+	}
+	waitFor(t, "the slow client to be dropped", func() bool { return ws.GetClientCount() == 0 })
+
+	// The queued messages are still delivered, then the queue is closed
+	received := 0
+	for range slow.Send {
+		received++
+	}
+	if received != 2 {
+		t.Errorf("received %d messages before the close, want 2", received)
+	}
+	shutdownHub(t, ws)
+}
+
+func TestSlowClientMessageDropped(t *testing.T) {
+	config := DefaultWebSocketConfig()
+	config.SendQueueSize = 2
+	config.SlowClientPolicy = SlowClientDropMessage
+	ws := NewWebSocketServiceWithConfig(config)
+	slow := ws.NewClient("slow", models.EncodingJSON)
+	ws.RegisterClient(slow)
+
+	for i := 0; i < 5; i++ {
+		ws.BroadcastMessage(models.NewMessage(models.ScoreUpdateData{Team: "A", ScoreA: uint(i)}))
+	}
+	if err := ws.Ping(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if n := ws.GetClientCount(); n != 1 {
+		t.Fatalf("%d clients, want the slow one kept", n)
+	}
+	for _, want := range []uint{0, 1} {
+		message := <-slow.Send
+		if got := message.Data.(models.ScoreUpdateData).ScoreA; got != want {
+			t.Errorf("got score %d, want %d", got, want)
+		}
+	}
+	select {
+	case message := <-slow.Send:
+		t.Errorf("message %v was queued past the limit", message.Data)
+	default:
+	}
+	shutdownHub(t, ws)
+}
+
+func TestBroadcastDuringShutdown(t *testing.T) {
+	ws := NewWebSocketService()
+	url := hubServer(t, ws)
+	for i := 0; i < 20; i++ {
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+	}
+	waitFor(t, "the clients to register", func() bool { return ws.GetClientCount() == 20 })
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			ws.BroadcastMessage(models.NewMessage(models.ScoreUpdateData{Team: "B", ScoreB: uint(i)}))
+		}
+	}()
+	shutdownHub(t, ws)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("BroadcastMessage blocked during shutdown")
+	}
+
+	// After shutdown broadcasts still return and new clients are refused
+	finished := make(chan struct{})
+	go func() {
+		ws.BroadcastMessage(models.NewMessage(models.ServerShutdownData{Reason: "test"}))
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("BroadcastMessage blocked after shutdown")
+	}
+	late := ws.NewClient("late", models.EncodingJSON)
+	ws.RegisterClient(late)
+	if _, ok := <-late.Send; ok {
+		t.Error("a client registered after shutdown got a message")
+	}
+	if n := ws.GetClientCount(); n != 0 {
+		t.Errorf("%d clients after shutdown", n)
+	}
+}
+
+func TestConnectDuringShutdown(t *testing.T) {
+	ws := NewWebSocketService()
+	url := hubServer(t, ws)
+
+	// Every connection, whether it beats Shutdown or not, must be closed
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					if !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+						t.Errorf("connection ended with %v, want a going-away close", err)
+					}
+					return
+				}
+			}
+		}()
+	}
+	shutdownHub(t, ws)
+	wg.Wait()
+}
@@ -1,78 +1,157 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"log"
-	"scoreboard-backend/internal/handlers"
-	"scoreboard-backend/internal/services"
-	"strings"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"scoreboard-backend/internal/clock"
+	"scoreboard-backend/internal/config"
+	"scoreboard-backend/internal/input"
+	"scoreboard-backend/internal/led"
+	"scoreboard-backend/internal/logging"
+	"scoreboard-backend/internal/models"
+	"scoreboard-backend/internal/remote"
+	"scoreboard-backend/internal/server"
+	"syscall"
+	"time"
 
 	_ "scoreboard-backend/docs"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	swaggerFiles "github.com/swaggo/files"
-	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
 func main() {
-	// Initialize services
-	scoreboardService := services.NewScoreboardService()
-	websocketService := services.NewWebSocketService()
-	timerService := services.NewTimerService(scoreboardService, websocketService)
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatal("Invalid configuration: ", err)
+	}
+	if err := logging.Setup(os.Stderr, cfg.LogLevel, cfg.LogFormat); err != nil {
+		log.Fatal("Invalid configuration: ", err)
+	}
+	if os.Getenv(gin.EnvGinMode) == "" && cfg.LogLevel != "debug" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+	effective, _ := json.Marshal(cfg.Redacted())
+	slog.Info("Effective configuration", "config", json.RawMessage(effective))
 
-	// Initialize handlers
-	scoreboardHandler := handlers.NewScoreboardHandler(scoreboardService, websocketService, timerService)
+	srv, err := server.New(cfg, clock.Real{})
+	if err != nil {
+		fatal("Failed to start services", err)
+	}
+	websocketService, controlService := srv.WebSocket, srv.Control
 
-	// Setup Gin router
-	router := gin.Default()
+	// Hardware remote control, enabled when a listen address is set
+	var remoteServer *remote.Server
+	if cfg.Remote.TCPAddr != "" || cfg.Remote.UDPAddr != "" {
+		remoteServer = remote.NewServer(controlService, remote.Config{
+			TCPAddr: cfg.Remote.TCPAddr,
+			UDPAddr: cfg.Remote.UDPAddr,
+			Key:     cfg.Remote.Key,
+		})
+		if err := remoteServer.Start(); err != nil {
+			fatal("Failed to start remote control", err)
+		}
+	}
 
-	// CORS middleware
-	config := cors.DefaultConfig()
-	config.AllowAllOrigins = true
-	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
-	router.Use(cors.New(config))
+	// Keypad input from evdev devices, enabled when device paths are set
+	var inputDaemon *input.Daemon
+	if len(cfg.Input.Devices) > 0 {
+		keymap, err := input.LoadKeyMap(cfg.Input.KeyMap)
+		if err != nil {
+			fatal("Failed to load key map", err)
+		}
+		inputDaemon = input.NewDaemon(controlService, input.Config{
+			Devices: cfg.Input.Devices,
+			KeyMap:  keymap,
+			Grab:    cfg.Input.Grab,
+		})
+		if err := inputDaemon.Start(); err != nil {
+			fatal("Failed to start input daemon", err)
+		}
+	}
 
-	// API routes
-	api := router.Group("/api")
-	{
-		api.GET("/state", scoreboardHandler.GetState)
-		api.POST("/timer/reset", scoreboardHandler.ResetTimer)
-		api.POST("/timer/set", scoreboardHandler.SetTimer)
-		api.POST("/scoreA/increment", scoreboardHandler.IncrementScoreA)
-		api.POST("/scoreA/decrement", scoreboardHandler.DecrementScoreA)
-		api.POST("/scoreB/increment", scoreboardHandler.IncrementScoreB)
-		api.POST("/scoreB/decrement", scoreboardHandler.DecrementScoreB)
-		api.POST("/shotclock/set", scoreboardHandler.SetShotClock)
-		api.POST("/shotclock/reset", scoreboardHandler.ResetShotClock)
-		api.POST("/shotclock/start", scoreboardHandler.StartShotClock)
-		api.POST("/shotclock/stop", scoreboardHandler.StopShotClock)
-		api.POST("/game/reset", scoreboardHandler.ResetGame)
-		api.POST("/foulA/increment", scoreboardHandler.IncrementFoulA)
-		api.POST("/foulA/decrement", scoreboardHandler.DecrementFoulA)
-		api.POST("/foulB/increment", scoreboardHandler.IncrementFoulB)
-		api.POST("/foulB/decrement", scoreboardHandler.DecrementFoulB)
-		api.GET("/log", scoreboardHandler.GetLog)
-		api.POST("/state/sync", scoreboardHandler.TriggerStateSync)
+	// LED board on a serial port, enabled when a port is set
+	var ledOutput *led.Output
+	if cfg.LED.Port != "" {
+		driver, err := led.Open(led.Config{
+			Port:     cfg.LED.Port,
+			Protocol: cfg.LED.Protocol,
+			Address:  cfg.LED.Address,
+			Serial:   led.SerialConfig{Baud: cfg.LED.Baud, Parity: cfg.LED.Parity, StopBits: cfg.LED.StopBits, RS485: cfg.LED.RS485},
+		}, clock.Real{})
+		if err != nil {
+			fatal("Failed to open LED board", err)
+		}
+		ledOutput = led.NewOutput(websocketService, controlService, []led.Driver{driver},
+			time.Duration(cfg.LED.RefreshMs)*time.Millisecond)
+		if err := ledOutput.Start(); err != nil {
+			fatal("Failed to start LED output", err)
+		}
 	}
 
-	router.GET("/ws/state", func(c *gin.Context) {
-		connectionHeader := c.Request.Header.Get("Connection")
-		upgradeHeader := c.Request.Header.Get("Upgrade")
-		if strings.Contains(strings.ToLower(connectionHeader), "upgrade") && strings.ToLower(upgradeHeader) == "websocket" {
-			scoreboardHandler.HandleWebSocket(c)
-			return
+	httpServer := &http.Server{Addr: cfg.ListenAddr, Handler: srv.Router}
+	serverErr := make(chan error, 1)
+	go func() {
+		if cfg.TLSEnabled() {
+			slog.Info("Server starting", "addr", cfg.ListenAddr, "tls", true)
+			serverErr <- httpServer.ListenAndServeTLS(cfg.TLSCert, cfg.TLSKey)
+		} else {
+			slog.Info("Server starting", "addr", cfg.ListenAddr, "tls", false)
+			serverErr <- httpServer.ListenAndServe()
 		}
-		c.JSON(400, gin.H{"error": "WebSocket upgrade required"})
-	})
+	}()
 
-	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok"})
-	})
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	select {
+	case err := <-serverErr:
+		fatal("Failed to start server", err)
+	case <-ctx.Done():
+		stop() // a second signal kills the process
+	}
+	slog.Info("Shutting down")
 
-	log.Println("Server starting on :8080")
-	if err := router.Run(":8080"); err != nil {
-		log.Fatal("Failed to start server:", err)
+	// Freeze the game first, so the displays are told about the shutdown
+	// showing the state that is saved, then close the sockets and drain the
+	// requests still running
+	if remoteServer != nil {
+		remoteServer.Close()
+	}
+	if inputDaemon != nil {
+		inputDaemon.Close()
+	}
+	srv.StopClocks()
+	drainCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	websocketService.BroadcastMessage(models.NewMessage(models.ServerShutdownData{Reason: "server shutting down"}))
+	if err := websocketService.Shutdown(drainCtx); err != nil {
+		slog.Warn("WebSocket clients did not close in time", "error", err)
 	}
+	if err := httpServer.Shutdown(drainCtx); err != nil {
+		slog.Warn("HTTP requests did not finish in time", "error", err)
+	}
+	// Nothing can change the game now: save and close the store
+	srv.SaveState()
+	if ledOutput != nil {
+		ledOutput.Close() // Leaves the board on the final state
+	}
+	slog.Info("Server stopped")
+}
+
+// shutdownTimeout bounds connection draining; docker stop waits 10s before SIGKILL
+const shutdownTimeout = 8 * time.Second
+
+// fatal logs err and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
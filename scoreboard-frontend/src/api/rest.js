@@ -55,7 +55,12 @@ export const resetShotClock = () => apiCall('/shotclock/reset', 'POST');
 export const setShotClock = (time) => apiCall('/shotclock/set', 'POST', { time });
 
 // Game controls
-export const resetGame = () => apiCall('/game/reset', 'POST');
+// Resetting is two calls: the first returns a token and a summary of what
+// will be lost, the second presents the token.
+export const requestGameReset = () => apiCall('/game/reset', 'POST');
+export const confirmGameReset = (token, reason) => apiCall('/game/reset', 'POST', { token, reason });
+export const listArchives = () => apiCall('/game/archives');
+export const restoreArchive = (id) => apiCall(`/game/archives/${encodeURIComponent(id)}/restore`, 'POST');
 
 // Log
 export const getLog = () => apiCall('/log');
\ No newline at end of file
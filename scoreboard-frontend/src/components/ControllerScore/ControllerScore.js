@@ -6,7 +6,8 @@ import {
   incrementScoreB, decrementScoreB,
   incrementFoulA, decrementFoulA,
   incrementFoulB, decrementFoulB,
-  resetGame,
+  requestGameReset,
+  confirmGameReset,
   getState
 } from '../../api/rest';
 import styles from './ControllerScore.module.css';
@@ -207,10 +208,17 @@ function ControllerScore() {
       <div className={styles.gameControlSection}>
         <button 
           className={`${styles.controlButton} ${styles.resetButton}`}
-          onClick={() => {
-            if (window.confirm('Are you sure you want to reset all scores and fouls?')) {
-              handleApiCall(resetGame, 'resetGame');
+          onClick={async () => {
+            let confirmation;
+            try {
+              confirmation = await requestGameReset();
+            } catch (error) {
+              alert(`Failed: ${error.message}`);
+              return;
             }
+            if (!window.confirm(`${confirmation.summary.description}\n\nReset the game?`)) return;
+            const reason = window.prompt('Reason for the reset (optional):', '') || '';
+            handleApiCall(() => confirmGameReset(confirmation.token, reason), 'resetGame');
           }}
           disabled={isLoading.resetGame}
         >
@@ -124,6 +124,13 @@ function Scoreboard() {
         </div>
       </div>
 
+      {/* Instant replay review banner */}
+      {state.review && (
+        <div className={styles.reviewBanner}>
+          REVIEW{state.review.reason ? ` – ${state.review.reason}` : ''}
+        </div>
+      )}
+
       {/* Middle section with scores and shot clock */}
       <div className={styles.middleSection}>
         <div className={styles.scoreSection}>
@@ -14,6 +14,7 @@ const initialState = {
   formattedTimer: "10:00",
   formattedShotclock: "12.0",
   connected: false,
+  review: null, // { status, reason } while an instant replay review is in progress
   teamAName: "TEAM A",
   teamBName: "TEAM B"
 };
@@ -25,6 +26,7 @@ const ACTION_TYPES = {
   TIMER_UPDATE: 'TIMER_UPDATE',
   FOUL_UPDATE: 'FOUL_UPDATE',
   SHOTCLOCK_UPDATE: 'SHOTCLOCK_UPDATE',
+  REVIEW_UPDATE: 'REVIEW_UPDATE',
   CONNECTION_UPDATE: 'CONNECTION_UPDATE'
 };
 
@@ -44,7 +46,8 @@ function scoreboardReducer(state, action) {
   let nextState;
   switch (action.type) {
     case ACTION_TYPES.STATE_SYNC:
-      nextState = { ...state, ...action.payload };
+      // review is only present while one is in progress
+      nextState = { ...state, review: null, ...action.payload };
       break;
     case ACTION_TYPES.SCORE_UPDATE:
       nextState = { ...state, ...action.payload };
@@ -58,6 +61,9 @@ function scoreboardReducer(state, action) {
     case ACTION_TYPES.SHOTCLOCK_UPDATE:
       nextState = { ...state, ...action.payload };
       break;
+    case ACTION_TYPES.REVIEW_UPDATE:
+      nextState = { ...state, review: action.payload.status === 'review' ? action.payload : null };
+      break;
     case ACTION_TYPES.CONNECTION_UPDATE:
       nextState = { ...state, connected: action.payload };
       break;
@@ -128,6 +134,13 @@ function ScoreboardProvider({ children }) {
           case 'game_reset':
             dispatch({ type: ACTION_TYPES.STATE_SYNC, payload: message.data });
             break;
+          case 'review_update':
+            dispatch({ type: ACTION_TYPES.REVIEW_UPDATE, payload: message.data });
+            break;
+          case 'server_shutdown':
+            // The socket closes next; show the display as reconnecting
+            dispatch({ type: ACTION_TYPES.CONNECTION_UPDATE, payload: false });
+            break;
           default:
             break;
         }